@@ -145,3 +145,69 @@ func Untar(dst string, r io.Reader) error {
 		}
 	}
 }
+
+// UntarSafe is Untar for the untrusted tarballs, such as the downloaded ones: the entries out of 'dst',
+// the links and the other special files are rejected, and the existing files are overwritten.
+func UntarSafe(dst string, r io.Reader) error {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gzr.Close()
+
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target, err := safeTarget(dst, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if target == filepath.Clean(dst) {
+				return fmt.Errorf("invalid file entry %q", header.Name)
+			}
+			if err := untarFile(target, os.FileMode(header.Mode).Perm(), tr); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("entry %q of type %q is not supported", header.Name, header.Typeflag)
+		}
+	}
+}
+
+// safeTarget returns the location of the entry name, which must be inside of 'dst'.
+func safeTarget(dst, name string) (string, error) {
+	if filepath.IsAbs(name) || strings.HasPrefix(name, "/") {
+		return "", fmt.Errorf("entry %q has an absolute path", name)
+	}
+	target := filepath.Join(dst, filepath.FromSlash(name))
+	rel, err := filepath.Rel(dst, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("entry %q is outside of %s", name, dst)
+	}
+	return target, nil
+}
+
+func untarFile(target string, mode os.FileMode, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, r)
+	return err
+}
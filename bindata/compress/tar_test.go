@@ -10,7 +10,9 @@
 package compress
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
 	"os"
 	"path/filepath"
 	"strings"
@@ -89,3 +91,53 @@ func TestTar_Install(t *testing.T) {
 	})
 	require.Equal(t, srcFileSet, dstFileSet)
 }
+
+func TestUntarSafe(t *testing.T) {
+	type entry struct {
+		name, link, body string
+		typ              byte
+	}
+	tarball := func(entries ...entry) *bytes.Buffer {
+		buf := &bytes.Buffer{}
+		gzw := gzip.NewWriter(buf)
+		tw := tar.NewWriter(gzw)
+		for _, e := range entries {
+			require.Nil(t, tw.WriteHeader(&tar.Header{Name: e.name, Linkname: e.link, Typeflag: e.typ,
+				Mode: 0644, Size: int64(len(e.body))}))
+			_, err := tw.Write([]byte(e.body))
+			require.Nil(t, err)
+		}
+		require.Nil(t, tw.Close())
+		require.Nil(t, gzw.Close())
+		return buf
+	}
+
+	dst := filepath.Join(t.TempDir(), "pack")
+	require.Nil(t, os.MkdirAll(dst, os.ModePerm))
+	require.Nil(t, os.WriteFile(filepath.Join(dst, "a.txt"), []byte("stale content"), 0644))
+	require.Nil(t, UntarSafe(dst, tarball(
+		entry{name: "./", typ: tar.TypeDir},
+		entry{name: "a.txt", body: "new", typ: tar.TypeReg},
+		entry{name: "sub/b.txt", body: "b", typ: tar.TypeReg},
+	)))
+	b, err := os.ReadFile(filepath.Join(dst, "a.txt"))
+	require.Nil(t, err)
+	require.Equal(t, "new", string(b), "the existing files are truncated")
+	b, err = os.ReadFile(filepath.Join(dst, "sub", "b.txt"))
+	require.Nil(t, err)
+	require.Equal(t, "b", string(b))
+
+	for _, e := range []entry{
+		{name: "../evil", body: "evil", typ: tar.TypeReg},
+		{name: "sub/../../evil", body: "evil", typ: tar.TypeReg},
+		{name: "/tmp/evil", body: "evil", typ: tar.TypeReg},
+		{name: "evil", link: "../evil", typ: tar.TypeSymlink},
+		{name: "evil", link: "/etc/passwd", typ: tar.TypeLink},
+	} {
+		require.NotNil(t, UntarSafe(dst, tarball(e)), e.name)
+	}
+	_, err = os.Stat(filepath.Join(filepath.Dir(dst), "evil"))
+	require.True(t, os.IsNotExist(err))
+	_, err = os.Lstat(filepath.Join(dst, "evil"))
+	require.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,222 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+// Package call provides call command.
+package call
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	trpcpb "trpc.group/trpc/trpc-protocol/pb/go/trpc"
+
+	"trpc.group/trpc-go/trpc-cmdline/cmd/internal"
+	"trpc.group/trpc-go/trpc-cmdline/util/log"
+	"trpc.group/trpc-go/trpc-cmdline/util/protocol"
+)
+
+const (
+	// defaultCaller is the caller sent in the requests if --caller is not specified.
+	defaultCaller = "trpc.cmdline.call"
+	// defaultTimeout is the timeout of the requests if --timeout is not specified.
+	defaultTimeout = 5 * time.Second
+)
+
+// options are the options of call command.
+type options struct {
+	target  string
+	data    string
+	timeout time.Duration
+	caller  string
+	callee  string
+	meta    []string
+}
+
+// CMD returns call command.
+func CMD() *cobra.Command {
+	var (
+		protoFlags internal.ProtoFlags
+		opts       options
+	)
+	callCmd := &cobra.Command{
+		Use:   "call <method>",
+		Short: "Send an RPC request to the target service without generated code",
+		Long: `Send an RPC request to the target service without generated code.
+
+The request is encoded from JSON by the pb file, and sent by the tRPC protocol.
+The response is printed as JSON, together with the ret codes and the trans_info metadata.
+
+For example:
+  trpc call -p helloworld.proto --target ip://127.0.0.1:8000 trpc.app.server.Greeter/SayHello -d '{"msg":"hi"}'
+
+The method can be specified as /trpc.app.server.Greeter/SayHello, trpc.app.server.Greeter/SayHello,
+or Greeter/SayHello if there is no ambiguity, the aliases of RPCs are supported as well.
+The request body can be read from a file by -d @request.json, or from stdin by -d @-.
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.SetPrefix("[call]")
+			fd, err := protoFlags.Load()
+			if err != nil {
+				return err
+			}
+			m, err := protocol.FindMethod(fd, args[0])
+			if err != nil {
+				return err
+			}
+			if m.RPC.ClientStreaming || m.RPC.ServerStreaming {
+				return fmt.Errorf("%s is a streaming method, which is not supported by call", m.Func)
+			}
+			data, err := readData(opts.data, cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+			return call(cmd.OutOrStdout(), m, data, &opts)
+		},
+	}
+	protoFlags.AddFlags(callCmd.Flags())
+	callCmd.Flags().StringVar(&opts.target, "target", "", "Address of the target service, e.g. ip://127.0.0.1:8000")
+	callCmd.Flags().StringVarP(&opts.data, "data", "d", "",
+		"Request body in JSON, @file reads it from the file and @- reads it from stdin, defaults to an empty message")
+	callCmd.Flags().DurationVar(&opts.timeout, "timeout", defaultTimeout, "Timeout of the request")
+	callCmd.Flags().StringVar(&opts.caller, "caller", defaultCaller, "Caller name sent in the request")
+	callCmd.Flags().StringVar(&opts.callee, "callee", "",
+		"Callee name sent in the request, defaults to the fully qualified service name")
+	callCmd.Flags().StringArrayVar(&opts.meta, "meta", nil,
+		"Metadata sent in the trans_info of the request, in the form of key=value, can be specified multiple times")
+	_ = callCmd.MarkFlagRequired("target")
+	return callCmd
+}
+
+// readData reads the request body specified by --data.
+func readData(data string, stdin io.Reader) ([]byte, error) {
+	switch {
+	case data == "@-":
+		b, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("read request body from stdin err: %w", err)
+		}
+		return b, nil
+	case strings.HasPrefix(data, "@"):
+		b, err := os.ReadFile(data[1:])
+		if err != nil {
+			return nil, fmt.Errorf("read request body err: %w", err)
+		}
+		return b, nil
+	default:
+		return []byte(data), nil
+	}
+}
+
+// result is the output of call command.
+type result struct {
+	Ret       int32             `json:"ret"`
+	FuncRet   int32             `json:"func_ret"`
+	ErrorMsg  string            `json:"error_msg,omitempty"`
+	TransInfo map[string]string `json:"trans_info,omitempty"`
+	Body      json.RawMessage   `json:"body,omitempty"`
+}
+
+func call(w io.Writer, m *protocol.Method, data []byte, opts *options) error {
+	req, err := m.NewInput(data)
+	if err != nil {
+		return err
+	}
+	body, err := req.Marshal()
+	if err != nil {
+		return fmt.Errorf("marshal request err: %w", err)
+	}
+	head, err := newRequestHead(m, opts)
+	if err != nil {
+		return err
+	}
+
+	network, address, err := protocol.ParseTarget(opts.target)
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialTimeout(network, address, opts.timeout)
+	if err != nil {
+		return fmt.Errorf("dial %s err: %w", opts.target, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(opts.timeout)); err != nil {
+		return fmt.Errorf("set deadline err: %w", err)
+	}
+	log.Debug("call %s of %s, request: %s", m.Func, opts.target, data)
+	if err := protocol.WriteRequest(conn, head, body); err != nil {
+		return fmt.Errorf("send request err: %w", err)
+	}
+	rspHead, rspBody, err := protocol.ReadResponse(conn)
+	if err != nil {
+		return fmt.Errorf("receive response err: %w", err)
+	}
+
+	res := &result{
+		Ret:      rspHead.GetRet(),
+		FuncRet:  rspHead.GetFuncRet(),
+		ErrorMsg: string(rspHead.GetErrorMsg()),
+	}
+	if len(rspHead.GetTransInfo()) != 0 {
+		res.TransInfo = make(map[string]string, len(rspHead.GetTransInfo()))
+		for k, v := range rspHead.GetTransInfo() {
+			res.TransInfo[k] = string(v)
+		}
+	}
+	if res.Ret == 0 {
+		rsp, err := m.NewOutput(nil)
+		if err != nil {
+			return err
+		}
+		if err := rsp.Unmarshal(rspBody); err != nil {
+			return fmt.Errorf("unmarshal response into %s err: %w", m.Output.GetFullyQualifiedName(), err)
+		}
+		if res.Body, err = protocol.MarshalJSON(rsp); err != nil {
+			return fmt.Errorf("marshal response into json err: %w", err)
+		}
+	}
+	b, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal result err: %w", err)
+	}
+	fmt.Fprintln(w, string(b))
+	if res.Ret != 0 || res.FuncRet != 0 {
+		return fmt.Errorf("call %s failed, ret: %d, func_ret: %d, error_msg: %s",
+			m.Func, res.Ret, res.FuncRet, res.ErrorMsg)
+	}
+	return nil
+}
+
+func newRequestHead(m *protocol.Method, opts *options) (*trpcpb.RequestProtocol, error) {
+	callee := opts.callee
+	if callee == "" {
+		callee = m.Service
+	}
+	transInfo, err := protocol.ParseTransInfo(opts.meta)
+	if err != nil {
+		return nil, err
+	}
+	return &trpcpb.RequestProtocol{
+		Version:     uint32(trpcpb.TrpcProtoVersion_TRPC_PROTO_V1),
+		CallType:    uint32(trpcpb.TrpcCallType_TRPC_UNARY_CALL),
+		RequestId:   1,
+		Timeout:     uint32(opts.timeout / time.Millisecond),
+		Caller:      []byte(opts.caller),
+		Callee:      []byte(callee),
+		Func:        []byte(m.Func),
+		TransInfo:   transInfo,
+		ContentType: uint32(trpcpb.TrpcContentEncodeType_TRPC_PROTO_ENCODE),
+	}, nil
+}
@@ -0,0 +1,142 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package call
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	trpcpb "trpc.group/trpc/trpc-protocol/pb/go/trpc"
+
+	"trpc.group/trpc-go/trpc-cmdline/cmd/internal"
+	"trpc.group/trpc-go/trpc-cmdline/util/protocol"
+)
+
+func helloMethod(t *testing.T) *protocol.Method {
+	protoFlags := internal.ProtoFlags{Protofile: "../../docs/helloworld/helloworld.proto"}
+	fd, err := protoFlags.Load()
+	require.Nil(t, err)
+	m, err := protocol.FindMethod(fd, "HelloWorldService/Hello")
+	require.Nil(t, err)
+	return m
+}
+
+// serve is a stand-in of the helloworld service, which says hello to the msg of the request.
+func serve(t *testing.T, ln net.Listener, m *protocol.Method) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		req, body, err := protocol.ReadRequest(conn)
+		if !assert.Nil(t, err) {
+			return
+		}
+		rsp := &trpcpb.ResponseProtocol{
+			RequestId: req.GetRequestId(),
+			TransInfo: map[string][]byte{"caller": req.GetCaller(), "callee": req.GetCallee()},
+		}
+		for k, v := range req.GetTransInfo() {
+			rsp.TransInfo[k] = v
+		}
+		var rspBody []byte
+		if string(req.GetFunc()) != m.Func {
+			rsp.Ret = int32(trpcpb.TrpcRetCode_TRPC_SERVER_NOFUNC_ERR)
+			rsp.ErrorMsg = []byte("no such func " + string(req.GetFunc()))
+		} else {
+			in := dynamic.NewMessage(m.Input)
+			assert.Nil(t, in.Unmarshal(body))
+			out := dynamic.NewMessage(m.Output)
+			out.SetFieldByName("msg", "hello "+in.GetFieldByName("msg").(string))
+			rspBody, err = out.Marshal()
+			assert.Nil(t, err)
+		}
+		assert.Nil(t, protocol.WriteResponse(conn, rsp, rspBody))
+		conn.Close()
+	}
+}
+
+func TestCallCmd(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer ln.Close()
+	go serve(t, ln, helloMethod(t))
+
+	run := func(stdin string, args ...string) (*result, error) {
+		cmd := CMD()
+		cmd.SilenceUsage, cmd.SilenceErrors = true, true
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetIn(bytes.NewBufferString(stdin))
+		cmd.SetArgs(append([]string{
+			"-p", "../../docs/helloworld/helloworld.proto", "--target", "ip://" + ln.Addr().String(),
+		}, args...))
+		err := cmd.Execute()
+		if out.Len() == 0 {
+			return nil, err
+		}
+		res := &result{}
+		require.Nil(t, json.Unmarshal(out.Bytes(), res))
+		return res, err
+	}
+
+	res, err := run("", "helloworld.HelloWorldService/Hello", "-d", `{"msg":"hi"}`, "--meta", "k=v")
+	require.Nil(t, err)
+	require.JSONEq(t, `{"msg":"hello hi"}`, string(res.Body))
+	require.Equal(t, map[string]string{
+		"caller": defaultCaller,
+		"callee": "helloworld.HelloWorldService",
+		"k":      "v",
+	}, res.TransInfo)
+
+	data := filepath.Join(t.TempDir(), "req.json")
+	require.Nil(t, os.WriteFile(data, []byte(`{"msg":"file"}`), 0644))
+	res, err = run("", "/helloworld.HelloWorldService/Hello", "-d", "@"+data)
+	require.Nil(t, err)
+	require.JSONEq(t, `{"msg":"hello file"}`, string(res.Body))
+
+	res, err = run(`{"msg":"stdin"}`, "HelloWorldService/Hello", "-d", "@-", "--callee", "trpc.app.server.Hello")
+	require.Nil(t, err)
+	require.JSONEq(t, `{"msg":"hello stdin"}`, string(res.Body))
+	require.Equal(t, "trpc.app.server.Hello", res.TransInfo["callee"])
+
+	_, err = run("", "HelloWorldService/Bye")
+	require.ErrorContains(t, err, "available methods: /helloworld.HelloWorldService/Hello")
+	_, err = run("", "HelloWorldService/Hello", "-d", `{"no_such_field":1}`)
+	require.ErrorContains(t, err, "decode helloworld.HelloRequest from json")
+	_, err = run("", "HelloWorldService/Hello", "--meta", "novalue")
+	require.ErrorContains(t, err, "invalid meta")
+}
+
+func TestCall_Ret(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer ln.Close()
+	m := helloMethod(t)
+	go serve(t, ln, m)
+
+	bye := *m
+	bye.Func = "/helloworld.HelloWorldService/Bye"
+	out := &bytes.Buffer{}
+	err = call(out, &bye, nil, &options{target: ln.Addr().String(), timeout: defaultTimeout})
+	require.ErrorContains(t, err, "ret: 12")
+	res := &result{}
+	require.Nil(t, json.Unmarshal(out.Bytes(), res))
+	require.Equal(t, "no such func /helloworld.HelloWorldService/Bye", res.ErrorMsg)
+	require.Empty(t, res.Body)
+}
@@ -24,13 +24,20 @@ func AddCreateFlags(createCmd *cobra.Command) {
 
 	// Whether to pass protoc/flatc by the basename of "--protofile/--fbs" provided above.
 	createCmd.Flags().Bool("usebasename", false, "Whether to pass the basename of --protofile/--fbs to protoc/flatc")
+	// Compile the pb files by protoc or in-process.
+	createCmd.Flags().String("pb-compiler", "",
+		"Specify the protobuf compiler, supported compilers: protoc, builtin (compiles in-process without protoc, "+
+			"go only), defaults to the pb_compiler specified in ~/.trpc-cmdline-assets/trpc.yaml, or protoc")
 
 	// Generate stub code without IDL.
 	createCmd.Flags().StringP("non-protocol-type", "n", "",
-		"Generate project types without pb protocol support, supported types: kafka, http, hippo")
+		"Generate project types without pb protocol support, supported types: kafka, http, hippo, timer")
 
 	// Select code template.
 	createCmd.Flags().String("assetdir", "", "Specify the custom template path, e.g., ~/.trpc-cmdline-assets/protobuf/asset_go")
+	createCmd.Flags().StringArray("overlay", nil,
+		"Specify the template directories layered on top of the templates, the former ones take precedence, "+
+			"their files override the ones at the same paths beneath and .trpcignore lists the ones to delete")
 	createCmd.Flags().StringP("lang", "l", "go",
 		"Specify the programming language to use, supported languages: go, cpp")
 	createCmd.Flags().Bool("rpconly", false,
@@ -82,6 +89,19 @@ func addOutputFlags(createCmd *cobra.Command) {
 		"Specify the output directory (default: output to the directory with the same name as the pb file, "+
 			"rpconly defaults to the current directory)")
 	createCmd.Flags().BoolP("force", "f", false, "Force overwrite existing code")
+	createCmd.Flags().Bool("update", false,
+		"Update an existing project: add methods and tests for new RPCs, fix changed request/response types, "+
+			"mark removed RPCs, and keep the hand-written code untouched")
+	createCmd.Flags().Bool("lockfile", true,
+		"Write trpc-gen.lock recording the IDL inputs, tool versions, options and generated files into the output "+
+			"directory, which is checked by `trpc verify`")
+	createCmd.Flags().Bool("dry-run", false,
+		"Generate into a scratch directory and print the unified diff against the output directory without writing it, "+
+			"exits with code 2 if anything would change")
+	createCmd.Flags().Bool("no-hooks", false,
+		"Do not run the pre and post hooks of the template manifests, which are also skipped by --dry-run")
+	createCmd.Flags().Bool("run-hooks", false,
+		"Run the hooks of the template packs without confirmation, which are skipped if not running in a terminal")
 	createCmd.Flags().StringP("mod", "m", "", "Specify the go module, default: trpc.app.${pb.package}")
 	createCmd.Flags().String("goversion", "1.18", "Specify the Go version in the generated go.mod file, default: 1.18")
 	createCmd.Flags().String("trpcgoversion", "",
@@ -109,9 +129,17 @@ func addOutputFlags(createCmd *cobra.Command) {
 	// Parameters related to git address.
 	createCmd.Flags().Bool("sync", false,
 		"Whether to sync git repository, default address: specified by go_package, can be specified by --remote")
-	createCmd.Flags().String("remote", "", "If sync git repository, specify the git repository address")
+	createCmd.Flags().String("remote", "", "If sync git repository, specify the git repository address, "+
+		"such as git@host:path, ssh://, https:// or file:// URLs")
+	createCmd.Flags().String("branch", "",
+		"If sync git repository, push to the branch instead of the default one, auto to generate its name by the proto file")
+	createCmd.Flags().String("patch", "",
+		"If sync git repository, write the patch of the commit into the file instead of pushing, - for stdout")
 	createCmd.Flags().Bool("newtag", false, "Whether to tag the uploaded repository")
 	createCmd.Flags().String("tag", "",
 		"If tagging the repository, specify the tag name. If not specified, "+
-			"tag will be created in the format of v1.1.1, with rule of base 100")
+			"the semantic version is bumped by the API changes since the latest tag")
+	createCmd.Flags().String("goproxy", "",
+		"Publish the stubs as a module into the directory of the GOPROXY layout, versioned by --tag or "+
+			"the API changes since the latest version, which can be used by GOPROXY=file://<dir>")
 }
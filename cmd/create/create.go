@@ -15,6 +15,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -25,8 +26,12 @@ import (
 	"trpc.group/trpc-go/trpc-cmdline/tpl"
 	"trpc.group/trpc-go/trpc-cmdline/util/fs"
 	"trpc.group/trpc-go/trpc-cmdline/util/log"
+	"trpc.group/trpc-go/trpc-cmdline/util/pb"
 )
 
+// flagInteractive is the flag running the wizard, which is only owned by the create command.
+const flagInteractive = "interactive"
+
 // FD is an alias of descriptor.FileDescriptor.
 type FD = descriptor.FileDescriptor
 
@@ -35,6 +40,14 @@ type Create struct {
 	options        *params.Option
 	fileDescriptor *descriptor.FileDescriptor
 	preRunHook     func() error
+
+	dryRunTarget  string // The real output directory in dry-run mode.
+	dryRunScratch string // The scratch directory generated into in dry-run mode.
+
+	manifest *tpl.Manifest // Manifest of the templates, whose hooks run in the output directory.
+	runHook  *bool         // Whether the hooks are confirmed to run, nil if not asked yet.
+
+	startTime time.Time // When the generation starts, used to find the generated files.
 }
 
 // CMD returns create command.
@@ -54,6 +67,9 @@ func CMD() *cobra.Command {
 		PostRunE: c.PostRunE,
 	}
 	AddCreateFlags(createCmd)
+	createCmd.Flags().BoolP(flagInteractive, "i", false,
+		"Walk through the options interactively, print the equivalent command line and optionally save it into "+
+			GenManifest)
 	return createCmd
 }
 
@@ -81,6 +97,11 @@ func getEnvMap() map[string]string {
 
 // PreRunE provides *cobra.Command.PreRunE.
 func (c *Create) PreRunE(cmd *cobra.Command, args []string) error {
+	if i, _ := cmd.Flags().GetBool(flagInteractive); i {
+		if err := runWizard(cmd); err != nil {
+			return err
+		}
+	}
 	if err := c.loadOptions(cmd.Flags()); err != nil {
 		return fmt.Errorf("load create options inside pre run err: %w", err)
 	}
@@ -88,6 +109,9 @@ func (c *Create) PreRunE(cmd *cobra.Command, args []string) error {
 	if err := c.preRunHook(); err != nil {
 		return fmt.Errorf("pre run hook err: %w", err)
 	}
+	if err := c.loadManifest(); err != nil {
+		return err
+	}
 	// Non-pb type, pre run done.
 	if c.options.OtherType != "" {
 		return nil
@@ -122,19 +146,29 @@ func (c *Create) PreRunE(cmd *cobra.Command, args []string) error {
 	if c.options.Verbose {
 		c.fileDescriptor.Dump()
 	}
-	// Check and install dependencies.
-	return setup([]string{c.options.Language})
+	// Check and install dependencies, the builtin compiler needs neither protoc nor protoc-gen-go.
+	var skips []string
+	if c.options.PBCompiler == pb.CompilerBuiltin {
+		skips = append(skips, "protoc", "protoc-gen-go")
+	}
+	return setup([]string{c.options.Language}, skips...)
 }
 
 // RunE provides *cobra.Command.RunE.
-func (c *Create) RunE(cmd *cobra.Command, args []string) error {
+func (c *Create) RunE(cmd *cobra.Command, args []string) (err error) {
 	log.Debug("args: %v", args)
+	c.startTime = time.Now()
+	// PostRunE, which reports the diff, is skipped on failure, so clean up here.
+	defer func() {
+		if err != nil {
+			c.cleanupDryRun()
+		}
+	}()
 	// Create a project of non protocol type.
 	if c.options.OtherType != "" {
 		return c.createByNonProtocolType()
 	}
 	return c.createByProtocolType()
-
 }
 
 func (c *Create) createByProtocolType() error {
@@ -142,7 +176,15 @@ func (c *Create) createByProtocolType() error {
 	if err != nil {
 		return err
 	}
+	if c.options.DryRun {
+		if outputDir, err = c.prepareDryRun(outputDir); err != nil {
+			return err
+		}
+	}
 	c.options.OutputDir = outputDir
+	if err := c.runHooks(c.manifest.Hooks.Pre); err != nil {
+		return err
+	}
 	// Create by IDL protocol type.
 	// Create a full project.
 	// if ignore RPCOnly flag, create a full project
@@ -155,8 +197,14 @@ func (c *Create) createByProtocolType() error {
 
 func (c *Create) createFullProject() (err error) {
 	dir := c.options.OutputDir
+	if c.options.Update && c.options.Language != "go" {
+		return fmt.Errorf("update mode does not support language %s yet", c.options.Language)
+	}
 	// Check whether the output path is clean.
-	if !isCleanDir(dir, c.options.IDLType, c.options.Language) && !c.options.Force {
+	// Dry-run never touches the output path, and update mode keeps the existing code,
+	// so there is no risk of overwriting.
+	safe := c.options.Force || c.options.DryRun || c.options.Update
+	if !safe && !isCleanDir(dir, c.options.IDLType, c.options.Language) {
 		return fmt.Errorf("%s is not empty, use a clean path or provide -f to force overwrite", dir)
 	}
 
@@ -252,11 +300,20 @@ func (c *Create) createByNonProtocolType() error {
 	if err != nil {
 		return err
 	}
+	if c.options.DryRun {
+		if outputdir, err = c.prepareDryRun(outputdir); err != nil {
+			return err
+		}
+	}
 
 	err = os.MkdirAll(outputdir, os.ModePerm)
 	if err != nil {
 		return err
 	}
+	c.options.OutputDir = outputdir
+	if err := c.runHooks(c.manifest.Hooks.Pre); err != nil {
+		return err
+	}
 
 	fd := &FD{
 		PackageName: "trpc.app." + c.options.OtherType,
@@ -323,7 +380,8 @@ func getOutputDir(option *params.Option) (string, error) {
 	return filepath.Join(wd, fs.BaseNameWithoutExt(option.Protofile)), nil
 }
 
-func setup(languages []string) error {
+// setup checks and installs the dependencies of the languages, except the executables in skips.
+func setup(languages []string, skips ...string) error {
 	if _, err := config.Init(); err != nil {
 		return err
 	}
@@ -331,7 +389,17 @@ func setup(languages []string) error {
 	if err != nil {
 		return err
 	}
-	return config.SetupDependencies(deps)
+	skipped := make(map[string]bool, len(skips))
+	for _, skip := range skips {
+		skipped[skip] = true
+	}
+	required := deps[:0]
+	for _, dep := range deps {
+		if !skipped[dep.Executable] {
+			required = append(required, dep)
+		}
+	}
+	return config.SetupDependencies(required)
 }
 
 // should ignore rpcOnly flag
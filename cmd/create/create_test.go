@@ -29,7 +29,6 @@ import (
 	"trpc.group/trpc-go/trpc-cmdline/descriptor"
 	"trpc.group/trpc-go/trpc-cmdline/params"
 	"trpc.group/trpc-go/trpc-cmdline/parser"
-	"trpc.group/trpc-go/trpc-cmdline/plugin"
 	"trpc.group/trpc-go/trpc-cmdline/tpl"
 	"trpc.group/trpc-go/trpc-cmdline/util/pb"
 )
@@ -141,11 +140,6 @@ func Test_CreateCmd(t *testing.T) {
 
 	tmp := filepath.Join(os.TempDir(), "create/generated")
 	os.RemoveAll(tmp)
-	// Reset plugin configuration.
-	// First, run create_idl_non_test.go, then execute setNonProtocolTypeOption,
-	// which modifies the global variables plugin.Plugins and plugin.PluginsExt.
-	// Finally, when running create_test.go test, it does not reset, causing the mockgan plugin not to run.
-	resetPlugin()
 
 	// run createCmd
 	for _, tt := range testcases {
@@ -226,24 +220,6 @@ func init() {
 	AddCreateFlags(createCmd)
 }
 
-func resetPlugin() {
-	// Public plugin chain.
-	plugin.Plugins = []plugin.Plugin{
-		&plugin.Swagger{},  // swagger apidoc
-		&plugin.OpenAPI{},  // openapi apidoc
-		&plugin.Validate{}, // protoc-gen-secv
-	}
-	// Language-specific plugin chain.
-	plugin.PluginsExt = map[string][]plugin.Plugin{
-		"go": {
-			&plugin.GoImports{}, // goimports,  runs before mockgen, to eliminate `package import but unused` errors
-			&plugin.Formatter{}, // gofmt
-			&plugin.GoMock{},    // gomock
-			&plugin.GoTag{},     // custom go tag by proto field options
-		},
-	}
-}
-
 func runCreateCmd(t *testing.T, name string, opts []string, out string, wantErr bool) {
 	if err := createCmd.ParseFlags(opts); err != nil {
 		t.Fatalf("TEST CreateCmd/%s ParseFlags error = %v", name, err)
@@ -283,6 +259,10 @@ func resetFlags(cmd *cobra.Command) {
 			value := reflect.ValueOf(flag.Value).Elem().FieldByName("value")
 			ptr := (*[]string)(unsafe.Pointer(value.Pointer()))
 			*ptr = make([]string, 0)
+			if flag.DefValue == "[]" {
+				// The default is empty, setting it would append "[]".
+				return
+			}
 		}
 		_ = flag.Value.Set(flag.DefValue)
 	})
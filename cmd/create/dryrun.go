@@ -0,0 +1,110 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package create
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"trpc.group/trpc-go/trpc-cmdline/cmd/internal"
+	"trpc.group/trpc-go/trpc-cmdline/util/diff"
+	"trpc.group/trpc-go/trpc-cmdline/util/log"
+)
+
+// ExitCodeChanged is the exit code of dry-run when the output directory would be changed.
+// Following the convention of `diff` and `terraform plan -detailed-exitcode`,
+// 0 means nothing changes and 1 means an error occurs.
+const ExitCodeChanged = 2
+
+// prepareDryRun creates a scratch directory filled with the files of outputDir,
+// so that templates, protoc/flatc and plugins can run against it instead of outputDir.
+// The scratch directory is returned.
+func (c *Create) prepareDryRun(outputDir string) (string, error) {
+	target, err := filepath.Abs(outputDir)
+	if err != nil {
+		return "", fmt.Errorf("get absolute path of %s err: %w", outputDir, err)
+	}
+	scratch, err := os.MkdirTemp("", "trpc-dry-run-*")
+	if err != nil {
+		return "", fmt.Errorf("create dry-run scratch directory err: %w", err)
+	}
+	// Keep the base name, since some of the generators derive names from the output directory.
+	dir := filepath.Join(scratch, filepath.Base(target))
+	if err := copyTree(target, dir); err != nil {
+		os.RemoveAll(scratch)
+		return "", fmt.Errorf("copy %s into dry-run scratch directory err: %w", target, err)
+	}
+	c.dryRunTarget, c.dryRunScratch = target, scratch
+	log.Debug("dry-run: generating into %s instead of %s", dir, target)
+	return dir, nil
+}
+
+// reportDryRun writes the unified diff between the output directory and the scratch directory into w,
+// and removes the scratch directory.
+// An *internal.ExitError with ExitCodeChanged is returned if anything would change.
+func (c *Create) reportDryRun(w io.Writer) error {
+	defer c.cleanupDryRun()
+	files, err := diff.Dirs(c.dryRunTarget, c.options.OutputDir, diff.IgnoreGitDir)
+	if err != nil {
+		return fmt.Errorf("diff dry-run output err: %w", err)
+	}
+	for _, f := range files {
+		fmt.Fprint(w, f.Unified())
+	}
+	summary := diff.Summary(files)
+	fmt.Fprintf(w, "dry-run: %d added, %d modified, %d deleted in %s\n",
+		summary[diff.Added], summary[diff.Modified], summary[diff.Deleted], c.dryRunTarget)
+	for _, f := range files {
+		fmt.Fprintf(w, "  %-8s %s\n", f.Status, f.Path)
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	return &internal.ExitError{Code: ExitCodeChanged}
+}
+
+// cleanupDryRun removes the scratch directory, it is safe to call it more than once.
+func (c *Create) cleanupDryRun() {
+	if c.dryRunScratch == "" {
+		return
+	}
+	if err := os.RemoveAll(c.dryRunScratch); err != nil {
+		log.Error("remove dry-run scratch directory %s err: %v", c.dryRunScratch, err)
+	}
+	c.dryRunScratch = ""
+}
+
+// copyTree copies the regular files under src into dest, the .git directory is skipped.
+// Nothing is copied if src does not exist.
+func copyTree(src, dest string) error {
+	files, err := diff.ReadDir(src, diff.IgnoreGitDir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dest, os.ModePerm); err != nil {
+		return err
+	}
+	for rel, content := range files {
+		fi, err := os.Stat(filepath.Join(src, filepath.FromSlash(rel)))
+		if err != nil {
+			return err
+		}
+		fp := filepath.Join(dest, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(fp), os.ModePerm); err != nil {
+			return err
+		}
+		if err := os.WriteFile(fp, content, fi.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package create
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-cmdline/cmd/internal"
+	"trpc.group/trpc-go/trpc-cmdline/params"
+)
+
+func TestCreate_DryRun(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "helloworld")
+	require.Nil(t, os.MkdirAll(filepath.Join(target, ".git"), os.ModePerm))
+	require.Nil(t, os.WriteFile(filepath.Join(target, "main.go"), []byte("package main\n"), 0644))
+	require.Nil(t, os.WriteFile(filepath.Join(target, "old.go"), []byte("package main\n"), 0644))
+	require.Nil(t, os.WriteFile(filepath.Join(target, ".git", "HEAD"), []byte("ref\n"), 0644))
+
+	t.Run("nothing changed", func(t *testing.T) {
+		c := &Create{options: &params.Option{DryRun: true}}
+		dir, err := c.prepareDryRun(target)
+		require.Nil(t, err)
+		require.Equal(t, "helloworld", filepath.Base(dir))
+		require.NoFileExists(t, filepath.Join(dir, ".git", "HEAD"))
+		c.options.OutputDir = dir
+
+		buf := &bytes.Buffer{}
+		require.Nil(t, c.reportDryRun(buf))
+		require.Contains(t, buf.String(), "0 added, 0 modified, 0 deleted")
+		require.NoDirExists(t, dir)
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		c := &Create{options: &params.Option{DryRun: true}}
+		dir, err := c.prepareDryRun(target)
+		require.Nil(t, err)
+		c.options.OutputDir = dir
+		require.Nil(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644))
+		require.Nil(t, os.WriteFile(filepath.Join(dir, "new.go"), []byte("package main\n"), 0644))
+		require.Nil(t, os.Remove(filepath.Join(dir, "old.go")))
+
+		buf := &bytes.Buffer{}
+		err = c.reportDryRun(buf)
+		var exitErr *internal.ExitError
+		require.True(t, errors.As(err, &exitErr))
+		require.Equal(t, ExitCodeChanged, exitErr.Code)
+		out := buf.String()
+		require.Contains(t, out, "+++ b/main.go\n")
+		require.Contains(t, out, "+func main() {}\n")
+		require.Contains(t, out, "--- /dev/null\n+++ b/new.go\n")
+		require.Contains(t, out, "--- a/old.go\n+++ /dev/null\n")
+		require.Contains(t, out, "1 added, 1 modified, 1 deleted")
+		require.True(t, strings.Index(out, "main.go") < strings.Index(out, "new.go"))
+		require.NoDirExists(t, dir)
+
+		// The real output directory is left untouched.
+		b, err := os.ReadFile(filepath.Join(target, "main.go"))
+		require.Nil(t, err)
+		require.Equal(t, "package main\n", string(b))
+		require.FileExists(t, filepath.Join(target, "old.go"))
+		require.NoFileExists(t, filepath.Join(target, "new.go"))
+	})
+
+	t.Run("output directory not exist", func(t *testing.T) {
+		c := &Create{options: &params.Option{DryRun: true}}
+		dir, err := c.prepareDryRun(filepath.Join(t.TempDir(), "not_exist"))
+		require.Nil(t, err)
+		c.options.OutputDir = dir
+		require.Nil(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644))
+		var exitErr *internal.ExitError
+		require.True(t, errors.As(c.reportDryRun(&bytes.Buffer{}), &exitErr))
+	})
+}
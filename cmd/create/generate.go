@@ -123,6 +123,7 @@ func runProtoc(fd *FD, pbOutDir string, option *params.Option) ([]string, error)
 		pb.WithPb2ImportPath(fd.Pb2ImportPath),
 		pb.WithPkg2ImportPath(fd.Pkg2ImportPath),
 		pb.WithDescriptorSetIn(option.DescriptorSetIn),
+		pb.WithCompiler(option.PBCompiler),
 	}
 
 	var files []string
@@ -347,6 +348,7 @@ func (g *genDependencyRPCStubParam) genDependencyRPCStubPB() error {
 		pb.WithPb2ImportPath(g.fd.Pb2ImportPath),
 		pb.WithPkg2ImportPath(g.fd.Pkg2ImportPath),
 		pb.WithDescriptorSetIn(g.option.DescriptorSetIn),
+		pb.WithCompiler(g.option.PBCompiler),
 	}
 	if err = pb.Protoc(searchPath, g.fname, g.option.Language, g.outputDir, opts...); err != nil {
 		return fmt.Errorf("GenerateFiles: %v", err)
@@ -0,0 +1,181 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package create
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"trpc.group/trpc-go/trpc-cmdline/cmd/internal/lockfile"
+	"trpc.group/trpc-go/trpc-cmdline/config"
+	"trpc.group/trpc-go/trpc-cmdline/util/fs"
+	"trpc.group/trpc-go/trpc-cmdline/util/pb"
+)
+
+// unlockedFlags only affect where and how the code is written, so they are not recorded into the lock file.
+var unlockedFlags = map[string]bool{
+	"output":    true,
+	"force":     true,
+	"dry-run":   true,
+	"update":    true,
+	"lockfile":  true,
+	"sync":      true,
+	"remote":    true,
+	"branch":    true,
+	"patch":     true,
+	"newtag":    true,
+	"tag":       true,
+	"goproxy":   true,
+	"no-hooks":  true,
+	"run-hooks": true,
+	"verbose":   true,
+	"config":    true,
+	"help":      true,
+}
+
+// writeLock writes the lock file into the output directory.
+// Files modified since the generation started are recorded as generated files.
+func (c *Create) writeLock(flags *pflag.FlagSet, workdir string) error {
+	outputDir, err := filepath.Abs(c.options.OutputDir)
+	if err != nil {
+		return fmt.Errorf("get absolute path of %s err: %w", c.options.OutputDir, err)
+	}
+	rel, err := filepath.Rel(outputDir, workdir)
+	if err != nil {
+		return fmt.Errorf("get relative path of %s err: %w", workdir, err)
+	}
+	l := &lockfile.Lock{
+		Version: lockfile.Version,
+		Workdir: filepath.ToSlash(rel),
+		Flags:   lockedFlags(flags),
+		Tools:   c.toolVersions(),
+		Inputs:  c.inputHashes(),
+	}
+	if l.Files, err = c.generatedFiles(outputDir); err != nil {
+		return err
+	}
+	return l.Write(filepath.Join(outputDir, lockfile.Name))
+}
+
+func lockedFlags(flags *pflag.FlagSet) map[string][]string {
+	m := make(map[string][]string)
+	flags.VisitAll(func(f *pflag.Flag) {
+		if unlockedFlags[f.Name] {
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			m[f.Name] = sv.GetSlice()
+			return
+		}
+		m[f.Name] = []string{f.Value.String()}
+	})
+	return m
+}
+
+// toolVersions returns the versions of the tools which take part in the generation.
+func (c *Create) toolVersions() map[string]string {
+	tools := map[string]string{"trpc-cmdline": config.TRPCCliVersion}
+	var names []string
+	switch c.options.IDLType {
+	case config.IDLTypeProtobuf:
+		// The builtin compiler links protoc-gen-go in, whose version follows trpc-cmdline.
+		if c.options.PBCompiler != pb.CompilerBuiltin {
+			names = append(names, "protoc", "protoc-gen-go")
+		}
+	case config.IDLTypeFlatBuffers:
+		names = append(names, "flatc")
+	}
+	if c.options.Mockgen {
+		names = append(names, "mockgen")
+	}
+	for _, name := range names {
+		if v := lockfile.ToolVersion(name); v != "" {
+			tools[name] = v
+		}
+	}
+	return tools
+}
+
+// inputHashes returns the hashes of the IDL file and the IDL files it depends on.
+func (c *Create) inputHashes() map[string]string {
+	inputs := make(map[string]string)
+	if h, err := lockfile.HashFile(c.options.ProtofileAbs); err == nil {
+		inputs[filepath.ToSlash(c.options.Protofile)] = h
+	}
+	var deps []string
+	for _, ds := range c.fileDescriptor.Pb2DepsPbs {
+		deps = append(deps, ds...)
+	}
+	sort.Strings(deps)
+	for _, dep := range deps {
+		if _, ok := inputs[dep]; ok {
+			continue
+		}
+		fp, err := fs.LocateFile(dep, c.options.Protodirs)
+		if err != nil {
+			continue
+		}
+		if h, err := lockfile.HashFile(fp); err == nil {
+			inputs[dep] = h
+		}
+	}
+	return inputs
+}
+
+// generatedFiles returns the files under outputDir which are written since the generation started.
+func (c *Create) generatedFiles(outputDir string) ([]*lockfile.File, error) {
+	// Filesystems may keep the modification time in seconds.
+	since := c.startTime.Truncate(time.Second)
+	rpcOnly := c.options.RPCOnly && !ignoreRPCOnly(c.options)
+	var files []*lockfile.File
+	err := filepath.Walk(outputDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() || info.ModTime().Before(since) {
+			return nil
+		}
+		rel, err := filepath.Rel(outputDir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == lockfile.Name {
+			return nil
+		}
+		h, err := lockfile.HashFile(path)
+		if err != nil {
+			return err
+		}
+		files = append(files, &lockfile.File{
+			Path:   rel,
+			SHA256: h,
+			// Only the stub code of a full project is not meant to be edited.
+			Scaffold: !rpcOnly && !strings.HasPrefix(rel, "stub/"),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("collect generated files under %s err: %w", outputDir, err)
+	}
+	return files, nil
+}
@@ -0,0 +1,163 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package create
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"trpc.group/trpc-go/trpc-cmdline/config"
+	"trpc.group/trpc-go/trpc-cmdline/tpl"
+	"trpc.group/trpc-go/trpc-cmdline/util/log"
+	"trpc.group/trpc-go/trpc-cmdline/util/promptui"
+)
+
+// interactive reports whether the variables of the templates can be prompted for, which is replaced by tests.
+var interactive = func() bool {
+	fi, err := os.Stdin.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// loadManifest loads the manifest of the templates, and fills the variables not provided by --kvfile or --kvrawjson,
+// which are prompted for if the standard input is a terminal.
+func (c *Create) loadManifest() error {
+	m, err := tpl.LoadManifest(c.options)
+	if err != nil {
+		return fmt.Errorf("load template manifest err: %w", err)
+	}
+	var ask func(*tpl.Variable) (interface{}, error)
+	if interactive() {
+		ask = askVariable
+	}
+	if err := m.ResolveVariables(c.options, ask); err != nil {
+		return err
+	}
+	c.manifest = m
+	return nil
+}
+
+// runHooks runs the hooks of the manifest in the output directory, which are skipped by --no-hooks and --dry-run.
+func (c *Create) runHooks(commands []string) error {
+	if len(commands) == 0 {
+		return nil
+	}
+	if c.options.NoHooks || c.options.DryRun {
+		log.Info("skip hooks: %s", strings.Join(commands, "; "))
+		return nil
+	}
+	if ok, err := c.confirmHooks(); err != nil || !ok {
+		return err
+	}
+	if err := os.MkdirAll(c.options.OutputDir, os.ModePerm); err != nil {
+		return fmt.Errorf("create output directory err: %w", err)
+	}
+	return tpl.RunHooks(commands, c.options.OutputDir, c.options)
+}
+
+// confirmHooks asks once whether to run the hooks if any of them comes from a template layer other than
+// the built-in assets, such as an installed template pack. The hooks are printed, and run only if
+// confirmed or --run-hooks is given, so they are skipped if the standard input is not a terminal.
+func (c *Create) confirmHooks() (bool, error) {
+	if c.runHook != nil {
+		return *c.runHook, nil
+	}
+	ok := true
+	if layers := externalLayers(c.manifest.Hooks.Layers); len(layers) != 0 {
+		hooks := append(append([]string{}, c.manifest.Hooks.Pre...), c.manifest.Hooks.Post...)
+		log.Info("the templates of %s run the hooks:\n  %s", strings.Join(layers, ", "), strings.Join(hooks, "\n  "))
+		switch {
+		case c.options.RunHooks:
+		case interactive():
+			var err error
+			if ok, err = newPrompter().Confirm("Run the hooks of the templates", false); err != nil {
+				return false, fmt.Errorf("confirm hooks err: %w", err)
+			}
+		default:
+			ok = false
+		}
+		if !ok {
+			log.Info("skip the hooks, the generated files may be incomplete, run them by --run-hooks")
+		}
+	}
+	c.runHook = &ok
+	return ok, nil
+}
+
+// externalLayers returns the layers outside of the built-in assets, all of which are returned
+// if the assets are not installed.
+func externalLayers(layers []string) []string {
+	installPath, err := config.CurrentTemplatePath()
+	if err == nil {
+		installPath, err = filepath.Abs(installPath)
+	}
+	var external []string
+	for _, layer := range layers {
+		if err == nil {
+			abs, absErr := filepath.Abs(layer)
+			rel, relErr := filepath.Rel(installPath, abs)
+			if absErr == nil && relErr == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				continue
+			}
+		}
+		external = append(external, layer)
+	}
+	return external
+}
+
+// askVariable prompts for the variable, the input is parsed into the type of its default value.
+func askVariable(v *tpl.Variable) (interface{}, error) {
+	label := v.Name
+	if v.Description != "" {
+		label = fmt.Sprintf("%s (%s)", v.Description, v.Name)
+	}
+	var def string
+	if v.Default != nil {
+		def = fmt.Sprint(v.Default)
+	}
+	parse := variableParser(v.Default)
+	s, err := promptui.ReadWithDefault(label, def, func(s string) error {
+		_, err := parse(strings.TrimSpace(s))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parse(strings.TrimSpace(s))
+}
+
+// variableParser returns the function parsing the input into the type of def, which is a string if def is not
+// a bool or a number.
+func variableParser(def interface{}) func(string) (interface{}, error) {
+	switch def.(type) {
+	case bool:
+		return func(s string) (interface{}, error) {
+			v, err := strconv.ParseBool(s)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a bool", s)
+			}
+			return v, nil
+		}
+	case int, int64, float64:
+		return func(s string) (interface{}, error) {
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a number", s)
+			}
+			return v, nil
+		}
+	default:
+		return func(s string) (interface{}, error) {
+			return s, nil
+		}
+	}
+}
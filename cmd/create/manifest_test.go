@@ -0,0 +1,77 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package create
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-cmdline/config"
+	"trpc.group/trpc-go/trpc-cmdline/params"
+	"trpc.group/trpc-go/trpc-cmdline/tpl"
+)
+
+func TestCreate_runHooks(t *testing.T) {
+	oldInteractive, oldPrompter := interactive, newPrompter
+	defer func() { interactive, newPrompter = oldInteractive, oldPrompter }()
+	interactive = func() bool { return true }
+
+	installPath, err := config.CurrentTemplatePath()
+	require.Nil(t, err)
+	pack := t.TempDir()
+	// run runs the pre hook of the layers, and returns whether it has run and the questions asked.
+	run := func(layers []string, answers map[string]string, opt *params.Option) (bool, []string) {
+		p := &scripted{answers: answers}
+		newPrompter = func() prompter { return p }
+		opt.OutputDir = t.TempDir()
+		c := &Create{options: opt, manifest: &tpl.Manifest{Hooks: tpl.Hooks{
+			Pre: []string{"touch pre"}, Post: []string{"touch post"}, Layers: layers}}}
+		require.Nil(t, c.runHooks(c.manifest.Hooks.Pre))
+		// The question is asked only once.
+		require.Nil(t, c.runHooks(c.manifest.Hooks.Post))
+		_, err := os.Stat(filepath.Join(opt.OutputDir, "pre"))
+		return err == nil, p.asked
+	}
+
+	ran, asked := run([]string{filepath.Join(installPath, "protobuf", "asset_go")}, nil, &params.Option{})
+	require.True(t, ran, "the hooks of the built-in assets run without asking")
+	require.Empty(t, asked)
+
+	ran, asked = run([]string{pack}, nil, &params.Option{})
+	require.False(t, ran, "the hooks of the template packs are skipped by default")
+	require.Equal(t, []string{"Run the hooks of the templates"}, asked)
+	ran, asked = run([]string{pack}, map[string]string{"Run the hooks of the templates": "y"}, &params.Option{})
+	require.True(t, ran)
+	require.Len(t, asked, 1)
+
+	ran, asked = run([]string{pack}, nil, &params.Option{RunHooks: true})
+	require.True(t, ran, "--run-hooks runs the hooks without asking")
+	require.Empty(t, asked)
+
+	// The hooks of the template packs are skipped if not running in a terminal, unless --run-hooks is given.
+	interactive = func() bool { return false }
+	ran, asked = run([]string{pack}, map[string]string{"Run the hooks of the templates": "y"}, &params.Option{})
+	require.False(t, ran)
+	require.Empty(t, asked)
+	ran, _ = run([]string{pack}, nil, &params.Option{RunHooks: true})
+	require.True(t, ran)
+	ran, _ = run([]string{installPath}, nil, &params.Option{})
+	require.True(t, ran)
+
+	ran, asked = run([]string{pack}, nil, &params.Option{NoHooks: true})
+	require.False(t, ran)
+	require.Empty(t, asked)
+	ran, asked = run([]string{installPath}, nil, &params.Option{DryRun: true})
+	require.False(t, ran)
+	require.Empty(t, asked)
+}
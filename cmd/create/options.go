@@ -20,9 +20,10 @@ import (
 	"github.com/spf13/pflag"
 
 	"trpc.group/trpc-go/trpc-cmdline/config"
-	"trpc.group/trpc-go/trpc-cmdline/plugin"
+	"trpc.group/trpc-go/trpc-cmdline/tpl/pack"
 	"trpc.group/trpc-go/trpc-cmdline/util/fs"
 	"trpc.group/trpc-go/trpc-cmdline/util/lang"
+	"trpc.group/trpc-go/trpc-cmdline/util/log"
 	"trpc.group/trpc-go/trpc-cmdline/util/paths"
 	"trpc.group/trpc-go/trpc-cmdline/util/pb"
 )
@@ -106,7 +107,32 @@ func (c *Create) fixIDL() error {
 	if err := c.fixProtoDirs(); err != nil {
 		return fmt.Errorf("fix proto dirs err: %w", err)
 	}
-	return c.fixProtocolType()
+	if err := c.fixProtocolType(); err != nil {
+		return err
+	}
+	return c.fixPBCompiler()
+}
+
+// fixPBCompiler updates the protobuf compiler by trpc.yaml, and checks if it is supported by the language.
+func (c *Create) fixPBCompiler() error {
+	if c.options.IDLType != config.IDLTypeProtobuf {
+		return nil
+	}
+	if c.options.PBCompiler == "" {
+		c.options.PBCompiler = config.GlobalConfig().PBCompiler
+	}
+	if c.options.PBCompiler == "" {
+		c.options.PBCompiler = pb.CompilerProtoc
+	}
+	if !pb.ValidCompiler(c.options.PBCompiler) {
+		return fmt.Errorf("invalid pb compiler %s, supported compilers: %s, %s",
+			c.options.PBCompiler, pb.CompilerProtoc, pb.CompilerBuiltin)
+	}
+	if c.options.PBCompiler == pb.CompilerBuiltin && c.options.Language != "go" {
+		return fmt.Errorf("pb compiler %s only supports go, use %s for %s",
+			pb.CompilerBuiltin, pb.CompilerProtoc, c.options.Language)
+	}
+	return nil
 }
 
 // fixOtherType updates the options related to "OtherType".
@@ -124,15 +150,6 @@ func (c *Create) fixOtherType() error {
 	// May consider using c.options.OutputDir here.
 
 	c.options.Protofile = c.options.OtherType
-
-	// Plugins for code generation.
-	plugin.Plugins = []plugin.Plugin{
-		&plugin.GoImports{}, // goimports, runs before mockgen, to eliminate `package import but unused` errors
-		&plugin.Formatter{}, // gofmt
-	}
-
-	plugin.PluginsExt[c.options.Language] = nil
-
 	return nil
 }
 
@@ -188,6 +205,24 @@ func (c *Create) fixProtocolType() error {
 	}
 	if c.options.Assetdir == "" {
 		c.options.Assetdir = cfg.AssetDir
+		// The default pack set by `trpc template use` is layered on top of the built-in templates.
+		store, err := pack.OpenDefault()
+		if err != nil {
+			return fmt.Errorf("open template store err: %w", err)
+		}
+		p, err := store.Default(c.options.IDLType.String(), c.options.Language)
+		if err != nil {
+			return err
+		}
+		if p != nil {
+			log.Info("use template pack %s", p.ID())
+			c.options.Overlays = append(c.options.Overlays, p.AssetDir())
+		}
+	}
+	for i, dir := range c.options.Overlays {
+		if c.options.Overlays[i], err = filepath.Abs(dir); err != nil {
+			return fmt.Errorf("get absolute path of overlay %s err: %w", dir, err)
+		}
 	}
 	if c.options.Domain == "" {
 		c.options.Domain = config.GlobalConfig().Domain
@@ -259,6 +294,10 @@ func (c *Create) parseInputOptions(flags *pflag.FlagSet) error {
 	if err != nil {
 		return fmt.Errorf("flags parse assetdir string err: %w", err)
 	}
+	c.options.Overlays, err = flags.GetStringArray("overlay")
+	if err != nil {
+		return fmt.Errorf("flags parse overlay string array err: %w", err)
+	}
 	c.options.Language, err = flags.GetString("lang")
 	if err != nil {
 		return fmt.Errorf("flags parse lang string err: %w", err)
@@ -353,6 +392,26 @@ func (c *Create) parseOutputOptions(flags *pflag.FlagSet) error {
 	if err != nil {
 		return fmt.Errorf("flags parse force bool err: %w", err)
 	}
+	c.options.Update, err = flags.GetBool("update")
+	if err != nil {
+		return fmt.Errorf("flags parse update bool err: %w", err)
+	}
+	c.options.Lockfile, err = flags.GetBool("lockfile")
+	if err != nil {
+		return fmt.Errorf("flags parse lockfile bool err: %w", err)
+	}
+	c.options.DryRun, err = flags.GetBool("dry-run")
+	if err != nil {
+		return fmt.Errorf("flags parse dry-run bool err: %w", err)
+	}
+	c.options.NoHooks, err = flags.GetBool("no-hooks")
+	if err != nil {
+		return fmt.Errorf("flags parse no-hooks bool err: %w", err)
+	}
+	c.options.RunHooks, err = flags.GetBool("run-hooks")
+	if err != nil {
+		return fmt.Errorf("flags parse run-hooks bool err: %w", err)
+	}
 	c.options.Mockgen, err = flags.GetBool("mock")
 	if err != nil {
 		return fmt.Errorf("flags parse mock bool err: %w", err)
@@ -427,6 +486,10 @@ func (c *Create) parsePBIDLOptions(flags *pflag.FlagSet) error {
 	if err != nil {
 		return fmt.Errorf("flags get gotag bool failed err: %w", err)
 	}
+	c.options.PBCompiler, err = flags.GetString("pb-compiler")
+	if err != nil {
+		return fmt.Errorf("flags get pb-compiler string failed err: %w", err)
+	}
 	c.options.IDLType = config.IDLTypeProtobuf
 	return nil
 }
@@ -461,6 +524,16 @@ func (c *Create) parseSyncGitOptions(flags *pflag.FlagSet) error {
 		return fmt.Errorf("flags get git remote address url failed err: %w", err)
 	}
 	c.options.Remote = remote
+	branch, err := flags.GetString("branch")
+	if err != nil {
+		return fmt.Errorf("flags get git branch failed err: %w", err)
+	}
+	c.options.Branch = branch
+	patch, err := flags.GetString("patch")
+	if err != nil {
+		return fmt.Errorf("flags get git patch failed err: %w", err)
+	}
+	c.options.Patch = patch
 	newTag, err := flags.GetBool("newtag")
 	if err != nil {
 		return fmt.Errorf("flags get git new tag bool failed err: %w", err)
@@ -471,5 +544,16 @@ func (c *Create) parseSyncGitOptions(flags *pflag.FlagSet) error {
 		return fmt.Errorf("flags get git tag failed err: %w", err)
 	}
 	c.options.Tag = tag
+	goProxy, err := flags.GetString("goproxy")
+	if err != nil {
+		return fmt.Errorf("flags get goproxy failed err: %w", err)
+	}
+	// The plugins run inside the output directory.
+	if goProxy != "" {
+		if goProxy, err = filepath.Abs(goProxy); err != nil {
+			return fmt.Errorf("get absolute path of goproxy %s err: %w", goProxy, err)
+		}
+	}
+	c.options.GoProxy = goProxy
 	return nil
 }
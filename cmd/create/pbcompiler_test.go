@@ -0,0 +1,58 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package create
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-cmdline/cmd/internal"
+	"trpc.group/trpc-go/trpc-cmdline/config"
+	"trpc.group/trpc-go/trpc-cmdline/params"
+	"trpc.group/trpc-go/trpc-cmdline/util/pb"
+)
+
+func TestCreate_fixPBCompiler(t *testing.T) {
+	c := &Create{options: &params.Option{IDLType: config.IDLTypeProtobuf, Language: "go"}}
+	require.Nil(t, c.fixPBCompiler())
+	require.Equal(t, pb.CompilerProtoc, c.options.PBCompiler)
+
+	c.options.PBCompiler = "protoc3"
+	require.ErrorContains(t, c.fixPBCompiler(), "invalid pb compiler")
+
+	c.options.PBCompiler = pb.CompilerBuiltin
+	require.Nil(t, c.fixPBCompiler())
+	c.options.Language = "cpp"
+	require.ErrorContains(t, c.fixPBCompiler(), "only supports go")
+}
+
+func TestCreateCmd_BuiltinPBCompiler(t *testing.T) {
+	wd, err := os.Getwd()
+	require.Nil(t, err)
+	defer os.Chdir(wd)
+	dir := t.TempDir()
+	b, err := os.ReadFile(filepath.Join(wd, "../../docs/helloworld/helloworld.proto"))
+	require.Nil(t, err)
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "helloworld.proto"), b, 0644))
+	require.Nil(t, os.Chdir(dir))
+
+	createCmd := CMD()
+	createCmd.Flags().BoolP("verbose", "v", false, "")
+	require.Nil(t, createCmd.ParseFlags([]string{
+		"--protofile", "helloworld.proto", "--output", "out", "--rpconly", "--mock=false",
+		"--pb-compiler", pb.CompilerBuiltin,
+	}))
+	require.Nil(t, internal.Run(createCmd, nil))
+	require.FileExists(t, filepath.Join(dir, "out", "helloworld.pb.go"))
+	require.FileExists(t, filepath.Join(dir, "out", "helloworld.trpc.go"))
+}
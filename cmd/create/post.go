@@ -15,6 +15,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"trpc.group/trpc-go/trpc-cmdline/config"
 	"trpc.group/trpc-go/trpc-cmdline/plugin"
 	"trpc.group/trpc-go/trpc-cmdline/util/fs"
 	"trpc.group/trpc-go/trpc-cmdline/util/log"
@@ -24,12 +25,18 @@ import (
 func (c *Create) PostRunE(cmd *cobra.Command, args []string) error {
 	wd, _ := os.Getwd()
 	defer os.Chdir(wd)
+	defer c.cleanupDryRun()
+
+	chain, err := c.pluginChain()
+	if err != nil {
+		return fmt.Errorf("load plugin chain err: %w", err)
+	}
 
 	if err := os.Chdir(c.options.OutputDir); err != nil {
 		return err
 	}
 
-	for _, p := range append(plugin.Plugins, plugin.PluginsExt[c.options.Language]...) {
+	for _, p := range chain {
 		if !p.Check(c.fileDescriptor, c.options) {
 			continue
 		}
@@ -48,10 +55,45 @@ func (c *Create) PostRunE(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if err := c.runHooks(c.manifest.Hooks.Post); err != nil {
+		return err
+	}
+
+	// The lock file of a dry-run would record the scratch directory, and projects without IDL have nothing to verify.
+	if c.options.Lockfile && !c.options.DryRun && c.options.OtherType == "" {
+		if err := c.writeLock(cmd.Flags(), wd); err != nil {
+			return fmt.Errorf("write lock file err: %w", err)
+		}
+	}
+
 	log.Info(
 		"Create tRPC project %s`%s`%s post process: succeed! (〃'▽'〃)",
 		log.ColorRed,
 		fs.BaseNameWithoutExt(c.fileDescriptor.FilePath),
 		log.ColorGreen)
+	if c.options.DryRun {
+		return c.reportDryRun(cmd.OutOrStdout())
+	}
 	return nil
 }
+
+// pluginChain returns the plugins to run, whose order and enable/disable state
+// are decided by the plugins section of the project or global config.
+func (c *Create) pluginChain() ([]plugin.Plugin, error) {
+	cfgs, err := config.PluginConfigs(c.options.Language)
+	if err != nil {
+		return nil, err
+	}
+	if c.options.PluginSettings == nil {
+		c.options.PluginSettings = make(map[string]map[string]interface{})
+	}
+	chain, err := plugin.Chain(c.options.Language, cfgs, c.options.PluginSettings)
+	if err != nil {
+		return nil, err
+	}
+	// Projects without IDL only need to be formatted.
+	if c.options.OtherType != "" {
+		chain = plugin.Filter(chain, plugin.NonIDLPlugins...)
+	}
+	return chain, nil
+}
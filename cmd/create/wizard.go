@@ -0,0 +1,375 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package create
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+
+	"trpc.group/trpc-go/trpc-cmdline/cmd/internal"
+	"trpc.group/trpc-go/trpc-cmdline/util/promptui"
+)
+
+// GenManifest is the project manifest the wizard saves its answers into, which is read by `trpc generate`.
+const GenManifest = "trpc-gen.yaml"
+
+// nonIDLTypes are the project types generated without IDL, see install/without_idl.
+var nonIDLTypes = []string{"http", "kafka", "hippo", "timer"}
+
+// Kinds of projects asked by the wizard.
+const (
+	kindProtobuf    = "protobuf: project or stubs from a .proto file"
+	kindFlatbuffers = "flatbuffers: project or stubs from a .fbs file"
+	kindNonIDL      = "without IDL: kafka, http, hippo or timer project"
+)
+
+// Answers of the rpconly question.
+const (
+	fullProject = "full project"
+	stubsOnly   = "RPC stubs only"
+)
+
+// prompter asks the questions of the wizard, which is replaced by tests.
+type prompter interface {
+	Input(label, def string, validate func(string) error) (string, error)
+	Select(label string, items []string, def string) (string, error)
+	Confirm(label string, def bool) (bool, error)
+}
+
+type terminal struct{}
+
+func (terminal) Input(label, def string, validate func(string) error) (string, error) {
+	return promptui.ReadWithDefault(label, def, validate)
+}
+
+func (terminal) Select(label string, items []string, def string) (string, error) {
+	return promptui.Select(label, items, def)
+}
+
+func (terminal) Confirm(label string, def bool) (bool, error) {
+	return promptui.Confirm(label, def)
+}
+
+var newPrompter = func() prompter { return terminal{} }
+
+// runWizard asks for the flags of the create command, prints the equivalent command line,
+// and saves it into GenManifest if the user agrees.
+// The flags provided on the command line are the defaults of the questions.
+func runWizard(cmd *cobra.Command) error {
+	if !interactive() {
+		return errors.New("interactive mode requires a terminal")
+	}
+	w := &wizard{p: newPrompter(), flags: cmd.Flags()}
+	if err := w.ask(); err != nil {
+		return fmt.Errorf("interactive create err: %w", err)
+	}
+	args := commandLine(cmd.LocalFlags())
+	fmt.Fprintf(cmd.OutOrStdout(), "The equivalent command is:\n\n  trpc create %s\n\n", strings.Join(args, " "))
+	save, err := w.p.Confirm("Save it as an entry of "+GenManifest+" for `trpc generate`", false)
+	if err != nil || !save {
+		return err
+	}
+	name, err := w.p.Input("Name of the entry", w.entryName(), nonEmpty)
+	if err != nil {
+		return err
+	}
+	if err := saveEntry(GenManifest, strings.TrimSpace(name), cmd.LocalFlags()); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Saved as entry %s of %s, run `trpc generate --only %s` to generate again.\n",
+		name, GenManifest, name)
+	return nil
+}
+
+type wizard struct {
+	p     prompter
+	flags *pflag.FlagSet
+}
+
+func (w *wizard) ask() error {
+	kind := kindProtobuf
+	switch {
+	case w.value("non-protocol-type") != "":
+		kind = kindNonIDL
+	case w.value("fbs") != "":
+		kind = kindFlatbuffers
+	}
+	kind, err := w.p.Select("What to create", []string{kindProtobuf, kindFlatbuffers, kindNonIDL}, kind)
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case kindNonIDL:
+		err = w.askNonIDL()
+	case kindFlatbuffers:
+		err = w.askIDL("fbs", "fbsdir", "Flatbuffers file")
+	default:
+		err = w.askIDL("protofile", "protodir", "Protobuf file")
+	}
+	if err != nil {
+		return err
+	}
+	if err := w.input("output", "Output directory, empty for the default", nil); err != nil {
+		return err
+	}
+	if w.value("lang") == "go" {
+		if err := w.input("mod", "Go module path, empty for the default", nil); err != nil {
+			return err
+		}
+	}
+	return w.askSync()
+}
+
+func (w *wizard) askNonIDL() error {
+	if err := w.choose("non-protocol-type", "Project type", nonIDLTypes); err != nil {
+		return err
+	}
+	// Only go templates are provided without IDL.
+	return w.set("lang", "go")
+}
+
+func (w *wizard) askIDL(file, dirs, label string) error {
+	def := strings.Join(w.values(dirs), ",")
+	s, err := w.p.Input("Search paths of the IDL files, separated by commas", def, nil)
+	if err != nil {
+		return err
+	}
+	if err := w.set(dirs, splitList(s)...); err != nil {
+		return err
+	}
+	if err := w.input(file, label, existingIn(w.values(dirs))); err != nil {
+		return err
+	}
+	if err := w.choose("lang", "Language", []string{"go", "cpp"}); err != nil {
+		return err
+	}
+	if err := w.input("protocol", "Protocol, such as trpc or http", nonEmpty); err != nil {
+		return err
+	}
+	def = fullProject
+	if w.value("rpconly") == "true" {
+		def = stubsOnly
+	}
+	mode, err := w.p.Select("Generate", []string{fullProject, stubsOnly}, def)
+	if err != nil {
+		return err
+	}
+	if err := w.set("rpconly", strconv.FormatBool(mode == stubsOnly)); err != nil {
+		return err
+	}
+	if w.value("lang") != "go" {
+		return nil
+	}
+	for _, q := range []struct{ name, label string }{
+		{"mock", "Generate mocks"},
+		{"gotag", "Generate custom struct tags"},
+		{"validate", "Generate validation code by protoc-gen-validate"},
+		{"swagger", "Generate Swagger API documentation"},
+	} {
+		if err := w.confirm(q.name, q.label); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *wizard) askSync() error {
+	if err := w.confirm("sync", "Sync the stubs into the git repository"); err != nil {
+		return err
+	}
+	if w.value("sync") != "true" {
+		return nil
+	}
+	return w.input("remote", "Git repository, empty for the one of go_package", nil)
+}
+
+// input asks for the value of the string flag.
+func (w *wizard) input(name, label string, validate func(string) error) error {
+	s, err := w.p.Input(label, w.value(name), validate)
+	if err != nil {
+		return err
+	}
+	return w.set(name, strings.TrimSpace(s))
+}
+
+// choose asks for the value of the string flag among items.
+func (w *wizard) choose(name, label string, items []string) error {
+	s, err := w.p.Select(label, items, w.value(name))
+	if err != nil {
+		return err
+	}
+	return w.set(name, s)
+}
+
+// confirm asks for the value of the bool flag.
+func (w *wizard) confirm(name, label string) error {
+	b, err := w.p.Confirm(label, w.value(name) == "true")
+	if err != nil {
+		return err
+	}
+	return w.set(name, strconv.FormatBool(b))
+}
+
+func (w *wizard) value(name string) string {
+	return w.flags.Lookup(name).Value.String()
+}
+
+func (w *wizard) values(name string) []string {
+	return internal.FlagValues(w.flags.Lookup(name))
+}
+
+// set sets the flag unless it is unchanged, so that the command line only contains the flags that matter.
+func (w *wizard) set(name string, vals ...string) error {
+	if strings.Join(vals, ",") == strings.Join(w.values(name), ",") {
+		return nil
+	}
+	return internal.SetFlag(w.flags, name, vals...)
+}
+
+// entryName returns the default name of the manifest entry, which is the base name of the IDL file
+// or the project type.
+func (w *wizard) entryName() string {
+	for _, name := range []string{"protofile", "fbs", "non-protocol-type"} {
+		if v := w.value(name); v != "" {
+			return strings.TrimSuffix(filepath.Base(v), filepath.Ext(v))
+		}
+	}
+	return ""
+}
+
+// commandLine returns the changed flags as command line arguments, in the order of their names.
+func commandLine(flags *pflag.FlagSet) []string {
+	var args []string
+	flags.VisitAll(func(f *pflag.Flag) {
+		if !f.Changed || f.Name == flagInteractive {
+			return
+		}
+		if f.Value.Type() == "bool" {
+			if f.Value.String() == "true" {
+				args = append(args, "--"+f.Name)
+			} else {
+				args = append(args, "--"+f.Name+"=false")
+			}
+			return
+		}
+		for _, v := range internal.FlagValues(f) {
+			args = append(args, "--"+f.Name, shellQuote(v))
+		}
+	})
+	return args
+}
+
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\$`*?[]{}()<>|&;#~!") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// genManifest is the part of the manifest of `trpc generate` kept by saveEntry, the order of the keys is kept.
+type genManifest struct {
+	Version  string          `yaml:"version"`
+	Defaults yaml.MapSlice   `yaml:"defaults,omitempty"`
+	Entries  []yaml.MapSlice `yaml:"entries"`
+}
+
+// saveEntry adds the changed flags as an entry named name into the manifest fp, replacing the entry of
+// the same name.
+func saveEntry(fp, name string, flags *pflag.FlagSet) error {
+	m := &genManifest{Version: "v1"}
+	b, err := os.ReadFile(fp)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read %s err: %w", fp, err)
+	}
+	if err := yaml.Unmarshal(b, m); err != nil {
+		return fmt.Errorf("yaml unmarshal %s err: %w", fp, err)
+	}
+	entry := yaml.MapSlice{{Key: "name", Value: name}}
+	flags.VisitAll(func(f *pflag.Flag) {
+		if !f.Changed || f.Name == flagInteractive {
+			return
+		}
+		var v interface{}
+		switch {
+		case f.Value.Type() == "bool":
+			v = f.Value.String() == "true"
+		case isList(f):
+			v = internal.FlagValues(f)
+		default:
+			v = f.Value.String()
+		}
+		entry = append(entry, yaml.MapItem{Key: f.Name, Value: v})
+	})
+	var replaced bool
+	for i, e := range m.Entries {
+		if len(e) != 0 && e[0].Key == "name" && e[0].Value == name {
+			m.Entries[i], replaced = entry, true
+		}
+	}
+	if !replaced {
+		m.Entries = append(m.Entries, entry)
+	}
+	if b, err = yaml.Marshal(m); err != nil {
+		return fmt.Errorf("yaml marshal %s err: %w", fp, err)
+	}
+	if err := os.WriteFile(fp, b, 0644); err != nil {
+		return fmt.Errorf("write %s err: %w", fp, err)
+	}
+	return nil
+}
+
+func isList(f *pflag.Flag) bool {
+	_, ok := f.Value.(pflag.SliceValue)
+	return ok
+}
+
+// existingIn validates that the file exists inside any of the dirs.
+func existingIn(dirs []string) func(string) error {
+	return func(s string) error {
+		if err := nonEmpty(s); err != nil {
+			return err
+		}
+		s = strings.TrimSpace(s)
+		for _, dir := range dirs {
+			if _, err := os.Stat(filepath.Join(dir, s)); err == nil {
+				return nil
+			}
+		}
+		if _, err := os.Stat(s); err == nil {
+			return nil
+		}
+		return fmt.Errorf("%s is not found in %s", s, strings.Join(dirs, ", "))
+	}
+}
+
+func nonEmpty(s string) error {
+	if strings.TrimSpace(s) == "" {
+		return errors.New("empty input")
+	}
+	return nil
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
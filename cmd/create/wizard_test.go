@@ -0,0 +1,141 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package create
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+// scripted answers the questions by their labels, the unknown questions take their defaults.
+type scripted struct {
+	answers map[string]string
+	asked   []string
+}
+
+func (s *scripted) answer(label, def string) string {
+	s.asked = append(s.asked, label)
+	if a, ok := s.answers[label]; ok {
+		return a
+	}
+	return def
+}
+
+func (s *scripted) Input(label, def string, validate func(string) error) (string, error) {
+	a := s.answer(label, def)
+	if validate != nil {
+		if err := validate(a); err != nil {
+			return "", err
+		}
+	}
+	return a, nil
+}
+
+func (s *scripted) Select(label string, _ []string, def string) (string, error) {
+	return s.answer(label, def), nil
+}
+
+func (s *scripted) Confirm(label string, def bool) (bool, error) {
+	return s.answer(label, map[bool]string{true: "y", false: "n"}[def]) == "y", nil
+}
+
+func TestWizard(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	require.Nil(t, err)
+	require.Nil(t, os.Chdir(dir))
+	defer os.Chdir(wd)
+	require.Nil(t, os.MkdirAll("protos", os.ModePerm))
+	require.Nil(t, os.WriteFile(filepath.Join("protos", "hello.proto"), []byte(`syntax = "proto3";`), 0644))
+
+	oldInteractive, oldPrompter := interactive, newPrompter
+	defer func() { interactive, newPrompter = oldInteractive, oldPrompter }()
+	interactive = func() bool { return true }
+
+	run := func(answers map[string]string, args ...string) (*scripted, string) {
+		p := &scripted{answers: answers}
+		newPrompter = func() prompter { return p }
+		cmd := CMD()
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		require.Nil(t, cmd.Flags().Parse(append(args, "-i")))
+		require.Nil(t, runWizard(cmd))
+		return p, out.String()
+	}
+
+	t.Run("protobuf", func(t *testing.T) {
+		p, out := run(map[string]string{
+			"Search paths of the IDL files, separated by commas": "., protos",
+			"Protobuf file":                         "hello.proto",
+			"Generate":                              stubsOnly,
+			"Go module path, empty for the default": "example.com/hello",
+			"Generate mocks":                        "n",
+			"Generate Swagger API documentation":    "y",
+			"Save it as an entry of " + GenManifest + " for `trpc generate`": "y",
+		}, "--output", "stub dir")
+		require.Contains(t, out, "trpc create --mock=false --mod example.com/hello --output 'stub dir' "+
+			"--protodir . --protodir protos --protofile hello.proto --rpconly --swagger\n")
+		require.Contains(t, p.asked, "Name of the entry")
+		require.NotContains(t, p.asked, "Project type")
+
+		b, err := os.ReadFile(GenManifest)
+		require.Nil(t, err)
+		require.Equal(t, `version: v1
+entries:
+- name: hello
+  mock: false
+  mod: example.com/hello
+  output: stub dir
+  protodir:
+  - .
+  - protos
+  protofile: hello.proto
+  rpconly: true
+  swagger: true
+`, string(b))
+	})
+
+	t.Run("without idl", func(t *testing.T) {
+		p, out := run(map[string]string{
+			"What to create": kindNonIDL,
+			"Project type":   "timer",
+			"Save it as an entry of " + GenManifest + " for `trpc generate`": "y",
+			"Name of the entry": "hello",
+		})
+		require.Contains(t, out, "trpc create --non-protocol-type timer\n")
+		require.NotContains(t, p.asked, "Language")
+
+		// The entry of the same name is replaced.
+		b, err := os.ReadFile(GenManifest)
+		require.Nil(t, err)
+		m := &genManifest{}
+		require.Nil(t, yaml.Unmarshal(b, m))
+		require.Len(t, m.Entries, 1)
+		require.Equal(t, yaml.MapSlice{{Key: "name", Value: "hello"}, {Key: "non-protocol-type", Value: "timer"}},
+			m.Entries[0])
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		newPrompter = func() prompter {
+			return &scripted{answers: map[string]string{"Protobuf file": "nope.proto"}}
+		}
+		cmd := CMD()
+		require.Nil(t, cmd.Flags().Parse([]string{"-i"}))
+		err := runWizard(cmd)
+		require.NotNil(t, err)
+		require.True(t, strings.Contains(err.Error(), "nope.proto is not found in ."), err.Error())
+	})
+}
@@ -0,0 +1,134 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+// Package generate provides generate command.
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"trpc.group/trpc-go/trpc-cmdline/cmd/create"
+	"trpc.group/trpc-go/trpc-cmdline/cmd/internal"
+	"trpc.group/trpc-go/trpc-cmdline/util/log"
+)
+
+// Flags owned by the generate command, which are not passed to create.
+const (
+	flagManifest = "manifest"
+	flagOnly     = "only"
+)
+
+// CMD returns generate command.
+func CMD() *cobra.Command {
+	generateCmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate projects or RPC stubs for every entry listed in the manifest",
+		Long: `Generate projects or RPC stubs for every entry listed in the manifest (trpc-gen.yaml by default).
+
+Each entry of the manifest is a 'trpc create' invocation, whose keys are the flags of 'trpc create'.
+The precedence is: command line flags > entry > defaults of the manifest > defaults of 'trpc create'.
+Relative paths are resolved against the directory of the manifest.
+
+For example:
+  version: v1
+  defaults:
+    domain: trpc.group
+  entries:
+    - name: helloworld
+      protofile: helloworld.proto
+      protodir: [., ../common]
+      output: stub/helloworld
+      rpconly: true
+`,
+		Args: cobra.NoArgs,
+		RunE: runGenerate,
+	}
+	generateCmd.Flags().String(flagManifest, DefaultManifest, "Path to the generation manifest")
+	generateCmd.Flags().StringSlice(flagOnly, nil,
+		"Only run the entries with the given names, can be specified multiple times")
+	// Flags of create override the values inside the manifest.
+	create.AddCreateFlags(generateCmd)
+	return generateCmd
+}
+
+func runGenerate(cmd *cobra.Command, _ []string) error {
+	fp, err := cmd.Flags().GetString(flagManifest)
+	if err != nil {
+		return fmt.Errorf("flags parse manifest string err: %w", err)
+	}
+	only, err := cmd.Flags().GetStringSlice(flagOnly)
+	if err != nil {
+		return fmt.Errorf("flags parse only string slice err: %w", err)
+	}
+	m, err := LoadManifest(fp)
+	if err != nil {
+		return err
+	}
+	entries, err := m.Select(only)
+	if err != nil {
+		return err
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory err: %w", err)
+	}
+	defer os.Chdir(wd)
+	var changed bool
+	for _, e := range entries {
+		if err := os.Chdir(m.dir); err != nil {
+			return fmt.Errorf("chdir into %s err: %w", m.dir, err)
+		}
+		log.Info("generate entry %s", e)
+		err := runEntry(cmd.Flags(), m, e)
+		var exitErr *internal.ExitError
+		if errors.As(err, &exitErr) && exitErr.Code == create.ExitCodeChanged {
+			// Dry-run reports the changes, go on with the rest entries.
+			changed = true
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("generate entry %s err: %w", e, err)
+		}
+	}
+	if changed {
+		return &internal.ExitError{Code: create.ExitCodeChanged}
+	}
+	return nil
+}
+
+// runEntry runs the create command for the entry.
+func runEntry(overrides *pflag.FlagSet, m *Manifest, e *Entry) error {
+	createCmd := create.CMD()
+	flags := createCmd.Flags()
+	// Persistent flags of the root command, such as --verbose, are shared.
+	internal.ShareFlags(overrides, flags, flagManifest, flagOnly)
+	if err := applyValues(flags, m.Defaults); err != nil {
+		return fmt.Errorf("apply defaults of manifest err: %w", err)
+	}
+	if err := applyValues(flags, e.Flags); err != nil {
+		return err
+	}
+	var err error
+	overrides.Visit(func(f *pflag.Flag) {
+		if err != nil || f.Name == flagManifest || f.Name == flagOnly {
+			return
+		}
+		err = internal.SetFlag(flags, f.Name, internal.FlagValues(f)...)
+	})
+	if err != nil {
+		return fmt.Errorf("apply command line flags err: %w", err)
+	}
+	return internal.Run(createCmd, nil)
+}
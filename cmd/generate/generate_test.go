@@ -0,0 +1,133 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-cmdline/cmd/internal"
+	"trpc.group/trpc-go/trpc-cmdline/config"
+)
+
+func writeManifest(t *testing.T, content string) string {
+	fp := filepath.Join(t.TempDir(), DefaultManifest)
+	require.Nil(t, os.WriteFile(fp, []byte(content), 0644))
+	return fp
+}
+
+func TestLoadManifest(t *testing.T) {
+	fp := writeManifest(t, `
+version: v1
+defaults:
+  domain: trpc.group
+entries:
+  - name: helloworld
+    protofile: helloworld.proto
+    protodir: [., ../common]
+  - fbs: greeter.fbs
+`)
+	m, err := LoadManifest(fp)
+	require.Nil(t, err)
+	require.Equal(t, filepath.Dir(fp), m.dir)
+	require.Equal(t, "trpc.group", m.Defaults["domain"])
+	require.Len(t, m.Entries, 2)
+	require.Equal(t, "helloworld", m.Entries[0].String())
+	require.Equal(t, "greeter.fbs", m.Entries[1].String())
+	require.Equal(t, []interface{}{".", "../common"}, m.Entries[0].Flags["protodir"])
+
+	entries, err := m.Select([]string{"helloworld"})
+	require.Nil(t, err)
+	require.Len(t, entries, 1)
+	_, err = m.Select([]string{"not_exist"})
+	require.NotNil(t, err)
+
+	_, err = LoadManifest(writeManifest(t, "version: v2\nentries:\n  - protofile: a.proto\n"))
+	require.NotNil(t, err)
+	_, err = LoadManifest(writeManifest(t, "version: v1\n"))
+	require.NotNil(t, err)
+	_, err = LoadManifest(writeManifest(t, "unknown: 1\nentries:\n  - protofile: a.proto\n"))
+	require.NotNil(t, err)
+}
+
+func TestApplyValues(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.StringArrayP("protodir", "d", []string{"."}, "")
+	fs.Bool("rpconly", false, "")
+	fs.String("domain", "", "")
+
+	require.Nil(t, applyValues(fs, map[string]interface{}{
+		"protodir": []interface{}{"a", "b"},
+		"rpconly":  true,
+	}))
+	// Values applied later replace the earlier ones, including lists.
+	require.Nil(t, applyValues(fs, map[string]interface{}{
+		"protodir": "c",
+		"domain":   "trpc.group",
+	}))
+	dirs, err := fs.GetStringArray("protodir")
+	require.Nil(t, err)
+	require.Equal(t, []string{"c"}, dirs)
+	rpcOnly, err := fs.GetBool("rpconly")
+	require.Nil(t, err)
+	require.True(t, rpcOnly)
+
+	require.NotNil(t, applyValues(fs, map[string]interface{}{"unknown": 1}))
+	require.NotNil(t, applyValues(fs, map[string]interface{}{"domain": []interface{}{"a", "b"}}))
+	require.NotNil(t, applyValues(fs, map[string]interface{}{"rpconly": "not_bool"}))
+	require.NotNil(t, applyValues(fs, map[string]interface{}{"domain": nil}))
+}
+
+func TestGenerate(t *testing.T) {
+	_, err := config.Init()
+	require.Nil(t, err)
+	wd, err := os.Getwd()
+	require.Nil(t, err)
+	defer os.Chdir(wd)
+
+	fp := writeManifest(t, `
+version: v1
+defaults:
+  mock: false
+entries:
+  - name: http
+    non-protocol-type: http
+    output: out/http
+  - name: kafka
+    non-protocol-type: kafka
+    output: out/kafka
+`)
+	dir := filepath.Dir(fp)
+
+	cmd := CMD()
+	cmd.Flags().BoolP("verbose", "v", false, "")
+	require.Nil(t, cmd.Flags().Set(flagManifest, fp))
+	require.Nil(t, cmd.Flags().Set(flagOnly, "http"))
+	_, err = internal.RunAndWatch(cmd, nil, nil)
+	require.Nil(t, err)
+	require.DirExists(t, filepath.Join(dir, "out/http"))
+	require.NoDirExists(t, filepath.Join(dir, "out/kafka"))
+
+	// Flags on the command line override the manifest.
+	cmd = CMD()
+	cmd.Flags().BoolP("verbose", "v", false, "")
+	_, err = internal.RunAndWatch(cmd, map[string]string{
+		flagManifest: fp,
+		flagOnly:     "kafka",
+		"output":     filepath.Join(dir, "override"),
+	}, nil)
+	require.Nil(t, err)
+	require.DirExists(t, filepath.Join(dir, "override"))
+	require.NoDirExists(t, filepath.Join(dir, "out/kafka"))
+}
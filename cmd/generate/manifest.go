@@ -0,0 +1,161 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+
+	"trpc.group/trpc-go/trpc-cmdline/cmd/internal"
+)
+
+// DefaultManifest is the default file name of the generation manifest.
+const DefaultManifest = "trpc-gen.yaml"
+
+// ManifestVersion is the only supported manifest version.
+const ManifestVersion = "v1"
+
+// Manifest is the project level generation manifest, e.g.
+//
+//	version: v1
+//	defaults:            # applied to every entry
+//	  domain: trpc.group
+//	  mock: false
+//	entries:
+//	  - name: helloworld
+//	    protofile: helloworld.proto
+//	    protodir: [., ../common]
+//	    output: stub/helloworld
+//	    rpconly: true
+//	  - fbs: greeter.fbs
+//	    output: greeter
+//
+// The keys of defaults and entries are the flags of `trpc create` without the leading dashes,
+// relative paths are resolved against the directory of the manifest.
+type Manifest struct {
+	Version  string                 `yaml:"version"`
+	Defaults map[string]interface{} `yaml:"defaults"`
+	Entries  []*Entry               `yaml:"entries"`
+
+	dir string // Directory of the manifest.
+}
+
+// Entry is a single generation inside the manifest.
+type Entry struct {
+	Name  string                 `yaml:"name"` // Optional name, used to select entries and in logs.
+	Flags map[string]interface{} `yaml:",inline"`
+}
+
+// String returns the name of the entry, or the IDL file if the name is empty.
+func (e *Entry) String() string {
+	if e.Name != "" {
+		return e.Name
+	}
+	for _, k := range []string{"protofile", "fbs", "non-protocol-type"} {
+		if v, ok := e.Flags[k]; ok {
+			return fmt.Sprint(v)
+		}
+	}
+	return "<unnamed>"
+}
+
+// LoadManifest loads the manifest from file fp.
+func LoadManifest(fp string) (*Manifest, error) {
+	b, err := os.ReadFile(fp)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest %s err: %w", fp, err)
+	}
+	m := &Manifest{}
+	if err := yaml.UnmarshalStrict(b, m); err != nil {
+		return nil, fmt.Errorf("yaml unmarshal manifest %s err: %w", fp, err)
+	}
+	if m.Version != "" && m.Version != ManifestVersion {
+		return nil, fmt.Errorf("manifest %s: unsupported version %s, only %s is supported", fp, m.Version, ManifestVersion)
+	}
+	if len(m.Entries) == 0 {
+		return nil, fmt.Errorf("manifest %s: no entries", fp)
+	}
+	if m.dir, err = filepath.Abs(filepath.Dir(fp)); err != nil {
+		return nil, fmt.Errorf("get absolute path of %s err: %w", fp, err)
+	}
+	return m, nil
+}
+
+// Select returns the entries with the given names, all entries are returned if names is empty.
+func (m *Manifest) Select(names []string) ([]*Entry, error) {
+	if len(names) == 0 {
+		return m.Entries, nil
+	}
+	var selected []*Entry
+	for _, name := range names {
+		var found bool
+		for _, e := range m.Entries {
+			if e.Name == name {
+				selected = append(selected, e)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("entry %s is not found in the manifest", name)
+		}
+	}
+	return selected, nil
+}
+
+// applyValues sets the flags in fs by values, which are keyed by flag name.
+// The list flags are replaced as a whole.
+func applyValues(fs *pflag.FlagSet, values map[string]interface{}) error {
+	// Apply in a stable order, so that the errors are reproducible.
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		vals, err := stringValues(values[name])
+		if err != nil {
+			return fmt.Errorf("invalid value of %s: %w", name, err)
+		}
+		if fs.Lookup(name) == nil {
+			return fmt.Errorf("unknown key %s, which is not a flag of trpc create", name)
+		}
+		if err := internal.SetFlag(fs, name, vals...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func stringValues(v interface{}) ([]string, error) {
+	switch vv := v.(type) {
+	case nil:
+		return nil, errors.New("empty value")
+	case []interface{}:
+		vals := make([]string, 0, len(vv))
+		for _, e := range vv {
+			switch e.(type) {
+			case []interface{}, map[interface{}]interface{}:
+				return nil, fmt.Errorf("nested value %v is not supported", e)
+			}
+			vals = append(vals, fmt.Sprint(e))
+		}
+		return vals, nil
+	case map[interface{}]interface{}:
+		return nil, fmt.Errorf("map value %v is not supported", vv)
+	default:
+		return []string{fmt.Sprint(vv)}, nil
+	}
+}
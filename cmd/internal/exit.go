@@ -0,0 +1,27 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package internal
+
+import "fmt"
+
+// ExitError asks the command line to exit with the given code without reporting an execution error.
+// It is used by commands whose exit code carries information, e.g. whether anything changed.
+type ExitError struct {
+	Code int
+	Msg  string // Optional message printed before exiting.
+}
+
+// Error implements error.
+func (e *ExitError) Error() string {
+	if e.Msg == "" {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Msg
+}
@@ -0,0 +1,156 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+// Package lockfile provides the generation lock file, which records how the code is generated.
+package lockfile
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Name is the file name of the lock file, which is written into the output directory.
+const Name = "trpc-gen.lock"
+
+// Version is the version of the lock file format.
+const Version = "v1"
+
+// Lock records the inputs, tools, options and outputs of a generation.
+type Lock struct {
+	Version string `json:"version"`
+	// Workdir is the working directory of the generation, relative to the directory of the lock file.
+	// Relative paths inside Flags are relative to Workdir.
+	Workdir string `json:"workdir"`
+	// Flags are the effective flags of `trpc create`, except the ones only affecting where and how to write.
+	Flags  map[string][]string `json:"flags"`
+	Tools  map[string]string   `json:"tools"`  // Tool name -> version.
+	Inputs map[string]string   `json:"inputs"` // IDL file -> sha256 of its content.
+	Files  []*File             `json:"files"`  // Generated files sorted by path.
+}
+
+// File is a generated file.
+type File struct {
+	Path   string `json:"path"` // Slash separated path relative to the directory of the lock file.
+	SHA256 string `json:"sha256"`
+	// Scaffold files are generated only as a starting point and are meant to be edited by hand,
+	// e.g. main.go and the service implementations of a full project.
+	Scaffold bool `json:"scaffold,omitempty"`
+}
+
+// FileMap returns the files keyed by path.
+func (l *Lock) FileMap() map[string]*File {
+	m := make(map[string]*File, len(l.Files))
+	for _, f := range l.Files {
+		m[f.Path] = f
+	}
+	return m
+}
+
+// Load loads the lock file.
+func Load(fp string) (*Lock, error) {
+	b, err := os.ReadFile(fp)
+	if err != nil {
+		return nil, fmt.Errorf("read lock file %s err: %w", fp, err)
+	}
+	l := &Lock{}
+	if err := json.Unmarshal(b, l); err != nil {
+		return nil, fmt.Errorf("json unmarshal lock file %s err: %w", fp, err)
+	}
+	if l.Version != Version {
+		return nil, fmt.Errorf("lock file %s: unsupported version %q, expect %s", fp, l.Version, Version)
+	}
+	return l, nil
+}
+
+// Write writes the lock file, the files are sorted by path so that the output is stable.
+func (l *Lock) Write(fp string) error {
+	sort.Slice(l.Files, func(i, j int) bool {
+		return l.Files[i].Path < l.Files[j].Path
+	})
+	b, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json marshal lock err: %w", err)
+	}
+	if err := os.WriteFile(fp, append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("write lock file %s err: %w", fp, err)
+	}
+	return nil
+}
+
+// Hash returns the hex encoded sha256 of b.
+func Hash(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// HashFile returns the hex encoded sha256 of the file content.
+func HashFile(fp string) (string, error) {
+	b, err := os.ReadFile(fp)
+	if err != nil {
+		return "", err
+	}
+	return Hash(b), nil
+}
+
+// ToolVersion returns the first line printed by `tool --version`, or an empty string if tool is not available.
+func ToolVersion(tool string) string {
+	if _, err := exec.LookPath(tool); err != nil {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, tool, "--version").CombinedOutput()
+	if err != nil {
+		return ""
+	}
+	line, _, _ := bufio.NewReader(bytes.NewReader(out)).ReadLine()
+	return strings.TrimSpace(string(line))
+}
+
+// Find returns the lock files of the given paths, a directory is searched recursively.
+func Find(paths []string) ([]string, error) {
+	var files []string
+	for _, p := range paths {
+		fi, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !fi.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		err = filepath.WalkDir(p, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			if !d.IsDir() && d.Name() == Name {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("find lock files under %s err: %w", p, err)
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,77 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package lockfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLock_WriteLoad(t *testing.T) {
+	dir := t.TempDir()
+	fp := filepath.Join(dir, Name)
+	l := &Lock{
+		Version: Version,
+		Workdir: "..",
+		Flags:   map[string][]string{"protofile": {"helloworld.proto"}, "protodir": {".", "../common"}},
+		Tools:   map[string]string{"trpc-cmdline": "v1.0.0"},
+		Inputs:  map[string]string{"helloworld.proto": Hash([]byte("syntax = \"proto3\";"))},
+		Files: []*File{
+			{Path: "b.go", SHA256: Hash([]byte("b"))},
+			{Path: "a.go", SHA256: Hash([]byte("a")), Scaffold: true},
+		},
+	}
+	require.Nil(t, l.Write(fp))
+	loaded, err := Load(fp)
+	require.Nil(t, err)
+	require.Equal(t, l, loaded)
+	require.Equal(t, "a.go", loaded.Files[0].Path)
+	require.Len(t, loaded.FileMap(), 2)
+
+	require.Nil(t, os.WriteFile(fp, []byte(`{"version": "v0"}`), 0644))
+	_, err = Load(fp)
+	require.NotNil(t, err)
+	_, err = Load(filepath.Join(dir, "not_exist"))
+	require.NotNil(t, err)
+}
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	for _, p := range []string{"a/" + Name, "b/c/" + Name, ".git/" + Name, "d/other"} {
+		fp := filepath.Join(dir, filepath.FromSlash(p))
+		require.Nil(t, os.MkdirAll(filepath.Dir(fp), os.ModePerm))
+		require.Nil(t, os.WriteFile(fp, nil, 0644))
+	}
+	files, err := Find([]string{dir})
+	require.Nil(t, err)
+	require.Equal(t, []string{
+		filepath.Join(dir, "a", Name),
+		filepath.Join(dir, "b", "c", Name),
+	}, files)
+
+	files, err = Find([]string{filepath.Join(dir, "d", "other")})
+	require.Nil(t, err)
+	require.Len(t, files, 1)
+
+	_, err = Find([]string{filepath.Join(dir, "not_exist")})
+	require.NotNil(t, err)
+}
+
+func TestHashFile(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "a")
+	require.Nil(t, os.WriteFile(fp, []byte("a"), 0644))
+	h, err := HashFile(fp)
+	require.Nil(t, err)
+	require.Equal(t, "ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb", h)
+	require.Equal(t, "", ToolVersion("trpc-cmdline-not-exist"))
+}
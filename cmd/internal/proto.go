@@ -0,0 +1,79 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"trpc.group/trpc-go/trpc-cmdline/descriptor"
+	"trpc.group/trpc-go/trpc-cmdline/parser"
+	"trpc.group/trpc-go/trpc-cmdline/util/fs"
+	"trpc.group/trpc-go/trpc-cmdline/util/paths"
+	"trpc.group/trpc-go/trpc-cmdline/util/pb"
+)
+
+// ProtoFlags are the flags locating the proto file, shared by the commands talking to services
+// without generated code, such as call.
+type ProtoFlags struct {
+	Protofile       string
+	Protodirs       []string
+	DescriptorSetIn string
+}
+
+// AddFlags adds the flags into fs, with the same names as the ones of create.
+func (f *ProtoFlags) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVarP(&f.Protofile, "protofile", "p", "", "Specify the pb file defining the service")
+	fs.StringArrayVar(&f.Protodirs, "protodir", []string{"."}, "Specify the search paths for pb import files")
+	fs.StringVar(&f.DescriptorSetIn, "descriptor_set_in", "",
+		"Specify the FileDescriptorSet file, which is used instead of parsing the pb files")
+}
+
+// Load parses the proto file.
+func (f *ProtoFlags) Load() (*descriptor.FileDescriptor, error) {
+	if f.Protofile == "" {
+		return nil, errors.New("--protofile is required")
+	}
+	opts := []parser.Option{
+		parser.WithAliasOn(true),
+		parser.WithRPCOnly(true),
+		parser.WithMultiVersion(true),
+	}
+	if f.DescriptorSetIn != "" {
+		fp, err := fs.LocateFile(f.DescriptorSetIn, nil)
+		if err != nil {
+			return nil, fmt.Errorf("fs locate file %s err: %w", f.DescriptorSetIn, err)
+		}
+		return parser.LoadDescriptorSet(fp, f.Protofile, opts...)
+	}
+
+	p, err := paths.Locate(pb.ProtoTRPC)
+	if err != nil {
+		return nil, fmt.Errorf("paths locate %s failed err: %w", pb.ProtoTRPC, err)
+	}
+	dirs := fs.UniqFilePath(append(append(f.Protodirs, p), paths.ExpandSearch(p)...))
+	target, err := fs.LocateFile(f.Protofile, dirs)
+	if err != nil {
+		return nil, fmt.Errorf("locate file in proto dirs failed err: %w", err)
+	}
+	protofile := strings.TrimPrefix(f.Protofile, "./")
+	if filepath.IsAbs(protofile) {
+		protofile = filepath.Base(target)
+	}
+	fd, err := parser.ParseProtoFile(protofile, append(dirs, filepath.Dir(target)), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s err: %w", f.Protofile, err)
+	}
+	return fd, nil
+}
@@ -0,0 +1,82 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package internal
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Run runs the PreRunE, RunE and PostRunE of cmd in order.
+// It is used to run a sub command, such as create, from inside another command.
+func Run(cmd *cobra.Command, args []string) error {
+	if cmd.PreRunE != nil {
+		if err := cmd.PreRunE(cmd, args); err != nil {
+			return err
+		}
+	}
+	if cmd.RunE != nil {
+		if err := cmd.RunE(cmd, args); err != nil {
+			return err
+		}
+	}
+	if cmd.PostRunE != nil {
+		return cmd.PostRunE(cmd, args)
+	}
+	return nil
+}
+
+// ShareFlags adds the flags of from which are not defined in to, such as the persistent flags of the root command.
+// Names in excludes are skipped.
+func ShareFlags(from, to *pflag.FlagSet, excludes ...string) {
+	from.VisitAll(func(f *pflag.Flag) {
+		if to.Lookup(f.Name) != nil {
+			return
+		}
+		for _, e := range excludes {
+			if f.Name == e {
+				return
+			}
+		}
+		to.AddFlag(f)
+	})
+}
+
+// SetFlag sets the flag in fs, the list flags are replaced as a whole instead of appended.
+func SetFlag(fs *pflag.FlagSet, name string, vals ...string) error {
+	f := fs.Lookup(name)
+	if f == nil {
+		return fmt.Errorf("unknown flag %s", name)
+	}
+	if sv, ok := f.Value.(pflag.SliceValue); ok {
+		if err := sv.Replace(vals); err != nil {
+			return fmt.Errorf("set %s to %v err: %w", name, vals, err)
+		}
+		f.Changed = true
+		return nil
+	}
+	if len(vals) != 1 {
+		return fmt.Errorf("%s expects a single value, got %v", name, vals)
+	}
+	if err := fs.Set(name, vals[0]); err != nil {
+		return fmt.Errorf("set %s to %s err: %w", name, vals[0], err)
+	}
+	return nil
+}
+
+// FlagValues returns the values of the flag, a list flag may have more than one value.
+func FlagValues(f *pflag.Flag) []string {
+	if sv, ok := f.Value.(pflag.SliceValue); ok {
+		return sv.GetSlice()
+	}
+	return []string{f.Value.String()}
+}
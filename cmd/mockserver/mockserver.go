@@ -0,0 +1,137 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+// Package mockserver provides mock-server command.
+package mockserver
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"trpc.group/trpc-go/trpc-cmdline/cmd/internal"
+	"trpc.group/trpc-go/trpc-cmdline/util/log"
+	"trpc.group/trpc-go/trpc-cmdline/util/mock"
+	"trpc.group/trpc-go/trpc-cmdline/util/protocol"
+)
+
+const (
+	protocolTRPC = "trpc"
+	protocolHTTP = "http"
+)
+
+// listen listens on the address, which is replaced in tests.
+var listen = net.Listen
+
+// options are the options of mock-server command.
+type options struct {
+	rules    string
+	addr     string
+	protocol string
+}
+
+// CMD returns mock-server command.
+func CMD() *cobra.Command {
+	var (
+		protoFlags internal.ProtoFlags
+		opts       options
+	)
+	mockCmd := &cobra.Command{
+		Use:   "mock-server",
+		Short: "Serve the services in the pb file with canned or generated responses",
+		Long: `Serve the services in the pb file with canned or generated responses.
+
+The mock server listens with the tRPC protocol, or the HTTP protocol for the RESTful routes defined by the
+trpc.api.http options. The responses are decided by the rules file, and sample responses are generated for
+the requests matching no rules. Every request is logged to stdout.
+
+For example:
+  trpc mock-server -p helloworld.proto --listen 127.0.0.1:8000 --rules rules.yaml
+
+The rules file is like:
+  rules:
+    - method: helloworld.Greeter/SayHello  # Specified the same as 'trpc call'.
+      match:                               # Fields of the request, nested ones are joined by dots.
+        msg: error
+      ret: 10001
+      error_msg: mocked error
+    - method: Greeter/SayHello
+      response:                            # Response in JSON form.
+        msg: hello
+      delay: 100ms
+
+Only the unary methods are served.
+`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.SetPrefix("[mock-server]")
+			fd, err := protoFlags.Load()
+			if err != nil {
+				return err
+			}
+			methods, err := protocol.Methods(fd)
+			if err != nil {
+				return err
+			}
+			var rules []*mock.Rule
+			if opts.rules != "" {
+				if rules, err = mock.LoadRules(opts.rules, methods); err != nil {
+					return err
+				}
+			}
+			return serve(cmd, mock.NewServer(methods, rules, cmd.OutOrStdout()), &opts)
+		},
+	}
+	protoFlags.AddFlags(mockCmd.Flags())
+	mockCmd.Flags().StringVar(&opts.rules, "rules", "", "Rules file in yaml or json deciding the responses")
+	mockCmd.Flags().StringVar(&opts.addr, "listen", "127.0.0.1:8000", "Address to listen on")
+	mockCmd.Flags().StringVar(&opts.protocol, "protocol", protocolTRPC,
+		"Protocol to serve, trpc or http, http serves the RESTful routes only")
+	return mockCmd
+}
+
+// serve serves until the command is interrupted.
+func serve(cmd *cobra.Command, s *mock.Server, opts *options) error {
+	var handler http.Handler
+	switch opts.protocol {
+	case protocolTRPC:
+	case protocolHTTP:
+		h, err := s.HTTPHandler()
+		if err != nil {
+			return err
+		}
+		handler = h
+	default:
+		return fmt.Errorf("unsupported protocol %s, expect %s or %s", opts.protocol, protocolTRPC, protocolHTTP)
+	}
+	ln, err := listen("tcp", opts.addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s err: %w", opts.addr, err)
+	}
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+	log.Info("mock server is serving %s on %s, press Ctrl-C to stop", opts.protocol, ln.Addr())
+	if handler == nil {
+		return s.ServeTRPC(ln)
+	}
+	if err := http.Serve(ln, handler); !errors.Is(err, net.ErrClosed) {
+		return fmt.Errorf("serve http err: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package mockserver
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	trpcpb "trpc.group/trpc/trpc-protocol/pb/go/trpc"
+
+	"trpc.group/trpc-go/trpc-cmdline/util/protocol"
+)
+
+const testProto = "../../testcase/create/9-restful/helloworld.proto"
+
+// start starts mock-server command with args, and returns the address it listens on.
+// The command is stopped by the returned function, which returns the error of the command.
+func start(t *testing.T, args ...string) (string, *bytes.Buffer, func() error) {
+	lns := make(chan net.Listener, 1)
+	listen = func(network, address string) (net.Listener, error) {
+		ln, err := net.Listen(network, address)
+		if err == nil {
+			lns <- ln
+		}
+		return ln, err
+	}
+	t.Cleanup(func() { listen = net.Listen })
+
+	cmd := CMD()
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetArgs(append([]string{"-p", testProto, "--listen", "127.0.0.1:0"}, args...))
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() { errs <- cmd.ExecuteContext(ctx) }()
+	select {
+	case ln := <-lns:
+		return ln.Addr().String(), out, func() error {
+			cancel()
+			return <-errs
+		}
+	case err := <-errs:
+		cancel()
+		return "", out, func() error { return err }
+	}
+}
+
+func TestMockServerCmd(t *testing.T) {
+	rules := filepath.Join(t.TempDir(), "rules.yaml")
+	require.Nil(t, os.WriteFile(rules, []byte(`
+rules:
+  - method: HelloWorldServer/Hello
+    match:
+      name: bob
+    response:
+      errcode: 2
+`), 0644))
+
+	addr, _, stop := start(t, "--rules", rules)
+	conn, err := net.Dial("tcp", addr)
+	require.Nil(t, err)
+	head := &trpcpb.RequestProtocol{Func: []byte("/helloworld.HelloWorldServer/Hello")}
+	require.Nil(t, protocol.WriteRequest(conn, head, []byte{0x0a, 0x03, 'b', 'o', 'b'})) // name: bob
+	rsp, body, err := protocol.ReadResponse(conn)
+	require.Nil(t, err)
+	require.Zero(t, rsp.GetRet())
+	require.Equal(t, []byte{0x08, 0x02}, body) // errcode: 2
+	conn.Close()
+	require.Nil(t, stop())
+
+	addr, out, stop := start(t, "--protocol", "http")
+	r, err := http.Get("http://" + addr + "/hello/bob")
+	require.Nil(t, err)
+	b, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	require.Nil(t, err)
+	require.Regexp(t, `^\{"errcode":[0-9]+\}$`, string(b))
+	require.Nil(t, stop())
+	require.Contains(t, out.String(), `[http] `)
+	require.Contains(t, out.String(), `/helloworld.HelloWorldServer/Hello ret: 0`)
+
+	_, _, stop = start(t, "--protocol", "grpc")
+	require.ErrorContains(t, stop(), "unsupported protocol grpc")
+	_, _, stop = start(t, "--protocol", "http", "-p", "../../testcase/create/1-without-import/helloworld.proto")
+	require.ErrorContains(t, stop(), "no RESTful routes")
+	_, _, stop = start(t, "--rules", filepath.Join(t.TempDir(), "nope.yaml"))
+	require.ErrorContains(t, stop(), "read rules file err")
+}
@@ -0,0 +1,208 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+// Package newcmd provides new command, which is not named new to avoid shadowing the builtin.
+package newcmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"trpc.group/trpc-go/trpc-cmdline/cmd/create"
+	"trpc.group/trpc-go/trpc-cmdline/cmd/internal"
+	"trpc.group/trpc-go/trpc-cmdline/config"
+	"trpc.group/trpc-go/trpc-cmdline/util/log"
+)
+
+// Flags owned by the new command, which are not passed to create.
+const (
+	flagService = "service"
+	flagRPC     = "rpc"
+	flagRESTful = "restful"
+)
+
+// CMD returns new command.
+func CMD() *cobra.Command {
+	newCmd := &cobra.Command{
+		Use:   "new",
+		Short: "Scaffold a service from scratch, including the proto file",
+		Long: `Scaffold a service from scratch, including the proto file.
+
+The proto file of package trpc.<app>.<server> is written to --protofile, which defaults to <server>.proto,
+with a request and a reply message for each RPC, and go_package <domain>/<groupname>/trpcprotocol/<app>/<server>.
+Then the project is created from it the same as 'trpc create', all of whose flags are accepted.
+With --dry-run, the proto file is written into a temporary directory, leaving the working tree untouched.
+
+Each --rpc is the name of the RPC optionally followed by its kind: unary (default), client-stream,
+server-stream or bidi-stream. --restful adds the trpc.api.http options to the unary RPCs,
+and --alias adds the trpc.alias options.
+
+For example:
+  trpc new --app foo --server bar --service Greeter --rpc SayHello --rpc ListThings:server-stream
+`,
+		Args: cobra.NoArgs,
+		RunE: runNew,
+	}
+	newCmd.Flags().String(flagService, "Greeter", "Name of the service")
+	newCmd.Flags().StringArray(flagRPC, []string{"SayHello"},
+		"RPC of the service as name[:kind], kind is one of unary, client-stream, server-stream and bidi-stream, "+
+			"can be specified multiple times")
+	newCmd.Flags().Bool(flagRESTful, false, "Add RESTful options to the unary RPCs")
+	// Flags of create are passed to the create command.
+	create.AddCreateFlags(newCmd)
+	newCmd.MarkFlagRequired("app")
+	newCmd.MarkFlagRequired("server")
+	return newCmd
+}
+
+func runNew(cmd *cobra.Command, _ []string) error {
+	spec, err := loadSpec(cmd.Flags())
+	if err != nil {
+		return err
+	}
+	b, err := spec.Proto()
+	if err != nil {
+		return err
+	}
+	protofile, err := cmd.Flags().GetString("protofile")
+	if err != nil {
+		return fmt.Errorf("flags parse protofile string err: %w", err)
+	}
+	if protofile == "" {
+		protofile = spec.Server + ".proto"
+	}
+	force, err := cmd.Flags().GetBool("force")
+	if err != nil {
+		return fmt.Errorf("flags parse force bool err: %w", err)
+	}
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return fmt.Errorf("flags parse dry-run bool err: %w", err)
+	}
+	target, err := writeProto(protofile, b, force, dryRun)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		defer os.RemoveAll(filepath.Dir(target))
+		log.Info("would write %s", protofile)
+	} else {
+		log.Info("write %s", protofile)
+	}
+	return runCreate(cmd.Flags(), target)
+}
+
+// loadSpec loads the spec of the proto file from the flags.
+func loadSpec(flags *pflag.FlagSet) (*Spec, error) {
+	spec := &Spec{}
+	for _, v := range []struct {
+		name string
+		p    *string
+	}{
+		{"app", &spec.App},
+		{"server", &spec.Server},
+		{flagService, &spec.Service},
+	} {
+		s, err := flags.GetString(v.name)
+		if err != nil {
+			return nil, fmt.Errorf("flags parse %s string err: %w", v.name, err)
+		}
+		*v.p = s
+	}
+	rpcs, err := flags.GetStringArray(flagRPC)
+	if err != nil {
+		return nil, fmt.Errorf("flags parse %s string array err: %w", flagRPC, err)
+	}
+	for _, s := range rpcs {
+		r, err := parseRPC(s)
+		if err != nil {
+			return nil, err
+		}
+		spec.RPCs = append(spec.RPCs, r)
+	}
+	if spec.RESTful, err = flags.GetBool(flagRESTful); err != nil {
+		return nil, fmt.Errorf("flags parse %s bool err: %w", flagRESTful, err)
+	}
+	if spec.Alias, err = flags.GetBool("alias"); err != nil {
+		return nil, fmt.Errorf("flags parse alias bool err: %w", err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	domain, err := flags.GetString("domain")
+	if err != nil {
+		return nil, fmt.Errorf("flags parse domain string err: %w", err)
+	}
+	if domain == "" {
+		domain = config.GlobalConfig().Domain
+	}
+	group, err := flags.GetString("groupname")
+	if err != nil {
+		return nil, fmt.Errorf("flags parse groupname string err: %w", err)
+	}
+	spec.GoPackage = path.Join(domain, group, "trpcprotocol", spec.App, spec.Server)
+	return spec, nil
+}
+
+// writeProto writes the proto file, which is not overwritten unless force is set, and returns its path.
+// In dry-run mode, the proto file is written into a new temporary directory instead to leave the working tree
+// untouched, which should be removed by the caller.
+func writeProto(protofile string, b []byte, force, dryRun bool) (string, error) {
+	_, err := os.Stat(protofile)
+	if err == nil && !force {
+		return "", fmt.Errorf("%s already exists, remove it or provide -f to force overwrite", protofile)
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("stat %s err: %w", protofile, err)
+	}
+	if dryRun {
+		tmp, err := os.MkdirTemp("", "trpc-new-*")
+		if err != nil {
+			return "", fmt.Errorf("create temporary directory err: %w", err)
+		}
+		// The absolute path is passed to create, which refers to the proto file by its base name by default.
+		protofile = filepath.Join(tmp, filepath.Base(protofile))
+	}
+	if err := os.MkdirAll(filepath.Dir(protofile), os.ModePerm); err != nil {
+		return "", fmt.Errorf("create directory of %s err: %w", protofile, err)
+	}
+	if err := os.WriteFile(protofile, b, 0644); err != nil {
+		return "", fmt.Errorf("write %s err: %w", protofile, err)
+	}
+	return protofile, nil
+}
+
+// runCreate runs the create command for the proto file, with the flags of create given to new.
+func runCreate(overrides *pflag.FlagSet, protofile string) error {
+	createCmd := create.CMD()
+	flags := createCmd.Flags()
+	// Persistent flags of the root command, such as --verbose, are shared.
+	internal.ShareFlags(overrides, flags, flagService, flagRPC, flagRESTful)
+	var err error
+	overrides.Visit(func(f *pflag.Flag) {
+		if err != nil || f.Name == flagService || f.Name == flagRPC || f.Name == flagRESTful {
+			return
+		}
+		err = internal.SetFlag(flags, f.Name, internal.FlagValues(f)...)
+	})
+	if err != nil {
+		return fmt.Errorf("apply command line flags err: %w", err)
+	}
+	if err := internal.SetFlag(flags, "protofile", protofile); err != nil {
+		return err
+	}
+	return internal.Run(createCmd, nil)
+}
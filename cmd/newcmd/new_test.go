@@ -0,0 +1,141 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package newcmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-cmdline/config"
+	"trpc.group/trpc-go/trpc-cmdline/parser"
+)
+
+const trpcProtos = "../../install/submodules/trpc-protocol"
+
+func TestProto(t *testing.T) {
+	cmd := CMD()
+	require.Nil(t, cmd.Flags().Parse([]string{
+		"--app", "foo", "--server", "bar", "--domain", "example.com", "--groupname", "team",
+		"--rpc", "SayHello", "--rpc", "list_things:server-stream", "--rpc", "Upload:client-stream",
+		"--restful", "--alias",
+	}))
+	spec, err := loadSpec(cmd.Flags())
+	require.Nil(t, err)
+	require.Equal(t, "example.com/team/trpcprotocol/foo/bar", spec.GoPackage)
+	b, err := spec.Proto()
+	require.Nil(t, err)
+
+	dir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "bar.proto"), b, 0644))
+	fd, err := parser.Parse("bar.proto", []string{dir, trpcProtos}, config.IDLTypeProtobuf,
+		parser.WithAliasOn(true), parser.WithAliasAsClientRPCName(true))
+	require.Nil(t, err)
+	require.Equal(t, "trpc.foo.bar", fd.PackageName)
+	require.Equal(t, "example.com/team/trpcprotocol/foo/bar", fd.FileOptions["go_package"])
+	require.Len(t, fd.Services, 1)
+	sd := fd.Services[0]
+	require.Equal(t, "Greeter", sd.Name)
+	require.Len(t, sd.RPC, 3)
+
+	hello, list, upload := sd.RPC[0], sd.RPC[1], sd.RPC[2]
+	require.Equal(t, "trpc.foo.bar.SayHelloRequest", hello.RequestType)
+	require.Equal(t, "trpc.foo.bar.SayHelloReply", hello.ResponseType)
+	require.Equal(t, "/v1/say_hello", hello.FullyQualifiedCmd)
+	require.Len(t, hello.RESTfulAPIInfo.ContentList, 1)
+	require.Equal(t, "POST", hello.RESTfulAPIInfo.ContentList[0].Method)
+
+	require.Equal(t, "ListThings", list.Name)
+	require.True(t, list.ServerStreaming)
+	require.False(t, list.ClientStreaming)
+	require.Empty(t, list.RESTfulAPIInfo.ContentList, "streaming rpcs are not restful")
+	require.True(t, upload.ClientStreaming)
+	require.False(t, upload.ServerStreaming)
+}
+
+func TestProtoPlain(t *testing.T) {
+	spec := &Spec{App: "foo", Server: "bar", Service: "Greeter", GoPackage: "trpc.group/trpc-go/trpcprotocol/foo/bar",
+		RPCs: []*RPC{{Name: "SayHello"}}}
+	require.Nil(t, spec.Validate())
+	b, err := spec.Proto()
+	require.Nil(t, err)
+	require.Equal(t, `syntax = "proto3";
+
+package trpc.foo.bar;
+
+option go_package = "trpc.group/trpc-go/trpcprotocol/foo/bar";
+
+// Greeter is the service of foo.bar.
+service Greeter {
+  rpc SayHello(SayHelloRequest) returns (SayHelloReply);
+}
+
+// SayHelloRequest is the request of SayHello.
+message SayHelloRequest {
+  string msg = 1;
+}
+
+// SayHelloReply is the reply of SayHello.
+message SayHelloReply {
+  string msg = 1;
+}
+`, string(b))
+}
+
+func TestSpecErr(t *testing.T) {
+	_, err := parseRPC("Chat:stream")
+	require.EqualError(t, err, `invalid kind "stream" of rpc Chat, `+
+		`supported kinds: unary, client-stream, server-stream, bidi-stream`)
+	_, err = parseRPC("1st")
+	require.EqualError(t, err, `invalid rpc name "1st"`)
+
+	for _, tt := range []struct {
+		spec *Spec
+		err  string
+	}{
+		{&Spec{App: "Foo", Server: "bar", Service: "Greeter"}, `invalid app name "Foo"`},
+		{&Spec{App: "foo", Server: "bar.baz", Service: "Greeter"}, `invalid server name "bar.baz"`},
+		{&Spec{App: "foo", Server: "bar", Service: "Greeter"}, "service Greeter has no rpc"},
+		{&Spec{App: "foo", Server: "bar", Service: "Greeter", RPCs: []*RPC{{Name: "A"}, {Name: "A"}}}, "duplicate rpc A"},
+	} {
+		require.ErrorContains(t, tt.spec.Validate(), tt.err)
+	}
+}
+
+func TestWriteProto(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "protos", "bar.proto")
+	target, err := writeProto(fp, []byte("a"), false, false)
+	require.Nil(t, err)
+	require.Equal(t, fp, target)
+	_, err = writeProto(fp, []byte("b"), false, false)
+	require.ErrorContains(t, err, "already exists")
+	_, err = writeProto(fp, []byte("b"), true, false)
+	require.Nil(t, err)
+	b, err := os.ReadFile(fp)
+	require.Nil(t, err)
+	require.Equal(t, "b", string(b))
+
+	// The proto file is written into a temporary directory in dry-run mode.
+	_, err = writeProto(fp, []byte("c"), false, true)
+	require.ErrorContains(t, err, "already exists")
+	dryRun := filepath.Join(filepath.Dir(fp), "baz.proto")
+	target, err = writeProto(dryRun, []byte("c"), false, true)
+	require.Nil(t, err)
+	defer os.RemoveAll(filepath.Dir(target))
+	require.NotEqual(t, dryRun, target)
+	require.Equal(t, "baz.proto", filepath.Base(target))
+	b, err = os.ReadFile(target)
+	require.Nil(t, err)
+	require.Equal(t, "c", string(b))
+	_, err = os.Stat(dryRun)
+	require.True(t, os.IsNotExist(err), "the working tree is left untouched")
+}
@@ -0,0 +1,181 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package newcmd
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/iancoleman/strcase"
+)
+
+// Kinds of RPCs, which follow the RPC names of --rpc after a colon, e.g. ListThings:server-stream.
+const (
+	kindUnary        = "unary"
+	kindClientStream = "client-stream"
+	kindServerStream = "server-stream"
+	kindBidiStream   = "bidi-stream"
+)
+
+var (
+	// packageName matches the app and server names, which are parts of the proto package.
+	packageName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	// identifier matches the service and RPC names.
+	identifier = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+)
+
+// Spec describes the proto file to scaffold.
+type Spec struct {
+	App       string
+	Server    string
+	Service   string
+	GoPackage string // Value of option go_package, such as trpc.group/trpc-go/trpcprotocol/foo/bar.
+	RPCs      []*RPC
+	RESTful   bool // Whether to add the trpc.api.http options to the unary RPCs.
+	Alias     bool // Whether to add the trpc.alias options to the RPCs.
+}
+
+// RPC is a method of the service.
+type RPC struct {
+	Name            string
+	ClientStreaming bool
+	ServerStreaming bool
+}
+
+// Path is the HTTP path and the alias of the RPC, such as /v1/say_hello.
+func (r *RPC) Path() string {
+	return "/v1/" + strcase.ToSnake(r.Name)
+}
+
+// Unary reports whether the RPC is not streaming.
+func (r *RPC) Unary() bool {
+	return !r.ClientStreaming && !r.ServerStreaming
+}
+
+// parseRPC parses the value of --rpc, which is the name optionally followed by the kind, e.g. ListThings:server-stream.
+func parseRPC(s string) (*RPC, error) {
+	name, kind := s, kindUnary
+	if i := strings.Index(s, ":"); i >= 0 {
+		name, kind = s[:i], s[i+1:]
+	}
+	if !identifier.MatchString(name) {
+		return nil, fmt.Errorf("invalid rpc name %q", name)
+	}
+	r := &RPC{Name: strcase.ToCamel(name)}
+	switch kind {
+	case kindUnary:
+	case kindClientStream:
+		r.ClientStreaming = true
+	case kindServerStream:
+		r.ServerStreaming = true
+	case kindBidiStream:
+		r.ClientStreaming, r.ServerStreaming = true, true
+	default:
+		return nil, fmt.Errorf("invalid kind %q of rpc %s, supported kinds: %s, %s, %s, %s",
+			kind, name, kindUnary, kindClientStream, kindServerStream, kindBidiStream)
+	}
+	return r, nil
+}
+
+// Validate checks the names of the spec.
+func (s *Spec) Validate() error {
+	for _, v := range []struct{ flag, name string }{{"app", s.App}, {"server", s.Server}} {
+		if !packageName.MatchString(v.name) {
+			return fmt.Errorf("invalid %s name %q, which should be lower case letters, digits and underscores",
+				v.flag, v.name)
+		}
+	}
+	if !identifier.MatchString(s.Service) {
+		return fmt.Errorf("invalid service name %q", s.Service)
+	}
+	if len(s.RPCs) == 0 {
+		return fmt.Errorf("service %s has no rpc", s.Service)
+	}
+	seen := make(map[string]bool)
+	for _, r := range s.RPCs {
+		if seen[r.Name] {
+			return fmt.Errorf("duplicate rpc %s", r.Name)
+		}
+		seen[r.Name] = true
+	}
+	return nil
+}
+
+// Proto renders the proto file.
+func (s *Spec) Proto() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := protoTemplate.Execute(buf, s); err != nil {
+		return nil, fmt.Errorf("render proto of %s.%s err: %w", s.App, s.Server, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// HasRESTful reports whether any RPC has the trpc.api.http option.
+func (s *Spec) HasRESTful() bool {
+	if !s.RESTful {
+		return false
+	}
+	for _, r := range s.RPCs {
+		if r.Unary() {
+			return true
+		}
+	}
+	return false
+}
+
+var protoTemplate = template.Must(template.New("proto").Parse(`syntax = "proto3";
+
+package trpc.{{ .App }}.{{ .Server }};
+
+{{ if .HasRESTful -}}
+import "trpc/api/annotations.proto";
+{{ end -}}
+{{ if .Alias -}}
+import "trpc/proto/trpc_options.proto";
+{{ end -}}
+{{ if or .HasRESTful .Alias }}
+{{ end -}}
+option go_package = "{{ .GoPackage }}";
+
+// {{ .Service }} is the service of {{ .App }}.{{ .Server }}.
+service {{ .Service }} {
+{{- range .RPCs }}
+{{- $restful := and $.RESTful .Unary }}
+  rpc {{ .Name }}({{ if .ClientStreaming }}stream {{ end }}{{ .Name }}Request) returns ({{ if .ServerStreaming }}stream {{ end }}{{ .Name }}Reply)
+{{- if or $restful $.Alias }} {
+{{- if $.Alias }}
+    option (trpc.alias) = "{{ .Path }}";
+{{- end }}
+{{- if $restful }}
+    option (trpc.api.http) = {
+      post: "{{ .Path }}"
+      body: "*"
+    };
+{{- end }}
+  }
+{{- else }};
+{{- end }}
+{{- end }}
+}
+{{ range .RPCs }}
+// {{ .Name }}Request is the request of {{ .Name }}.
+message {{ .Name }}Request {
+  string msg = 1;
+}
+
+// {{ .Name }}Reply is the reply of {{ .Name }}.
+message {{ .Name }}Reply {
+  string msg = 1;
+}
+{{ end -}}
+`))
@@ -11,6 +11,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -19,9 +20,18 @@ import (
 	"github.com/spf13/viper"
 
 	"trpc.group/trpc-go/trpc-cmdline/cmd/apidocs"
+	"trpc.group/trpc-go/trpc-cmdline/cmd/call"
 	"trpc.group/trpc-go/trpc-cmdline/cmd/completion"
 	"trpc.group/trpc-go/trpc-cmdline/cmd/create"
+	"trpc.group/trpc-go/trpc-cmdline/cmd/generate"
+	"trpc.group/trpc-go/trpc-cmdline/cmd/internal"
+	"trpc.group/trpc-go/trpc-cmdline/cmd/mockserver"
+	"trpc.group/trpc-go/trpc-cmdline/cmd/newcmd"
+	"trpc.group/trpc-go/trpc-cmdline/cmd/sample"
 	"trpc.group/trpc-go/trpc-cmdline/cmd/setup"
+	"trpc.group/trpc-go/trpc-cmdline/cmd/stream"
+	"trpc.group/trpc-go/trpc-cmdline/cmd/template"
+	"trpc.group/trpc-go/trpc-cmdline/cmd/verify"
 	"trpc.group/trpc-go/trpc-cmdline/cmd/version"
 	"trpc.group/trpc-go/trpc-cmdline/config"
 	"trpc.group/trpc-go/trpc-cmdline/util/log"
@@ -31,6 +41,13 @@ import (
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
+		var exitErr *internal.ExitError
+		if errors.As(err, &exitErr) {
+			if exitErr.Msg != "" {
+				fmt.Println(exitErr.Msg)
+			}
+			os.Exit(exitErr.Code)
+		}
 		fmt.Printf(`Execution err:
 	%+v
 Please run "trpc -h" or "trpc create -h" (or "trpc {some-other-subcommand} -h") for help messages.
@@ -74,6 +91,14 @@ func init() {
 	rootCmd.PersistentFlags().BoolVarP(&verboseFlag, "verbose", "v", false, "Display detailed log information")
 
 	rootCmd.AddCommand(create.CMD())
+	rootCmd.AddCommand(newcmd.CMD())
+	rootCmd.AddCommand(generate.CMD())
+	rootCmd.AddCommand(verify.CMD())
+	rootCmd.AddCommand(template.CMD())
+	rootCmd.AddCommand(call.CMD())
+	rootCmd.AddCommand(stream.CMD())
+	rootCmd.AddCommand(mockserver.CMD())
+	rootCmd.AddCommand(sample.CMD())
 	rootCmd.AddCommand(setup.CMD())
 	rootCmd.AddCommand(completion.CMD())
 	rootCmd.AddCommand(apidocs.CMD())
@@ -0,0 +1,104 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+// Package sample provides sample command.
+package sample
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/spf13/cobra"
+
+	"trpc.group/trpc-go/trpc-cmdline/cmd/internal"
+	"trpc.group/trpc-go/trpc-cmdline/descriptor"
+	"trpc.group/trpc-go/trpc-cmdline/util/protocol"
+	"trpc.group/trpc-go/trpc-cmdline/util/sample"
+)
+
+// options are the options of sample command.
+type options struct {
+	seed     int64
+	response bool
+	compact  bool
+}
+
+// CMD returns sample command.
+func CMD() *cobra.Command {
+	var (
+		protoFlags internal.ProtoFlags
+		opts       options
+	)
+	sampleCmd := &cobra.Command{
+		Use:   "sample <message|method>",
+		Short: "Generate a sample payload of the message in JSON",
+		Long: `Generate a sample payload of the message in JSON.
+
+The fields are filled with realistic values guessed by their names and types, within the constraints
+of the validate.proto options. The same seed generates the same payload.
+
+The argument is a message, specified by the fully qualified name or the name relative to its package,
+or a method specified the same as 'trpc call', whose request is generated.
+
+For example:
+  trpc sample -p helloworld.proto helloworld.HelloRequest
+  trpc sample -p helloworld.proto Greeter/SayHello --response --seed 2
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fd, err := protoFlags.Load()
+			if err != nil {
+				return err
+			}
+			md, err := findMessage(fd, args[0], opts.response)
+			if err != nil {
+				return err
+			}
+			indent := "  "
+			if opts.compact {
+				indent = ""
+			}
+			b, err := sample.New(opts.seed).JSON(md, indent)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(b))
+			return nil
+		},
+	}
+	protoFlags.AddFlags(sampleCmd.Flags())
+	sampleCmd.Flags().Int64Var(&opts.seed, "seed", sample.DefaultSeed, "Seed of the random values")
+	sampleCmd.Flags().BoolVar(&opts.response, "response", false, "Generate the response instead of the request of the method")
+	sampleCmd.Flags().BoolVar(&opts.compact, "compact", false, "Print the JSON without indent")
+	return sampleCmd
+}
+
+// findMessage finds the message by name, or the request or response of the method if name contains a slash.
+func findMessage(fd *descriptor.FileDescriptor, name string, response bool) (*desc.MessageDescriptor, error) {
+	if strings.Contains(name, "/") {
+		m, err := protocol.FindMethod(fd, name)
+		if err != nil {
+			return nil, err
+		}
+		if response {
+			return m.Output, nil
+		}
+		return m.Input, nil
+	}
+	if response {
+		return nil, errors.New("--response is only supported for methods")
+	}
+	pfd, ok := fd.FD.(*descriptor.ProtoFileDescriptor)
+	if !ok {
+		return nil, errors.New("only protobuf is supported")
+	}
+	return sample.FindMessage(pfd.FD, name)
+}
@@ -0,0 +1,58 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package sample
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testProto = "../../testcase/create/9-restful/helloworld.proto"
+
+func run(args ...string) (string, error) {
+	cmd := CMD()
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetArgs(append([]string{"-p", testProto}, args...))
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestSampleCmd(t *testing.T) {
+	out, err := run("helloworld.HelloReq")
+	require.Nil(t, err)
+	require.Contains(t, out, "\n  \"name\": ")
+	obj := make(map[string]interface{})
+	require.Nil(t, json.Unmarshal([]byte(out), &obj))
+	require.Contains(t, obj, "id")
+
+	again, err := run("HelloWorldServer/Hello", "--compact")
+	require.Nil(t, err)
+	require.Equal(t, 1, strings.Count(again, "\n"))
+	require.JSONEq(t, out, again, "the request of the method with the same seed")
+
+	other, err := run("HelloReq", "--seed", "2")
+	require.Nil(t, err)
+	require.NotEqual(t, out, other)
+
+	out, err = run("HelloWorldServer/Hello", "--response", "--compact")
+	require.Nil(t, err)
+	require.Contains(t, out, `"errcode":`)
+
+	_, err = run("HelloReq", "--response")
+	require.ErrorContains(t, err, "--response is only supported for methods")
+	_, err = run("Nope")
+	require.ErrorContains(t, err, "message Nope not found")
+}
@@ -0,0 +1,218 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+// Package stream provides stream command.
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/spf13/cobra"
+	trpcpb "trpc.group/trpc/trpc-protocol/pb/go/trpc"
+
+	"trpc.group/trpc-go/trpc-cmdline/cmd/internal"
+	"trpc.group/trpc-go/trpc-cmdline/util/log"
+	"trpc.group/trpc-go/trpc-cmdline/util/protocol"
+)
+
+const (
+	// defaultCaller is the caller sent in the init frame if --caller is not specified.
+	defaultCaller = "trpc.cmdline.stream"
+	// streamID is the id of the only stream on the connection.
+	streamID = 1
+	// maxLineSize is the max size of a request line read from stdin.
+	maxLineSize = protocol.MaxFrameSize
+)
+
+// options are the options of stream command.
+type options struct {
+	target  string
+	timeout time.Duration
+	caller  string
+	callee  string
+	meta    []string
+	window  uint32
+}
+
+// CMD returns stream command.
+func CMD() *cobra.Command {
+	var (
+		protoFlags internal.ProtoFlags
+		opts       options
+	)
+	streamCmd := &cobra.Command{
+		Use:   "stream <method>",
+		Short: "Open a stream to the target service for a streaming RPC without generated code",
+		Long: `Open a stream to the target service for a streaming RPC without generated code.
+
+The requests are read from stdin as newline-delimited JSON, and sent by the tRPC stream frame protocol.
+Each response is printed as a line of JSON as soon as it arrives.
+The stream is closed for sending when stdin reaches EOF, press Ctrl-D to end the input interactively.
+Server streaming methods take only the first request.
+
+For example:
+  trpc stream -p helloworld.proto --target ip://127.0.0.1:8000 trpc.app.server.Greeter/SayHelloStream <<EOF
+  {"msg":"hi"}
+  {"msg":"there"}
+  EOF
+
+The method is specified the same as 'trpc call'.
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.SetPrefix("[stream]")
+			fd, err := protoFlags.Load()
+			if err != nil {
+				return err
+			}
+			m, err := protocol.FindMethod(fd, args[0])
+			if err != nil {
+				return err
+			}
+			if !m.RPC.ClientStreaming && !m.RPC.ServerStreaming {
+				return fmt.Errorf("%s is not a streaming method, use 'trpc call' instead", m.Func)
+			}
+			return stream(cmd.InOrStdin(), cmd.OutOrStdout(), m, &opts)
+		},
+	}
+	protoFlags.AddFlags(streamCmd.Flags())
+	streamCmd.Flags().StringVar(&opts.target, "target", "", "Address of the target service, e.g. ip://127.0.0.1:8000")
+	streamCmd.Flags().DurationVar(&opts.timeout, "timeout", 5*time.Second,
+		"Timeout of connecting and initializing the stream, the stream itself never times out")
+	streamCmd.Flags().StringVar(&opts.caller, "caller", defaultCaller, "Caller name sent in the init frame")
+	streamCmd.Flags().StringVar(&opts.callee, "callee", "",
+		"Callee name sent in the init frame, defaults to the fully qualified service name")
+	streamCmd.Flags().StringArrayVar(&opts.meta, "meta", nil,
+		"Metadata sent in the trans_info of the init frame, in the form of key=value, can be specified multiple times")
+	streamCmd.Flags().Uint32Var(&opts.window, "window", protocol.DefaultWindowSize,
+		"Initial window size in bytes advertised to the server for flow control")
+	_ = streamCmd.MarkFlagRequired("target")
+	return streamCmd
+}
+
+func stream(in io.Reader, out io.Writer, m *protocol.Method, opts *options) error {
+	meta, err := newInitMeta(m, opts)
+	if err != nil {
+		return err
+	}
+	network, address, err := protocol.ParseTarget(opts.target)
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialTimeout(network, address, opts.timeout)
+	if err != nil {
+		return fmt.Errorf("dial %s err: %w", opts.target, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(opts.timeout)); err != nil {
+		return fmt.Errorf("set deadline err: %w", err)
+	}
+	s, _, err := protocol.NewClientStream(conn, streamID, meta, opts.window)
+	if err != nil {
+		return fmt.Errorf("init stream of %s err: %w", m.Func, err)
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return fmt.Errorf("clear deadline err: %w", err)
+	}
+	log.Debug("stream of %s is open", m.Func)
+
+	sendErr := make(chan error, 1)
+	go func() {
+		err := send(in, s, m)
+		sendErr <- err
+		if err != nil {
+			conn.Close() // Stops the receiving.
+		}
+	}()
+	for {
+		body, err := s.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			select {
+			case serr := <-sendErr:
+				if serr != nil {
+					return serr
+				}
+			default:
+			}
+			return err
+		}
+		rsp, err := m.NewOutput(nil)
+		if err != nil {
+			return err
+		}
+		if err := rsp.Unmarshal(body); err != nil {
+			return fmt.Errorf("unmarshal response into %s err: %w", m.Output.GetFullyQualifiedName(), err)
+		}
+		b, err := protocol.MarshalJSON(rsp)
+		if err != nil {
+			return fmt.Errorf("marshal response into json err: %w", err)
+		}
+		fmt.Fprintln(out, string(b))
+	}
+}
+
+// send sends the requests read from in line by line, and closes the sending at EOF.
+func send(in io.Reader, s *protocol.ClientStream, m *protocol.Method) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, maxLineSize)
+	var n int
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		n++
+		req, err := m.NewInput(line)
+		if err != nil {
+			return fmt.Errorf("request %d: %w", n, err)
+		}
+		body, err := req.Marshal()
+		if err != nil {
+			return fmt.Errorf("marshal request %d err: %w", n, err)
+		}
+		if err := s.Send(body); err != nil {
+			return fmt.Errorf("send request %d err: %w", n, err)
+		}
+		if !m.RPC.ClientStreaming {
+			break // Server streaming methods take only one request.
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read requests err: %w", err)
+	}
+	if err := s.CloseSend(); err != nil {
+		return fmt.Errorf("close sending err: %w", err)
+	}
+	return nil
+}
+
+func newInitMeta(m *protocol.Method, opts *options) (*trpcpb.TrpcStreamInitRequestMeta, error) {
+	callee := opts.callee
+	if callee == "" {
+		callee = m.Service
+	}
+	transInfo, err := protocol.ParseTransInfo(opts.meta)
+	if err != nil {
+		return nil, err
+	}
+	return &trpcpb.TrpcStreamInitRequestMeta{
+		Caller:    []byte(opts.caller),
+		Callee:    []byte(callee),
+		Func:      []byte(m.Func),
+		TransInfo: transInfo,
+	}, nil
+}
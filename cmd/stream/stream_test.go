@@ -0,0 +1,265 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package stream
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	trpcpb "trpc.group/trpc/trpc-protocol/pb/go/trpc"
+
+	"trpc.group/trpc-go/trpc-cmdline/cmd/internal"
+	"trpc.group/trpc-go/trpc-cmdline/util/protocol"
+)
+
+const (
+	testProto = "../../testcase/create/1-without-import/helloworld.proto"
+	// serverWindow is small so that the client has to wait for the feedback of the server.
+	serverWindow = 8
+	// serverStreamCount is the number of responses of the server streaming method.
+	serverStreamCount = 50
+)
+
+// streamServer is a stand-in of the streaming services in testProto.
+type streamServer struct {
+	t         *testing.T
+	conn      net.Conn
+	methods   map[string]*protocol.Method
+	window    int64 // Window to send DATA frames.
+	feedbacks int   // Number of FEEDBACK frames received.
+	closed    bool  // Whether the client has closed the sending.
+}
+
+func serve(t *testing.T, ln net.Listener, methods map[string]*protocol.Method, feedbacks chan<- int) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		s := &streamServer{t: t, conn: conn, methods: methods}
+		assert.Nil(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+		if err := s.serve(); err != io.EOF {
+			assert.Nil(t, err) // The client may go away on errors.
+		}
+		conn.Close()
+		feedbacks <- s.feedbacks
+	}
+}
+
+func (s *streamServer) serve() error {
+	f, err := protocol.ReadFrame(s.conn)
+	if err != nil {
+		return err
+	}
+	init := &trpcpb.TrpcStreamInitMeta{}
+	if err := proto.Unmarshal(f.Header, init); err != nil {
+		return err
+	}
+	s.window = int64(init.GetInitWindowSize())
+	rsp := &trpcpb.TrpcStreamInitMeta{ResponseMeta: &trpcpb.TrpcStreamInitResponseMeta{}, InitWindowSize: serverWindow}
+	m, ok := s.methods[string(init.GetRequestMeta().GetFunc())]
+	if !ok || string(init.GetRequestMeta().GetCallee()) == "fail" {
+		rsp.ResponseMeta.Ret = int32(trpcpb.TrpcRetCode_TRPC_SERVER_NOFUNC_ERR)
+		rsp.ResponseMeta.ErrorMsg = []byte("no such func")
+		return s.write(trpcpb.TrpcStreamFrameType_TRPC_STREAM_FRAME_INIT, rsp, nil)
+	}
+	if err := s.write(trpcpb.TrpcStreamFrameType_TRPC_STREAM_FRAME_INIT, rsp, nil); err != nil {
+		return err
+	}
+
+	var msgs []string
+	for !s.closed {
+		msg, ok, err := s.recv(m)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		switch {
+		case m.RPC.ClientStreaming && m.RPC.ServerStreaming:
+			if err := s.send(m, "hello "+msg); err != nil {
+				return err
+			}
+		case m.RPC.ClientStreaming:
+			msgs = append(msgs, msg)
+		default:
+			for i := 0; i < serverStreamCount; i++ {
+				if err := s.send(m, fmt.Sprintf("hello %s %d", msg, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if m.RPC.ClientStreaming && !m.RPC.ServerStreaming {
+		if err := s.send(m, "hello "+strings.Join(msgs, " ")); err != nil {
+			return err
+		}
+	}
+	closeMeta := &trpcpb.TrpcStreamCloseMeta{}
+	if len(msgs) != 0 && msgs[0] == "fail" {
+		closeMeta.Ret = int32(trpcpb.TrpcRetCode_TRPC_STREAM_SERVER_DECODE_ERR)
+		closeMeta.Msg = []byte("bad request")
+	}
+	return s.write(trpcpb.TrpcStreamFrameType_TRPC_STREAM_FRAME_CLOSE, closeMeta, nil)
+}
+
+// recv receives the msg of the next request, ok is false when the client closes the sending.
+func (s *streamServer) recv(m *protocol.Method) (msg string, ok bool, err error) {
+	for {
+		f, err := s.next()
+		if err != nil || f == nil {
+			return "", false, err
+		}
+		if f.StreamFrameType != trpcpb.TrpcStreamFrameType_TRPC_STREAM_FRAME_DATA {
+			continue
+		}
+		req := dynamic.NewMessage(m.Input)
+		if err := req.Unmarshal(f.Body); err != nil {
+			return "", false, err
+		}
+		// Feedback at once, so that the small window is never used up.
+		feedback := &trpcpb.TrpcStreamFeedBackMeta{WindowSizeIncrement: uint32(len(f.Body))}
+		if err := s.write(trpcpb.TrpcStreamFrameType_TRPC_STREAM_FRAME_FEEDBACK, feedback, nil); err != nil {
+			return "", false, err
+		}
+		return req.GetFieldByName("msg").(string), true, nil
+	}
+}
+
+// send sends a response, which waits for the feedback of the client when the window is used up.
+func (s *streamServer) send(m *protocol.Method, msg string) error {
+	for s.window <= 0 {
+		if _, err := s.next(); err != nil {
+			return err
+		}
+	}
+	rsp := dynamic.NewMessage(m.Output)
+	rsp.SetFieldByName("msg", msg)
+	b, err := rsp.Marshal()
+	if err != nil {
+		return err
+	}
+	s.window -= int64(len(b))
+	return s.write(trpcpb.TrpcStreamFrameType_TRPC_STREAM_FRAME_DATA, nil, b)
+}
+
+// next reads the next frame, the FEEDBACK and CLOSE frames are handled, nil is returned for CLOSE.
+// The client may still send FEEDBACK frames after CLOSE.
+func (s *streamServer) next() (*protocol.Frame, error) {
+	f, err := protocol.ReadFrame(s.conn)
+	if err != nil {
+		return nil, err
+	}
+	switch f.StreamFrameType {
+	case trpcpb.TrpcStreamFrameType_TRPC_STREAM_FRAME_FEEDBACK:
+		meta := &trpcpb.TrpcStreamFeedBackMeta{}
+		if err := proto.Unmarshal(f.Header, meta); err != nil {
+			return nil, err
+		}
+		s.window += int64(meta.GetWindowSizeIncrement())
+		s.feedbacks++
+	case trpcpb.TrpcStreamFrameType_TRPC_STREAM_FRAME_CLOSE:
+		s.closed = true
+		return nil, nil
+	}
+	return f, nil
+}
+
+func (s *streamServer) write(typ trpcpb.TrpcStreamFrameType, meta proto.Message, body []byte) error {
+	var header []byte
+	if meta != nil {
+		b, err := proto.Marshal(meta)
+		if err != nil {
+			return err
+		}
+		header = b
+	}
+	return protocol.WriteFrame(s.conn, &protocol.Frame{
+		FrameHead: protocol.FrameHead{
+			FrameType:       trpcpb.TrpcDataFrameType_TRPC_STREAM_FRAME,
+			StreamFrameType: typ,
+			StreamID:        streamID,
+		},
+		Header: header,
+		Body:   body,
+	})
+}
+
+func TestStreamCmd(t *testing.T) {
+	protoFlags := internal.ProtoFlags{Protofile: testProto, Protodirs: []string{"."}}
+	fd, err := protoFlags.Load()
+	require.Nil(t, err)
+	ms, err := protocol.Methods(fd)
+	require.Nil(t, err)
+	methods := make(map[string]*protocol.Method)
+	for _, m := range ms {
+		methods[m.Func] = m
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer ln.Close()
+	feedbacks := make(chan int, 1)
+	go serve(t, ln, methods, feedbacks)
+
+	run := func(stdin string, args ...string) (string, error) {
+		cmd := CMD()
+		cmd.SilenceUsage, cmd.SilenceErrors = true, true
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetIn(bytes.NewBufferString(stdin))
+		cmd.SetArgs(append([]string{"-p", testProto, "--target", "ip://" + ln.Addr().String()}, args...))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	// Bidi streaming, the requests are sent with a window smaller than their total size.
+	out, err := run("{\"msg\":\"a\"}\n\n{\"msg\":\"bb\"}\n{\"msg\":\"ccc\"}\n", "HelloWorldBidiStreamService/Hello")
+	require.Nil(t, err)
+	require.Equal(t, "{\"msg\":\"hello a\"}\n{\"msg\":\"hello bb\"}\n{\"msg\":\"hello ccc\"}\n", out)
+	<-feedbacks
+
+	// Client streaming.
+	out, err = run("{\"msg\":\"a\"}\n{\"msg\":\"b\"}\n", "HelloWroldClientStreamService/Hello")
+	require.Nil(t, err)
+	require.Equal(t, "{\"msg\":\"hello a b\"}\n", out)
+	<-feedbacks
+
+	// Server streaming, the client has to feedback to receive all the responses.
+	out, err = run("{\"msg\":\"a\"}\n{\"msg\":\"ignored\"}\n", "HelloWorldServerStreamService/Hello", "--window", "64")
+	require.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, serverStreamCount)
+	require.Equal(t, fmt.Sprintf("{\"msg\":\"hello a %d\"}", serverStreamCount-1), lines[serverStreamCount-1])
+	require.Greater(t, <-feedbacks, 0)
+
+	// Errors.
+	_, err = run("{\"msg\":\"fail\"}\n", "HelloWroldClientStreamService/Hello")
+	require.ErrorContains(t, err, "ret: 222, func_ret: 0, msg: bad request")
+	<-feedbacks
+	_, err = run("", "HelloWorldBidiStreamService/Hello", "--callee", "fail")
+	require.ErrorContains(t, err, "no such func")
+	<-feedbacks
+	_, err = run("{\"no_such_field\":1}\n", "HelloWorldBidiStreamService/Hello")
+	require.ErrorContains(t, err, "request 1: decode helloworld.HelloReq from json")
+	<-feedbacks
+	_, err = run("", "HelloWorldService/Hello1")
+	require.ErrorContains(t, err, "not a streaming method")
+}
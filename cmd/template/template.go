@@ -0,0 +1,315 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+// Package template provides template command.
+package template
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/multierr"
+
+	"trpc.group/trpc-go/trpc-cmdline/cmd/internal"
+	"trpc.group/trpc-go/trpc-cmdline/config"
+	"trpc.group/trpc-go/trpc-cmdline/tpl/lint"
+	"trpc.group/trpc-go/trpc-cmdline/tpl/pack"
+	"trpc.group/trpc-go/trpc-cmdline/util/log"
+	"trpc.group/trpc-go/trpc-cmdline/util/paths"
+	"trpc.group/trpc-go/trpc-cmdline/util/pb"
+)
+
+// ExitCodeLint is the exit code when problems are found in the templates.
+// It differs from 1, which means an error occurs, so that CI can tell the problems from a failed lint.
+const ExitCodeLint = 2
+
+// openStore opens the template store, which is replaced by tests.
+var openStore = pack.OpenDefault
+
+// CMD returns template command.
+func CMD() *cobra.Command {
+	templateCmd := &cobra.Command{
+		Use:   "template",
+		Short: "Manage the template packs used by trpc create",
+		Long: `Manage the template packs used by trpc create.
+
+A template pack is a named and versioned set of code templates, laid out the same as the built-in ones,
+e.g. ~/.trpc-cmdline-assets/protobuf/asset_go, with the metadata file template.yaml at its root:
+  name: my-service
+  version: v1.0.0
+  description: tRPC-Go service with company defaults
+  idl: protobuf
+  language: go
+
+Packs are installed into ~/.trpc-cmdline-templates, which is kept when trpc-cmdline is upgraded.
+The default pack of an IDL and language is layered on top of the built-in templates by 'trpc create'
+unless --assetdir is specified, so a pack only needs the files it changes, and lists the inherited files
+to delete in .trpcignore.
+`,
+		PersistentPreRun: func(*cobra.Command, []string) {
+			log.SetPrefix("[template]")
+		},
+	}
+	templateCmd.AddCommand(listCMD(), installCMD(), useCMD(), removeCMD(), lintCMD())
+	return templateCmd
+}
+
+func listCMD() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the installed template packs",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, err := openStore()
+			if err != nil {
+				return err
+			}
+			return list(cmd.OutOrStdout(), store)
+		},
+	}
+}
+
+func list(w io.Writer, store *pack.Store) error {
+	packs, err := store.List()
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tVERSION\tIDL\tLANGUAGE\tDEFAULT\tDESCRIPTION")
+	for _, p := range packs {
+		var mark string
+		def, err := store.Default(p.IDL, p.Language)
+		if err != nil {
+			log.Error("%v", err)
+		}
+		if def != nil && def.ID() == p.ID() {
+			mark = "*"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", p.Name, p.Version, p.IDL, p.Language, mark, p.Description)
+	}
+	return tw.Flush()
+}
+
+func installCMD() *cobra.Command {
+	var (
+		opts pack.InstallOptions
+		use  bool
+	)
+	installCmd := &cobra.Command{
+		Use:   "install <directory | tarball | git repository>",
+		Short: "Install a template pack from a local directory, a *.tar.gz tarball or a local git repository",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openStore()
+			if err != nil {
+				return err
+			}
+			p, err := store.Install(args[0], opts)
+			if err != nil {
+				return fmt.Errorf("install template pack err: %w", err)
+			}
+			log.Info("template pack %s is installed to %s", p.ID(), p.Dir)
+			if !use {
+				return nil
+			}
+			if _, err := store.Use(p.ID()); err != nil {
+				return err
+			}
+			log.Info("template pack %s is the default of %s/%s", p.ID(), p.IDL, p.Language)
+			return nil
+		},
+	}
+	installCmd.Flags().StringVar(&opts.Name, "name", "", "Name of the pack, overrides the one in template.yaml")
+	installCmd.Flags().StringVar(&opts.Version, "version", "",
+		"Version of the pack, overrides the one in template.yaml")
+	installCmd.Flags().StringVar(&opts.IDL, "idl", "",
+		"IDL of the pack, protobuf or flatbuffers, overrides the one in template.yaml, defaults to protobuf")
+	installCmd.Flags().StringVar(&opts.Language, "lang", "",
+		"Language of the pack, overrides the one in template.yaml, defaults to go")
+	installCmd.Flags().StringVar(&opts.Ref, "ref", "",
+		"Revision to install from the git repository, such as a tag, branch or commit, defaults to HEAD")
+	installCmd.Flags().BoolVarP(&opts.Force, "force", "f", false,
+		"Overwrite the installed pack with the same name and version")
+	installCmd.Flags().BoolVar(&use, "use", false, "Set the pack as the default of its IDL and language")
+	return installCmd
+}
+
+func useCMD() *cobra.Command {
+	var (
+		builtin bool
+		idl     string
+		lang    string
+	)
+	useCmd := &cobra.Command{
+		Use:   "use <name[@version]>",
+		Short: "Set the default template pack of its IDL and language",
+		Long: `Set the default template pack of its IDL and language.
+
+The latest installed version is used if no version is specified.
+Use --builtin to switch the IDL and language back to the built-in templates.
+`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if builtin {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openStore()
+			if err != nil {
+				return err
+			}
+			if builtin {
+				if err := store.Unuse(idl, lang); err != nil {
+					return err
+				}
+				log.Info("built-in templates are the default of %s/%s", idl, lang)
+				return nil
+			}
+			p, err := store.Use(args[0])
+			if err != nil {
+				return err
+			}
+			log.Info("template pack %s is the default of %s/%s", p.ID(), p.IDL, p.Language)
+			return nil
+		},
+	}
+	useCmd.Flags().BoolVar(&builtin, "builtin", false, "Switch back to the built-in templates")
+	useCmd.Flags().StringVar(&idl, "idl", config.IDLTypeProtobuf.String(), "IDL to switch back, used with --builtin")
+	useCmd.Flags().StringVar(&lang, "lang", "go", "Language to switch back, used with --builtin")
+	return useCmd
+}
+
+func removeCMD() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <name[@version]>...",
+		Short: "Remove the installed template packs, all versions are removed if no version is specified",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openStore()
+			if err != nil {
+				return err
+			}
+			var errs error
+			for _, ref := range args {
+				removed, err := store.Remove(ref)
+				if err != nil {
+					errs = multierr.Append(errs, err)
+					continue
+				}
+				for _, p := range removed {
+					log.Info("template pack %s is removed", p.ID())
+				}
+			}
+			return errs
+		},
+	}
+}
+
+func lintCMD() *cobra.Command {
+	var (
+		idl       string
+		lang      string
+		protodirs []string
+	)
+	lintCmd := &cobra.Command{
+		Use:   "lint <directory>",
+		Short: "Check the templates by rendering them against synthetic IDLs",
+		Long: `Check the templates by rendering them against synthetic IDLs.
+
+Every template inside the directory is rendered against a set of synthetic IDLs covering
+unary and streaming RPCs, multiple services, split by method, imports, aliases, RESTful rules and flatbuffers,
+and the parse and execution errors are reported with the file and line of the template.
+The generated Go code is also parsed the same as gofmt and type-checked against the standard library,
+the problems fixed by goimports, i.e. the imports which are missing or not used, are not reported.
+
+A template pack, i.e. a directory with template.yaml, is layered on top of the built-in templates
+the same as 'trpc create' does, and its IDL and language are used unless --idl or --lang is specified.
+The command exits with code 2 if any problem is found, or with code 1 if the lint itself fails.
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := lintOptions(cmd, args[0], idl, lang, protodirs)
+			if err != nil {
+				return err
+			}
+			issues, err := lint.Lint(opts)
+			if err != nil {
+				return err
+			}
+			for _, i := range issues {
+				fmt.Fprintln(cmd.OutOrStdout(), i)
+			}
+			if len(issues) != 0 {
+				return &internal.ExitError{
+					Code: ExitCodeLint,
+					Msg:  fmt.Sprintf("lint failed: %d problem(s) found in %s", len(issues), args[0]),
+				}
+			}
+			log.Info("no problem found in %s", args[0])
+			return nil
+		},
+	}
+	lintCmd.Flags().StringVar(&idl, "idl", "", "IDL of the templates, protobuf or flatbuffers, defaults to protobuf")
+	lintCmd.Flags().StringVar(&lang, "lang", "", "Language of the templates, defaults to go")
+	lintCmd.Flags().StringArrayVar(&protodirs, "protodir", nil,
+		"Search paths of the trpc protos imported by the synthetic IDLs, located automatically by default")
+	return lintCmd
+}
+
+// lintOptions returns the options to lint dir, whose IDL and language default to the ones of the pack.
+func lintOptions(cmd *cobra.Command, dir, idl, lang string, protodirs []string) (*lint.Options, error) {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	meta, err := pack.LoadMeta(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !cmd.Flags().Changed("idl") {
+		idl = meta.IDL
+	}
+	if !cmd.Flags().Changed("lang") {
+		lang = meta.Language
+	}
+	opts := &lint.Options{
+		Assetdir:  dir,
+		Language:  "go",
+		Protodirs: protodirs,
+	}
+	switch idl {
+	case "", config.IDLTypeProtobuf.String():
+		opts.IDLType = config.IDLTypeProtobuf
+	case config.IDLTypeFlatBuffers.String():
+		opts.IDLType = config.IDLTypeFlatBuffers
+	default:
+		return nil, fmt.Errorf("invalid idl %s, supported: %s, %s", idl, config.IDLTypeProtobuf, config.IDLTypeFlatBuffers)
+	}
+	if lang != "" {
+		opts.Language = lang
+	}
+	if meta.Name != "" {
+		cfg, err := config.GetTemplate(opts.IDLType, opts.Language)
+		if err != nil {
+			return nil, err
+		}
+		opts.Assetdir, opts.Overlays = cfg.AssetDir, []string{dir}
+	}
+	if len(opts.Protodirs) == 0 && opts.IDLType == config.IDLTypeProtobuf {
+		p, err := paths.Locate(pb.ProtoTRPC)
+		if err != nil {
+			return nil, fmt.Errorf("paths locate %s failed err: %w", pb.ProtoTRPC, err)
+		}
+		opts.Protodirs = append([]string{p}, paths.ExpandSearch(p)...)
+	}
+	return opts, nil
+}
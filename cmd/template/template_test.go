@@ -0,0 +1,99 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package template
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-cmdline/cmd/internal"
+	"trpc.group/trpc-go/trpc-cmdline/tpl/pack"
+)
+
+func TestTemplateCmd(t *testing.T) {
+	store := pack.NewStore(t.TempDir())
+	defer func(f func() (*pack.Store, error)) { openStore = f }(openStore)
+	openStore = func() (*pack.Store, error) { return store, nil }
+
+	src := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(src, pack.MetaFile),
+		[]byte("name: svc\nversion: v1.0.0\ndescription: test pack\n"), 0644))
+
+	run := func(args ...string) (string, error) {
+		cmd := CMD()
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetErr(out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	_, err := run("install", src, "--use")
+	require.Nil(t, err)
+	_, err = run("install", src, "--version", "v1.1.0")
+	require.Nil(t, err)
+
+	out, err := run("list")
+	require.Nil(t, err)
+	require.Regexp(t, `svc\s+v1.0.0\s+protobuf\s+go\s+\*\s+test pack`, out)
+	require.Regexp(t, `svc\s+v1.1.0\s+protobuf\s+go\s+test pack`, out)
+
+	_, err = run("use", "svc")
+	require.Nil(t, err)
+	def, err := store.Default("protobuf", "go")
+	require.Nil(t, err)
+	require.Equal(t, "svc@v1.1.0", def.ID())
+
+	_, err = run("use", "--builtin")
+	require.Nil(t, err)
+	def, err = store.Default("protobuf", "go")
+	require.Nil(t, err)
+	require.Nil(t, def)
+
+	_, err = run("remove", "svc@v1.0.0", "none")
+	require.ErrorContains(t, err, "template pack none is not installed")
+	packs, err := store.List()
+	require.Nil(t, err)
+	require.Len(t, packs, 1)
+}
+
+func TestLintCmd(t *testing.T) {
+	run := func(args ...string) (string, error) {
+		cmd := CMD()
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetErr(out)
+		cmd.SetArgs(append([]string{"lint", "--protodir", "../../install/submodules/trpc-protocol"}, args...))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	_, err := run("../../install/protobuf/asset_go")
+	require.Nil(t, err)
+	_, err = run("../../install/flatbuffers/asset_go", "--idl", "flatbuffers")
+	require.Nil(t, err)
+	_, err = run("../../install/flatbuffers/asset_go", "--idl", "thrift")
+	require.ErrorContains(t, err, "invalid idl thrift")
+
+	// A pack is layered on top of the built-in templates.
+	src := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(src, pack.MetaFile), []byte("name: svc\nversion: v1.0.0\n"), 0644))
+	require.Nil(t, os.WriteFile(filepath.Join(src, "main.go.tpl"), []byte("package main\n\n{{ .Nope }}\n"), 0644))
+	out, err := run(src)
+	var exitErr *internal.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, ExitCodeLint, exitErr.Code)
+	require.Contains(t, out, "main.go.tpl:3: executing")
+	require.NotContains(t, out, "service_rpc.go.tpl")
+}
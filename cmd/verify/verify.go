@@ -0,0 +1,263 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+// Package verify provides verify command.
+package verify
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"trpc.group/trpc-go/trpc-cmdline/cmd/create"
+	"trpc.group/trpc-go/trpc-cmdline/cmd/internal"
+	"trpc.group/trpc-go/trpc-cmdline/cmd/internal/lockfile"
+	"trpc.group/trpc-go/trpc-cmdline/util/log"
+)
+
+// ExitCodeDrift is the exit code when the generated code drifts from the IDL.
+// It differs from 1, which means an error occurs, so that CI can tell the drift from a failed verify.
+const ExitCodeDrift = 2
+
+// CMD returns verify command.
+func CMD() *cobra.Command {
+	verifyCmd := &cobra.Command{
+		Use:   "verify [lockfile or directory]...",
+		Short: "Verify that the generated code is up to date with the IDL",
+		Long: `Verify that the generated code is up to date with the IDL.
+
+'trpc create' writes trpc-gen.lock into the output directory, which records the IDL inputs,
+tool versions, options and generated files. 'trpc verify' regenerates the code in a temporary
+directory by the recorded options, and compares it against the lock file and the files in the tree.
+
+The following problems are reported, and the command exits with code 2 if any of them is found,
+or with code 1 if the verification itself fails:
+- stale:    the generated file is out of date, or is no longer generated, regenerate to fix it
+- missing:  the generated file does not exist
+- modified: the generated file is modified by hand
+
+Directories are searched recursively for trpc-gen.lock, the current directory is used by default.
+Scaffold files of full projects, such as main.go and the service implementations, are not checked.
+`,
+		RunE: runVerify,
+	}
+	return verifyCmd
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	log.SetPrefix("[verify]")
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+	locks, err := lockfile.Find(args)
+	if err != nil {
+		return err
+	}
+	if len(locks) == 0 {
+		return fmt.Errorf("no %s found in %v", lockfile.Name, args)
+	}
+	var problems []*Problem
+	for _, fp := range locks {
+		ps, err := verify(cmd.Flags(), fp)
+		if err != nil {
+			return fmt.Errorf("verify %s err: %w", fp, err)
+		}
+		problems = append(problems, ps...)
+	}
+	report(cmd.OutOrStdout(), problems)
+	if len(problems) != 0 {
+		return &internal.ExitError{
+			Code: ExitCodeDrift,
+			Msg:  fmt.Sprintf("verify failed: %d problem(s) found, please regenerate the code", len(problems)),
+		}
+	}
+	log.Info("generated code is up to date")
+	return nil
+}
+
+// Kinds of problems.
+const (
+	Stale    = "stale"
+	Missing  = "missing"
+	Modified = "modified"
+)
+
+// Problem is a drift found by verify.
+type Problem struct {
+	Kind   string
+	Path   string // Path of the file.
+	Reason string
+}
+
+// verify regenerates the code of the lock file fp in a temporary directory and checks the drifts.
+func verify(shared *pflag.FlagSet, fp string) ([]*Problem, error) {
+	fp, err := filepath.Abs(fp)
+	if err != nil {
+		return nil, fmt.Errorf("get absolute path err: %w", err)
+	}
+	locked, err := lockfile.Load(fp)
+	if err != nil {
+		return nil, err
+	}
+	lockDir := filepath.Dir(fp)
+	fresh, err := regenerate(shared, lockDir, locked)
+	if err != nil {
+		return nil, fmt.Errorf("regenerate err: %w", err)
+	}
+	for _, name := range sortedKeys(locked.Tools, fresh.Tools) {
+		if locked.Tools[name] != fresh.Tools[name] {
+			log.Info("%s: %s version differs, locked %q, now %q, which may cause drifts",
+				fp, name, locked.Tools[name], fresh.Tools[name])
+		}
+	}
+	return check(lockDir, locked, fresh)
+}
+
+// regenerate runs the create command by the options recorded in the lock file,
+// and returns the lock file of the regenerated code.
+func regenerate(shared *pflag.FlagSet, lockDir string, locked *lockfile.Lock) (*lockfile.Lock, error) {
+	tmp, err := os.MkdirTemp("", "trpc-verify-*")
+	if err != nil {
+		return nil, fmt.Errorf("create temporary directory err: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	// Keep the base name, since some of the generators derive names from the output directory.
+	out := filepath.Join(tmp, filepath.Base(lockDir))
+
+	createCmd := create.CMD()
+	flags := createCmd.Flags()
+	internal.ShareFlags(shared, flags)
+	names := make([]string, 0, len(locked.Flags))
+	for name := range locked.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if flags.Lookup(name) == nil {
+			log.Debug("skip flag %s unknown to this version", name)
+			continue
+		}
+		if err := internal.SetFlag(flags, name, locked.Flags[name]...); err != nil {
+			return nil, err
+		}
+	}
+	for name, val := range map[string]string{
+		"output":   out,
+		"force":    "true",
+		"lockfile": "true",
+		"sync":     "false",
+		"no-hooks": "true",
+	} {
+		if err := internal.SetFlag(flags, name, val); err != nil {
+			return nil, err
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("get working directory err: %w", err)
+	}
+	defer os.Chdir(wd)
+	workdir := filepath.Join(lockDir, filepath.FromSlash(locked.Workdir))
+	if err := os.Chdir(workdir); err != nil {
+		return nil, fmt.Errorf("chdir into %s err: %w", workdir, err)
+	}
+	if err := internal.Run(createCmd, nil); err != nil {
+		return nil, err
+	}
+	return lockfile.Load(filepath.Join(out, lockfile.Name))
+}
+
+// check compares the files of the tree under lockDir with the locked and freshly generated ones.
+func check(lockDir string, locked, fresh *lockfile.Lock) ([]*Problem, error) {
+	var problems []*Problem
+	lockedFiles, freshFiles := locked.FileMap(), fresh.FileMap()
+	for _, f := range fresh.Files {
+		if f.Scaffold {
+			continue
+		}
+		fp := filepath.Join(lockDir, filepath.FromSlash(f.Path))
+		h, err := lockfile.HashFile(fp)
+		if os.IsNotExist(err) {
+			problems = append(problems, &Problem{Missing, fp, "generated but not found"})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("hash %s err: %w", fp, err)
+		}
+		if h == f.SHA256 {
+			continue
+		}
+		if l, ok := lockedFiles[f.Path]; ok && l.SHA256 == h {
+			problems = append(problems, &Problem{Stale, fp, "out of date with the IDL, options or tools"})
+		} else {
+			problems = append(problems, &Problem{Modified, fp, "modified by hand"})
+		}
+	}
+	for _, f := range locked.Files {
+		if _, ok := freshFiles[f.Path]; ok || f.Scaffold {
+			continue
+		}
+		fp := filepath.Join(lockDir, filepath.FromSlash(f.Path))
+		if _, err := os.Stat(fp); err == nil {
+			problems = append(problems, &Problem{Stale, fp, "no longer generated"})
+		}
+	}
+	if reason := lockDrift(locked, fresh); reason != "" {
+		problems = append(problems, &Problem{Stale, filepath.Join(lockDir, lockfile.Name), reason})
+	}
+	return problems, nil
+}
+
+// lockDrift returns why the lock file is out of date, or an empty string if it is not.
+func lockDrift(locked, fresh *lockfile.Lock) string {
+	for _, name := range sortedKeys(locked.Inputs, fresh.Inputs) {
+		if locked.Inputs[name] != fresh.Inputs[name] {
+			return fmt.Sprintf("input %s has changed since the last generation", name)
+		}
+	}
+	lockedFiles := locked.FileMap()
+	for _, f := range fresh.Files {
+		if l, ok := lockedFiles[f.Path]; !f.Scaffold && (!ok || l.SHA256 != f.SHA256) {
+			return fmt.Sprintf("generated file %s is not recorded", f.Path)
+		}
+	}
+	return ""
+}
+
+func report(w io.Writer, problems []*Problem) {
+	wd, _ := os.Getwd()
+	for _, p := range problems {
+		path := p.Path
+		if rel, err := filepath.Rel(wd, p.Path); err == nil {
+			path = rel
+		}
+		fmt.Fprintf(w, "%-8s %s: %s\n", p.Kind, path, p.Reason)
+	}
+}
+
+func sortedKeys(maps ...map[string]string) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,98 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package verify
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-cmdline/cmd/internal/lockfile"
+)
+
+func newLock(inputs map[string]string, files ...*lockfile.File) *lockfile.Lock {
+	return &lockfile.Lock{Version: lockfile.Version, Inputs: inputs, Files: files}
+}
+
+func file(path, content string) *lockfile.File {
+	return &lockfile.File{Path: path, SHA256: lockfile.Hash([]byte(content))}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	tree := map[string]string{
+		"same.pb.go":     "same",
+		"stale.pb.go":    "old",
+		"modified.pb.go": "hand-written",
+		"obsolete.pb.go": "obsolete",
+		"main.go":        "scaffold edited by hand",
+	}
+	for name, content := range tree {
+		require.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	inputs := map[string]string{"helloworld.proto": "h1"}
+	scaffold := file("main.go", "scaffold")
+	scaffold.Scaffold = true
+	locked := newLock(inputs,
+		file("same.pb.go", "same"),
+		file("stale.pb.go", "old"),
+		file("modified.pb.go", "generated"),
+		file("obsolete.pb.go", "obsolete"),
+		file("missing.pb.go", "missing"),
+		scaffold,
+	)
+	fresh := newLock(map[string]string{"helloworld.proto": "h2"},
+		file("same.pb.go", "same"),
+		file("stale.pb.go", "new"),
+		file("modified.pb.go", "generated"),
+		file("missing.pb.go", "missing"),
+		scaffold,
+	)
+
+	problems, err := check(dir, locked, fresh)
+	require.Nil(t, err)
+	got := make(map[string]string)
+	for _, p := range problems {
+		got[filepath.Base(p.Path)] = p.Kind
+	}
+	require.Equal(t, map[string]string{
+		"stale.pb.go":    Stale,
+		"modified.pb.go": Modified,
+		"missing.pb.go":  Missing,
+		"obsolete.pb.go": Stale,
+		lockfile.Name:    Stale,
+	}, got)
+
+	buf := &bytes.Buffer{}
+	report(buf, problems)
+	require.Contains(t, buf.String(), "modified by hand")
+	require.Contains(t, buf.String(), "input helloworld.proto has changed")
+}
+
+func TestCheck_UpToDate(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "a.pb.go"), []byte("a"), 0644))
+	locked := newLock(map[string]string{"a.proto": "h"}, file("a.pb.go", "a"))
+	fresh := newLock(map[string]string{"a.proto": "h"}, file("a.pb.go", "a"))
+	problems, err := check(dir, locked, fresh)
+	require.Nil(t, err)
+	require.Empty(t, problems)
+
+	// The tree is regenerated, but the lock file is not updated.
+	fresh = newLock(map[string]string{"a.proto": "h"}, file("a.pb.go", "a"), file("b.pb.go", "b"))
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "b.pb.go"), []byte("b"), 0644))
+	problems, err = check(dir, locked, fresh)
+	require.Nil(t, err)
+	require.Len(t, problems, 1)
+	require.Equal(t, filepath.Join(dir, lockfile.Name), problems[0].Path)
+}
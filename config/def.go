@@ -66,6 +66,12 @@ type Template struct {
 	RPCClientStub     []string `yaml:"rpc_client_stub"`      // Client stub
 	// Whether the client stub includes all service definitions
 	RPCClientStubPerService bool `yaml:"rpc_client_stub_per_service"`
+	// Glob patterns of the templates rendered once per message, such as model/*.go.tpl.
+	// Templates with {{.Message}} in their file names are rendered per message regardless.
+	MessageStub []string `yaml:"message_stub"`
+	// Glob patterns of the templates rendered once per enum.
+	// Templates with {{.Enum}} in their file names are rendered per enum regardless.
+	EnumStub []string `yaml:"enum_stub"`
 }
 
 // OpSys is the system of operation (运营体系).
@@ -80,8 +86,11 @@ type Config struct {
 	TplFileExt string                          `yaml:"tpl_file_ext"`
 	IDL        map[string]*Dependency          `yaml:"idl"`       // IDL name -> IDL tool
 	Tools      map[string][]*Dependency        `yaml:"tools"`     // Programming language -> Dependency tools
-	Plugins    map[string][]string             `yaml:"plugins"`   // Programming language -> Dependency plugins
+	Plugins    map[string][]*PluginConfig      `yaml:"plugins"`   // Programming language -> Plugin chain
 	Templates  map[string]map[string]*Template `yaml:"templates"` // idltype -> Code templates for each language
+
+	// PBCompiler is the default compiler of the pb files, protoc or builtin, defaults to protoc.
+	PBCompiler string `yaml:"pb_compiler"`
 }
 
 // Dependency is the description of dependencies.
@@ -0,0 +1,107 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ProjectConfigFile is the name of the project level configuration file,
+// which is looked up in the current working directory.
+const ProjectConfigFile = ".trpc.yaml"
+
+// PluginConfig describes a plugin inside the plugin chain of a language.
+//
+// Both of the following forms are accepted in trpc.yaml:
+//
+//	plugins:
+//	  go:
+//	    - goimports              # name only, enabled with no settings
+//	    - name: gotag            # full form
+//	      disabled: true
+//	      settings:
+//	        key: value
+//	    - name: my_post_process  # external executable plugin
+//	      exec: /path/to/executable
+//	      args: ["--flag"]
+type PluginConfig struct {
+	Name     string                 `yaml:"name"`     // Plugin name, same as Plugin.Name().
+	Disabled bool                   `yaml:"disabled"` // Whether the plugin is skipped.
+	Settings map[string]interface{} `yaml:"settings"` // Settings block passed to the plugin.
+	// Exec is the executable of an external plugin, which speaks the JSON protocol over stdin/stdout.
+	// It is looked up in $PATH if it contains no path separator.
+	Exec string   `yaml:"exec"`
+	Args []string `yaml:"args"` // Arguments passed to the external plugin.
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, so that a plain plugin name is also accepted.
+func (p *PluginConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var name string
+	if err := unmarshal(&name); err == nil {
+		p.Name = name
+		return nil
+	}
+	type plain PluginConfig
+	if err := unmarshal((*plain)(p)); err != nil {
+		return err
+	}
+	if p.Name == "" {
+		return errors.New("plugin name is empty")
+	}
+	return nil
+}
+
+// ProjectConfig is the project level configuration, which overrides parts of the global config.
+type ProjectConfig struct {
+	Plugins map[string][]*PluginConfig `yaml:"plugins"` // Programming language -> plugin chain
+}
+
+// LoadProjectConfig loads the project config located in dir.
+// A nil config is returned if the file does not exist.
+func LoadProjectConfig(dir string) (*ProjectConfig, error) {
+	fp := filepath.Join(dir, ProjectConfigFile)
+	b, err := os.ReadFile(fp)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read project config %s err: %w", fp, err)
+	}
+	cfg := &ProjectConfig{}
+	if err := yaml.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("yaml unmarshal project config %s err: %w", fp, err)
+	}
+	return cfg, nil
+}
+
+// PluginConfigs returns the plugin chain configuration of the given language.
+// The plugin list of the project config in the current working directory takes precedence
+// over the one in the global config. Nil is returned if neither of them configures the language.
+func PluginConfigs(lang string) ([]*PluginConfig, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("get working directory err: %w", err)
+	}
+	project, err := LoadProjectConfig(wd)
+	if err != nil {
+		return nil, err
+	}
+	if project != nil {
+		if cfgs, ok := project.Plugins[lang]; ok {
+			return cfgs, nil
+		}
+	}
+	return GlobalConfig().Plugins[lang], nil
+}
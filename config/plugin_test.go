@@ -0,0 +1,66 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestPluginConfig_UnmarshalYAML(t *testing.T) {
+	var cfg Config
+	err := yaml.Unmarshal([]byte(`
+plugins:
+  go:
+    - goimports
+    - name: gotag
+      disabled: true
+    - name: mockgen
+      settings:
+        source: foo.go
+`), &cfg)
+	require.Nil(t, err)
+	require.Len(t, cfg.Plugins["go"], 3)
+	require.Equal(t, &PluginConfig{Name: "goimports"}, cfg.Plugins["go"][0])
+	require.Equal(t, &PluginConfig{Name: "gotag", Disabled: true}, cfg.Plugins["go"][1])
+	require.Equal(t, "mockgen", cfg.Plugins["go"][2].Name)
+	require.Equal(t, "foo.go", cfg.Plugins["go"][2].Settings["source"])
+
+	err = yaml.Unmarshal([]byte(`
+plugins:
+  go:
+    - disabled: true
+`), &cfg)
+	require.NotNil(t, err)
+}
+
+func TestLoadProjectConfig(t *testing.T) {
+	dir := t.TempDir()
+	cfg, err := LoadProjectConfig(dir)
+	require.Nil(t, err)
+	require.Nil(t, cfg)
+
+	require.Nil(t, os.WriteFile(filepath.Join(dir, ProjectConfigFile), []byte(`
+plugins:
+  go:
+    - gofmt
+`), 0644))
+	cfg, err = LoadProjectConfig(dir)
+	require.Nil(t, err)
+	require.Equal(t, []*PluginConfig{{Name: "gofmt"}}, cfg.Plugins["go"])
+
+	require.Nil(t, os.WriteFile(filepath.Join(dir, ProjectConfigFile), []byte(`plugins: [`), 0644))
+	_, err = LoadProjectConfig(dir)
+	require.NotNil(t, err)
+}
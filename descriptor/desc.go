@@ -53,6 +53,8 @@ type Desc interface {
 	GetServices() []ServiceDesc
 	// GetMessageTypes returns the descriptor of all the messages defined in the file.
 	GetMessageTypes() []MessageDesc
+	// GetEnumTypes returns the descriptor of all the top level enums defined in the file.
+	GetEnumTypes() []EnumDesc
 }
 
 // FileOpt provides an interface for file options.
@@ -101,6 +103,68 @@ type MessageDesc interface {
 	// GetFullyQualifiedName returns the full name of this message.
 	// Usually includes package information, such as "trpc.testapp.testserver.TestMessage".
 	GetFullyQualifiedName() string
+	// GetName returns the name of this message without package information, such as "TestMessage".
+	GetName() string
+	// GetFields returns the descriptor of all the fields of this message in the order of definition.
+	GetFields() []FieldDesc
+	// GetNestedMessageTypes returns the descriptor of the messages nested in this message.
+	// For flatbuffers, it is always empty.
+	GetNestedMessageTypes() []MessageDesc
+	// GetNestedEnumTypes returns the descriptor of the enums nested in this message.
+	// For flatbuffers, it is always empty.
+	GetNestedEnumTypes() []EnumDesc
+	// GetSourceInfo returns the comment information of this message in the source file.
+	GetSourceInfo() SourceInfo
+}
+
+// FieldDesc provides an interface for describing fields of messages in different IDLs.
+type FieldDesc interface {
+	// GetName returns the name of the field.
+	GetName() string
+	// GetNumber returns the field number.
+	// For flatbuffers, it is the id of the field, i.e. the index of the field in the table.
+	GetNumber() int32
+	// GetType returns the type of the field, which is the scalar type name defined by the IDL,
+	// such as "int32", "string" for protobuf and "int", "ubyte" for flatbuffers,
+	// or "message", "enum" for the composite types, and "union" for the unions of flatbuffers.
+	GetType() string
+	// GetTypeName returns the fully qualified name of the message or enum type of the field,
+	// which is empty for the scalar types.
+	GetTypeName() string
+	// IsRepeated returns true if it is a repeated field of protobuf or a vector of flatbuffers.
+	IsRepeated() bool
+	// IsMap returns true if it is a map field, which is always false for flatbuffers.
+	IsMap() bool
+	// GetMapKeyType returns the descriptor of the key of the map field, or nil if it is not a map field.
+	GetMapKeyType() FieldDesc
+	// GetMapValueType returns the descriptor of the value of the map field, or nil if it is not a map field.
+	GetMapValueType() FieldDesc
+	// GetOneOf returns the name of the oneof which the field belongs to, or empty if there is none.
+	GetOneOf() string
+	// GetSourceInfo returns the comment information of this field in the source file.
+	GetSourceInfo() SourceInfo
+}
+
+// EnumDesc provides an interface for describing enums in different IDLs.
+type EnumDesc interface {
+	// GetName returns the name of the enum without package information.
+	GetName() string
+	// GetFullyQualifiedName returns the full name of this enum, which is formed the same as the one of MessageDesc.
+	GetFullyQualifiedName() string
+	// GetValues returns the descriptor of all the values of the enum in the order of definition.
+	GetValues() []EnumValueDesc
+	// GetSourceInfo returns the comment information of this enum in the source file.
+	GetSourceInfo() SourceInfo
+}
+
+// EnumValueDesc provides an interface for describing enum values in different IDLs.
+type EnumValueDesc interface {
+	// GetName returns the name of the enum value.
+	GetName() string
+	// GetNumber returns the number of the enum value.
+	GetNumber() int32
+	// GetSourceInfo returns the comment information of this enum value in the source file.
+	GetSourceInfo() SourceInfo
 }
 
 // SourceInfo provides an interface for source code comments in different IDLs.
@@ -155,6 +219,9 @@ type FileDescriptor struct {
 
 	// RPCMessageType maps message type names to the filename where defined that type.
 	RPCMessageType map[string]string // k is pkg.typ defined by pb, v is valid pkg.typ in go.
+
+	Messages []*MessageDescriptor // Top level messages defined in the file, extracted from pb message or fbs table.
+	Enums    []*EnumDescriptor    // Top level enums defined in the file.
 }
 
 // Dump prints the protobuf file parsing information.
@@ -233,3 +300,97 @@ type RESTfulAPIContent struct {
 	RequestBody  string
 	ResponseBody string
 }
+
+// MessageDescriptor provides the description information at the message level.
+type MessageDescriptor struct {
+	Name               string // Name of the message, such as TypeA.
+	FullyQualifiedName string // Name of the message including package, the same form as RPCDescriptor.RequestType.
+	LeadingComments    string
+	TrailingComments   string
+	Fields             []*FieldDescriptor   // Fields in the order of definition.
+	Oneofs             []*OneofDescriptor   // Oneofs of protobuf, excluding the ones of proto3 optional fields.
+	Messages           []*MessageDescriptor // Nested messages, excluding the entries of map fields.
+	Enums              []*EnumDescriptor    // Nested enums.
+	Options            map[string]interface{}
+}
+
+// FieldDescriptor provides the description information at the field level.
+type FieldDescriptor struct {
+	Name             string
+	Number           int32
+	Type             string // Scalar type name, or "message", "enum" and "union", see FieldDesc.GetType.
+	TypeName         string // Fully qualified name of the message or enum type.
+	Repeated         bool
+	Map              bool
+	MapKey           *FieldDescriptor // Key of the map field.
+	MapValue         *FieldDescriptor // Value of the map field.
+	Oneof            string           // Name of the oneof which the field belongs to.
+	LeadingComments  string
+	TrailingComments string
+	// Options are the field options of protobuf, the extensions are keyed by their fully qualified names,
+	// such as validate.rules.
+	Options map[string]interface{}
+}
+
+// OneofDescriptor provides the description information of a oneof.
+type OneofDescriptor struct {
+	Name   string
+	Fields []*FieldDescriptor
+}
+
+// EnumDescriptor provides the description information at the enum level.
+type EnumDescriptor struct {
+	Name               string
+	FullyQualifiedName string
+	LeadingComments    string
+	TrailingComments   string
+	Values             []*EnumValueDescriptor
+	Options            map[string]interface{}
+}
+
+// EnumValueDescriptor provides the description information of an enum value.
+type EnumValueDescriptor struct {
+	Name             string
+	Number           int32
+	LeadingComments  string
+	TrailingComments string
+	Options          map[string]interface{}
+}
+
+// FindMessage returns the message defined in the file by its fully qualified name,
+// including the nested ones, or nil if it is not found.
+func (fd *FileDescriptor) FindMessage(name string) *MessageDescriptor {
+	var find func([]*MessageDescriptor) *MessageDescriptor
+	find = func(mds []*MessageDescriptor) *MessageDescriptor {
+		for _, md := range mds {
+			if md.FullyQualifiedName == name {
+				return md
+			}
+			if found := find(md.Messages); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	return find(fd.Messages)
+}
+
+// FindEnum returns the enum defined in the file by its fully qualified name,
+// including the ones nested in messages, or nil if it is not found.
+func (fd *FileDescriptor) FindEnum(name string) *EnumDescriptor {
+	var find func([]*EnumDescriptor, []*MessageDescriptor) *EnumDescriptor
+	find = func(eds []*EnumDescriptor, mds []*MessageDescriptor) *EnumDescriptor {
+		for _, ed := range eds {
+			if ed.FullyQualifiedName == name {
+				return ed
+			}
+		}
+		for _, md := range mds {
+			if found := find(md.Enums, md.Messages); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	return find(fd.Enums, fd.Messages)
+}
@@ -64,6 +64,33 @@ func TestFbsDesc(t *testing.T) {
 	require.Equal(t, ".HelloRequest", fbsMessageDesc.GetFullyQualifiedName())
 	fbsMessageDesc.MD.Namespace = "trpc.testapp.testserver"
 	require.Equal(t, ".trpc.testapp.testserver.HelloRequest", fbsMessageDesc.GetFullyQualifiedName())
+	require.Equal(t, "HelloRequest", fbsMessageDesc.GetName())
+	require.Empty(t, fbsMessageDesc.GetNestedMessageTypes())
+
+	enumDesc := &fbs.EnumDesc{Name: "Status", Values: []*fbs.EnumValDesc{{Name: "OK", Number: 1}}}
+	schema.Enums = []*fbs.EnumDesc{enumDesc}
+	reqDesc.Fields = []*fbs.FieldDesc{
+		{Name: "msg", TypeName: "string", IsVector: true},
+		{Name: "status", TypeName: ".Status"},
+		{Name: "rsp", TypeName: ".trpc.testapp.testserver.HelloResponse"},
+	}
+	fields := fbsMessageDesc.GetFields()
+	require.Len(t, fields, 3)
+	require.Equal(t, "msg", fields[0].GetName())
+	require.Equal(t, "string", fields[0].GetType())
+	require.Empty(t, fields[0].GetTypeName())
+	require.True(t, fields[0].IsRepeated())
+	require.False(t, fields[0].IsMap())
+	require.Nil(t, fields[0].GetMapKeyType())
+	require.Equal(t, int32(1), fields[1].GetNumber())
+	require.Equal(t, "enum", fields[1].GetType())
+	require.Equal(t, ".Status", fields[1].GetTypeName())
+	require.Equal(t, "message", fields[2].GetType())
+	enums := fbsDesc.GetEnumTypes()
+	require.Len(t, enums, 1)
+	require.Equal(t, ".Status", enums[0].GetFullyQualifiedName())
+	require.Equal(t, "OK", enums[0].GetValues()[0].GetName())
+	require.Equal(t, int32(1), enums[0].GetValues()[0].GetNumber())
 	fbsSourceInfo := &FbsSourceInfo{}
 	require.Equal(t, "", fbsSourceInfo.GetLeadingComments())
 	require.Equal(t, "", fbsSourceInfo.GetTrailingComments())
@@ -99,4 +126,7 @@ func TestProtoDesc(t *testing.T) {
 	require.False(t, method.IsServerStreaming())
 	require.Equal(t, "", method.GetSourceInfo().GetLeadingComments())
 	require.Equal(t, "hello.HelloReq", method.GetInputType().GetFullyQualifiedName())
+	require.Equal(t, "HelloReq", method.GetInputType().GetName())
+	require.Empty(t, method.GetInputType().GetFields())
+	require.Empty(t, protoFileDesc.GetEnumTypes())
 }
@@ -75,6 +75,15 @@ func (p *FbsFileDescriptor) GetMessageTypes() []MessageDesc {
 	return descs
 }
 
+// GetEnumTypes implements the Desc interface.
+func (p *FbsFileDescriptor) GetEnumTypes() []EnumDesc {
+	var descs []EnumDesc
+	for _, ed := range p.FD.Enums {
+		descs = append(descs, &FbsEnumDescriptor{ED: ed})
+	}
+	return descs
+}
+
 // FbsAttrs implements the FileOpt interface.
 // Attrs contains various strings stored in the attribute field of flatbuffers.
 // Among them, the following format is customized to provide go package information for flatbuffers files.
@@ -184,10 +193,187 @@ func (p *FbsMessageDescriptor) GetFile() Desc {
 
 // GetFullyQualifiedName implements the MessageDesc interface.
 func (p *FbsMessageDescriptor) GetFullyQualifiedName() string {
-	if p.MD.Namespace == "" {
-		return "." + p.MD.Name
+	return fbsFullyQualifiedName(p.MD.Namespace, p.MD.Name)
+}
+
+// GetName implements the MessageDesc interface.
+func (p *FbsMessageDescriptor) GetName() string {
+	return p.MD.Name
+}
+
+// GetFields implements the MessageDesc interface.
+func (p *FbsMessageDescriptor) GetFields() []FieldDesc {
+	var descs []FieldDesc
+	for i, fd := range p.MD.Fields {
+		descs = append(descs, &FbsFieldDescriptor{FD: fd, Number: int32(i), Schema: p.MD.Schema})
+	}
+	return descs
+}
+
+// GetNestedMessageTypes implements the MessageDesc interface.
+func (p *FbsMessageDescriptor) GetNestedMessageTypes() []MessageDesc {
+	return nil
+}
+
+// GetNestedEnumTypes implements the MessageDesc interface.
+func (p *FbsMessageDescriptor) GetNestedEnumTypes() []EnumDesc {
+	return nil
+}
+
+// GetSourceInfo implements the MessageDesc interface.
+func (p *FbsMessageDescriptor) GetSourceInfo() SourceInfo {
+	return &FbsSourceInfo{}
+}
+
+// FbsFieldDescriptor implements the FieldDesc interface.
+type FbsFieldDescriptor struct {
+	FD     *fbs.FieldDesc
+	Number int32           // Number is the id of the field.
+	Schema *fbs.SchemaDesc // Schema is the file where the table of the field is defined.
+}
+
+// GetName implements the FieldDesc interface.
+func (p *FbsFieldDescriptor) GetName() string {
+	return p.FD.Name
+}
+
+// GetNumber implements the FieldDesc interface.
+func (p *FbsFieldDescriptor) GetNumber() int32 {
+	return p.Number
+}
+
+// GetType implements the FieldDesc interface.
+// The type names of tables, structs, enums and unions have been resolved to be fully qualified
+// with a leading dot by the parser, and the others are scalar types.
+func (p *FbsFieldDescriptor) GetType() string {
+	if !strings.HasPrefix(p.FD.TypeName, ".") {
+		return p.FD.TypeName
+	}
+	if kind := fbsTypeKind(p.Schema, p.FD.TypeName, make(map[*fbs.SchemaDesc]bool)); kind != "" {
+		return kind
+	}
+	// Tables and structs.
+	return "message"
+}
+
+// fbsTypeKind returns "enum" or "union" if the type is an enum or a union defined in the schema
+// or its dependencies, otherwise returns empty.
+func fbsTypeKind(schema *fbs.SchemaDesc, name string, visited map[*fbs.SchemaDesc]bool) string {
+	if schema == nil || visited[schema] {
+		return ""
+	}
+	visited[schema] = true
+	for _, ed := range schema.Enums {
+		if fbsFullyQualifiedName(ed.Namespace, ed.Name) == name {
+			return "enum"
+		}
+	}
+	for _, ud := range schema.Unions {
+		if fbsFullyQualifiedName(ud.Namespace, ud.Name) == name {
+			return "union"
+		}
+	}
+	for _, dep := range schema.Dependencies {
+		if kind := fbsTypeKind(dep, name, visited); kind != "" {
+			return kind
+		}
+	}
+	return ""
+}
+
+// GetTypeName implements the FieldDesc interface.
+func (p *FbsFieldDescriptor) GetTypeName() string {
+	if strings.HasPrefix(p.FD.TypeName, ".") {
+		return p.FD.TypeName
+	}
+	return ""
+}
+
+// IsRepeated implements the FieldDesc interface.
+func (p *FbsFieldDescriptor) IsRepeated() bool {
+	return p.FD.IsVector
+}
+
+// IsMap implements the FieldDesc interface.
+func (p *FbsFieldDescriptor) IsMap() bool {
+	return false
+}
+
+// GetMapKeyType implements the FieldDesc interface.
+func (p *FbsFieldDescriptor) GetMapKeyType() FieldDesc {
+	return nil
+}
+
+// GetMapValueType implements the FieldDesc interface.
+func (p *FbsFieldDescriptor) GetMapValueType() FieldDesc {
+	return nil
+}
+
+// GetOneOf implements the FieldDesc interface.
+func (p *FbsFieldDescriptor) GetOneOf() string {
+	return ""
+}
+
+// GetSourceInfo implements the FieldDesc interface.
+func (p *FbsFieldDescriptor) GetSourceInfo() SourceInfo {
+	return &FbsSourceInfo{}
+}
+
+// FbsEnumDescriptor implements the EnumDesc interface.
+type FbsEnumDescriptor struct {
+	ED *fbs.EnumDesc
+}
+
+// GetName implements the EnumDesc interface.
+func (p *FbsEnumDescriptor) GetName() string {
+	return p.ED.Name
+}
+
+// GetFullyQualifiedName implements the EnumDesc interface.
+func (p *FbsEnumDescriptor) GetFullyQualifiedName() string {
+	return fbsFullyQualifiedName(p.ED.Namespace, p.ED.Name)
+}
+
+// GetValues implements the EnumDesc interface.
+func (p *FbsEnumDescriptor) GetValues() []EnumValueDesc {
+	var descs []EnumValueDesc
+	for _, vd := range p.ED.Values {
+		descs = append(descs, &FbsEnumValueDescriptor{VD: vd})
+	}
+	return descs
+}
+
+// GetSourceInfo implements the EnumDesc interface.
+func (p *FbsEnumDescriptor) GetSourceInfo() SourceInfo {
+	return &FbsSourceInfo{}
+}
+
+// FbsEnumValueDescriptor implements the EnumValueDesc interface.
+type FbsEnumValueDescriptor struct {
+	VD *fbs.EnumValDesc
+}
+
+// GetName implements the EnumValueDesc interface.
+func (p *FbsEnumValueDescriptor) GetName() string {
+	return p.VD.Name
+}
+
+// GetNumber implements the EnumValueDesc interface.
+func (p *FbsEnumValueDescriptor) GetNumber() int32 {
+	return p.VD.Number
+}
+
+// GetSourceInfo implements the EnumValueDesc interface.
+func (p *FbsEnumValueDescriptor) GetSourceInfo() SourceInfo {
+	return &FbsSourceInfo{}
+}
+
+// fbsFullyQualifiedName returns the fully qualified name of the type in the namespace, with a leading dot.
+func fbsFullyQualifiedName(namespace, name string) string {
+	if namespace == "" {
+		return "." + name
 	}
-	return "." + p.MD.Namespace + "." + p.MD.Name
+	return "." + namespace + "." + name
 }
 
 // FbsSourceInfo implements the SourceInfo interface.
@@ -9,7 +9,12 @@
 
 package descriptor
 
-import "github.com/jhump/protoreflect/desc"
+import (
+	"strings"
+
+	"github.com/jhump/protoreflect/desc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
 
 // ProtoFileDescriptor implements the Desc interface.
 // Describes all information about a protobuf file.
@@ -67,6 +72,19 @@ func (p *ProtoFileDescriptor) GetMessageTypes() []MessageDesc {
 	return descs
 }
 
+// GetEnumTypes implements the Desc interface.
+func (p *ProtoFileDescriptor) GetEnumTypes() []EnumDesc {
+	return protoEnums(p.FD.GetEnumTypes())
+}
+
+func protoEnums(eds []*desc.EnumDescriptor) []EnumDesc {
+	var descs []EnumDesc
+	for _, ed := range eds {
+		descs = append(descs, &ProtoEnumDescriptor{ED: ed})
+	}
+	return descs
+}
+
 // ProtoServiceDescriptor implements the ServiceDesc interface.
 // Describes all information of an RPC service.
 type ProtoServiceDescriptor struct {
@@ -136,3 +154,163 @@ func (p *ProtoMessageDescriptor) GetFile() Desc {
 func (p *ProtoMessageDescriptor) GetFullyQualifiedName() string {
 	return p.MD.GetFullyQualifiedName()
 }
+
+// GetName implements the MessageDesc interface.
+func (p *ProtoMessageDescriptor) GetName() string {
+	return p.MD.GetName()
+}
+
+// GetFields implements the MessageDesc interface.
+func (p *ProtoMessageDescriptor) GetFields() []FieldDesc {
+	var descs []FieldDesc
+	for _, fd := range p.MD.GetFields() {
+		descs = append(descs, &ProtoFieldDescriptor{FD: fd})
+	}
+	return descs
+}
+
+// GetNestedMessageTypes implements the MessageDesc interface, the entries of map fields are excluded.
+func (p *ProtoMessageDescriptor) GetNestedMessageTypes() []MessageDesc {
+	var descs []MessageDesc
+	for _, md := range p.MD.GetNestedMessageTypes() {
+		if !md.IsMapEntry() {
+			descs = append(descs, &ProtoMessageDescriptor{MD: md})
+		}
+	}
+	return descs
+}
+
+// GetNestedEnumTypes implements the MessageDesc interface.
+func (p *ProtoMessageDescriptor) GetNestedEnumTypes() []EnumDesc {
+	return protoEnums(p.MD.GetNestedEnumTypes())
+}
+
+// GetSourceInfo implements the MessageDesc interface.
+func (p *ProtoMessageDescriptor) GetSourceInfo() SourceInfo {
+	return p.MD.GetSourceInfo()
+}
+
+// ProtoFieldDescriptor implements the FieldDesc interface.
+type ProtoFieldDescriptor struct {
+	FD *desc.FieldDescriptor
+}
+
+// GetName implements the FieldDesc interface.
+func (p *ProtoFieldDescriptor) GetName() string {
+	return p.FD.GetName()
+}
+
+// GetNumber implements the FieldDesc interface.
+func (p *ProtoFieldDescriptor) GetNumber() int32 {
+	return p.FD.GetNumber()
+}
+
+// GetType implements the FieldDesc interface.
+func (p *ProtoFieldDescriptor) GetType() string {
+	switch p.FD.GetType() {
+	case descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, descriptorpb.FieldDescriptorProto_TYPE_GROUP:
+		return "message"
+	default:
+		// Such as TYPE_INT32 and TYPE_ENUM.
+		return strings.ToLower(strings.TrimPrefix(p.FD.GetType().String(), "TYPE_"))
+	}
+}
+
+// GetTypeName implements the FieldDesc interface.
+func (p *ProtoFieldDescriptor) GetTypeName() string {
+	if md := p.FD.GetMessageType(); md != nil {
+		return md.GetFullyQualifiedName()
+	}
+	if ed := p.FD.GetEnumType(); ed != nil {
+		return ed.GetFullyQualifiedName()
+	}
+	return ""
+}
+
+// IsRepeated implements the FieldDesc interface, map fields are not counted as repeated ones.
+func (p *ProtoFieldDescriptor) IsRepeated() bool {
+	return p.FD.IsRepeated() && !p.FD.IsMap()
+}
+
+// IsMap implements the FieldDesc interface.
+func (p *ProtoFieldDescriptor) IsMap() bool {
+	return p.FD.IsMap()
+}
+
+// GetMapKeyType implements the FieldDesc interface.
+func (p *ProtoFieldDescriptor) GetMapKeyType() FieldDesc {
+	if !p.FD.IsMap() {
+		return nil
+	}
+	return &ProtoFieldDescriptor{FD: p.FD.GetMapKeyType()}
+}
+
+// GetMapValueType implements the FieldDesc interface.
+func (p *ProtoFieldDescriptor) GetMapValueType() FieldDesc {
+	if !p.FD.IsMap() {
+		return nil
+	}
+	return &ProtoFieldDescriptor{FD: p.FD.GetMapValueType()}
+}
+
+// GetOneOf implements the FieldDesc interface, the synthetic oneofs of proto3 optional fields are ignored.
+func (p *ProtoFieldDescriptor) GetOneOf() string {
+	if od := p.FD.GetOneOf(); od != nil && !od.IsSynthetic() {
+		return od.GetName()
+	}
+	return ""
+}
+
+// GetSourceInfo implements the FieldDesc interface.
+func (p *ProtoFieldDescriptor) GetSourceInfo() SourceInfo {
+	return p.FD.GetSourceInfo()
+}
+
+// ProtoEnumDescriptor implements the EnumDesc interface.
+type ProtoEnumDescriptor struct {
+	ED *desc.EnumDescriptor
+}
+
+// GetName implements the EnumDesc interface.
+func (p *ProtoEnumDescriptor) GetName() string {
+	return p.ED.GetName()
+}
+
+// GetFullyQualifiedName implements the EnumDesc interface.
+func (p *ProtoEnumDescriptor) GetFullyQualifiedName() string {
+	return p.ED.GetFullyQualifiedName()
+}
+
+// GetValues implements the EnumDesc interface.
+func (p *ProtoEnumDescriptor) GetValues() []EnumValueDesc {
+	var descs []EnumValueDesc
+	for _, vd := range p.ED.GetValues() {
+		descs = append(descs, &ProtoEnumValueDescriptor{VD: vd})
+	}
+	return descs
+}
+
+// GetSourceInfo implements the EnumDesc interface.
+func (p *ProtoEnumDescriptor) GetSourceInfo() SourceInfo {
+	return p.ED.GetSourceInfo()
+}
+
+// ProtoEnumValueDescriptor implements the EnumValueDesc interface.
+type ProtoEnumValueDescriptor struct {
+	VD *desc.EnumValueDescriptor
+}
+
+// GetName implements the EnumValueDesc interface.
+func (p *ProtoEnumValueDescriptor) GetName() string {
+	return p.VD.GetName()
+}
+
+// GetNumber implements the EnumValueDesc interface.
+func (p *ProtoEnumValueDescriptor) GetNumber() int32 {
+	return p.VD.GetNumber()
+}
+
+// GetSourceInfo implements the EnumValueDesc interface.
+func (p *ProtoEnumValueDescriptor) GetSourceInfo() SourceInfo {
+	return p.VD.GetSourceInfo()
+}
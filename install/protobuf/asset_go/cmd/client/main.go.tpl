@@ -20,6 +20,15 @@ import (
 	_ "{{ $domainName }}/{{ $groupName }}/trpc-filter/validation{{ $versionSuffix }}"
 	{{- end }}
 	pb "{{ trimright ";" $goPkgName }}"
+	{{- $importjson := false }}
+	{{- range .Services }}
+	{{- if .RPC }}
+	{{- $importjson = true }}
+	{{- end }}
+	{{- end }}
+	{{- if $importjson }}
+	"google.golang.org/protobuf/encoding/protojson"
+	{{- end }}
 	{{ range $.ImportsX }}
 		{{.Name}} "{{.Path}}"
 	{{ end }}
@@ -59,6 +68,13 @@ func call{{$svrNameCamelCase}}{{$rpcName}}() {
 		client.WithProtocol("{{$serviceProtocol}}"),
 	)
 	ctx := trpc.BackgroundContext()
+	req := &{{$rpcReqType}}{}
+	{{- with sample $method.RequestType $.FileDescriptor }}
+	// Fill the request with sample values.
+	if err := protojson.Unmarshal([]byte({{ printf "%q" . }}), req); err != nil {
+		log.Fatalf("err: %v", err)
+	}
+	{{- end }}
 {{- if and $method.ClientStreaming $method.ServerStreaming}}
 	// Example of using a bidirectional streaming client.
 	stream, err := proxy.{{$rpcName}}(ctx)
@@ -66,7 +82,7 @@ func call{{$svrNameCamelCase}}{{$rpcName}}() {
 		log.Fatalf("err: %v", err)
 	}
 	for i := 0; i < 5; i++ {
-		if err := stream.Send(&{{$rpcReqType}}{}); err != nil {
+		if err := stream.Send(req); err != nil {
 			log.Fatalf("err: %v", err)
 		}
 	}
@@ -90,7 +106,7 @@ func call{{$svrNameCamelCase}}{{$rpcName}}() {
 		log.Fatalf("err: %v", err)
 	}
 	for i := 0; i < 5; i++ {
-		if err := stream.Send(&{{$rpcReqType}}{}); err != nil {
+		if err := stream.Send(req); err != nil {
 			log.Fatalf("err: %v", err)
 		}
 	}
@@ -101,7 +117,7 @@ func call{{$svrNameCamelCase}}{{$rpcName}}() {
 	log.Debugf("client stream receive: %+v", rsp)
 {{- else if $method.ServerStreaming}}
 	// Example usage of server-side streaming.
-	stream, err := proxy.{{$rpcName}}(ctx, &{{$rpcReqType}}{})
+	stream, err := proxy.{{$rpcName}}(ctx, req)
 	if err != nil {
 		log.Fatalf("err: %v", err)
 	}
@@ -117,7 +133,7 @@ func call{{$svrNameCamelCase}}{{$rpcName}}() {
 	}
 {{- else}}
 	// Example usage of unary client.
-	reply, err := proxy.{{$rpcName}}(ctx, &{{$rpcReqType}}{})
+	reply, err := proxy.{{$rpcName}}(ctx, req)
 	if err != nil {
 		log.Fatalf("err: %v", err)
 	}
@@ -35,6 +35,9 @@ import (
 	_ "{{ $domainName }}/{{ $groupName }}/trpc-go{{ $versionSuffix }}/http"
     "go.uber.org/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	{{- if (index .Services .ServiceIndex).RPC }}
+	"google.golang.org/protobuf/encoding/protojson"
+	{{- end }}
 	pb "{{ trimright ";" $goPkgName }}"
     {{ range .ImportsX }}
     	{{.Name}} "{{.Path}}"
@@ -162,6 +165,10 @@ func Test_{{$svrNameCamelCase}}_{{$rpcName}}(t *testing.T) {
 	for i := 0; i < 5; i++ {
 
 		req := &{{$rpcReqType}}{}
+		{{- with sample $method.RequestType $.FileDescriptor }}
+		// Fill the request with sample values.
+		require.Nil(t, protojson.Unmarshal([]byte({{ printf "%q" . }}), req))
+		{{- end }}
 
 		// Output each input parameter (check t.Logf output, run `go test -v`).
 		t.Logf("{{$svrNameCamelCase}}_{{$rpcName}} req: %v", req)
@@ -260,6 +267,10 @@ func Test_{{$svrNameCamelCase}}_{{$rpcName}}(t *testing.T) {
 	for i := 0; i < 5; i++ {
 
 		req := &{{$rpcReqType}}{}
+		{{- with sample $method.RequestType $.FileDescriptor }}
+		// Fill the request with sample values.
+		require.Nil(t, protojson.Unmarshal([]byte({{ printf "%q" . }}), req))
+		{{- end }}
 
 		// Output each input parameter (check t.Logf output, run `go test -v`).
 		t.Logf("{{$svrNameCamelCase}}_{{$rpcName}} req: %v", req)
@@ -327,6 +338,10 @@ func Test_{{$svrNameCamelCase}}_{{$rpcName}}(t *testing.T) {
 
 	// Start writing unit test logic (for reference only, please modify as needed).
 	req := &{{$rpcReqType}}{}
+	{{- with sample $method.RequestType $.FileDescriptor }}
+	// Fill the request with sample values.
+	require.Nil(t, protojson.Unmarshal([]byte({{ printf "%q" . }}), req))
+	{{- end }}
 
 	// Output the input parameters (check t.Logf output, run `go test -v`).
 	t.Logf("{{$svrNameCamelCase}}_{{$rpcName}} req: %v", req)
@@ -370,11 +385,24 @@ func Test_{{$svrNameCamelCase|untitle}}Impl_{{$rpcName}}(t *testing.T) {
         req *{{$rpcReqType}}
         rsp *{{$rpcRspType}}
     }
+    {{- with sample $method.RequestType $.FileDescriptor }}
+    // Fill the request with sample values.
+    req := &{{$rpcReqType}}{}
+    if err := protojson.Unmarshal([]byte({{ printf "%q" . }}), req); err != nil {
+        t.Fatalf("unmarshal sample request err: %v", err)
+    }
+    {{- end }}
     tests := []struct {
         name    string
         args    args
         wantErr bool
     }{
+        {{- if sample $method.RequestType $.FileDescriptor }}
+        {
+            name: "sample request",
+            args: args{ctx: trpc.BackgroundContext(), req: req, rsp: &{{$rpcRspType}}{}},
+        },
+        {{- end }}
         // TODO: Add test cases.
     }
     for _, tt := range tests {
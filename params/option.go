@@ -23,6 +23,9 @@ type Option struct {
 
 	UseBaseName bool // Whether to pass protoc/flatc by the basename of "--protofile/--fbs" (default as true)
 
+	// PBCompiler is the compiler of the pb files, "protoc", or "builtin" which compiles in-process without protoc.
+	PBCompiler string
+
 	// Parses the MethodOption or the "//@alias=" alias in comments to replace the RPC in the .proto file.
 	AliasOn bool
 	// If enabled, client rpc name in stub will be replaced as alias name, default true.
@@ -30,12 +33,26 @@ type Option struct {
 	PerMethod            bool   // Whether to support splitting files by method.
 	OutputDir            string // Project output path.
 	Force                bool   // Force write.
+	// DryRun generates into a scratch directory and reports the diff against OutputDir instead of writing into it.
+	DryRun bool
+	// NoHooks skips the pre and post hooks of the template manifests.
+	NoHooks bool
+	// RunHooks runs the hooks of the template layers other than the built-in assets without confirmation.
+	RunHooks bool
+	// Update merges newly generated RPCs into the existing service implementations and tests instead of overwriting.
+	Update bool
+	// Lockfile decides whether to write trpc-gen.lock, which records how the code is generated, into OutputDir.
+	Lockfile bool
 
 	CustomAPPName    string // APPName is the custom app name provided by user.
 	CustomServerName string // ServerName is the custom server name provided by user.
 
 	DescriptorSetIn string // Descriptor file specified by "--descriptor_set_in".
 
+	// Overlays are the template directories layered on top of Assetdir, ordered from the highest priority,
+	// whose files override the ones at the same relative paths beneath.
+	Overlays []string
+
 	// template option
 	Assetdir string         // Service template path.
 	Language string         // Development language, such as Go.
@@ -52,6 +69,9 @@ type Option struct {
 	KVs  map[string]interface{}
 	Envs map[string]string
 
+	// PluginSettings maps plugin name to the settings block configured in the plugins section of trpc.yaml.
+	PluginSettings map[string]map[string]interface{}
+
 	// gomod option
 	GoMod         string // go.mod specified in the current project.
 	GoModEx       string // Module extracted from go.mod.
@@ -108,9 +128,16 @@ type Option struct {
 	// If Sync is true, push to the remote Git repository address.
 	// The default is the address specified in the "go_package" option of the .proto file.
 	Remote string
+	// If Sync is true, push to the branch instead of the default one, whose name is generated if it is "auto".
+	Branch string
+	// If Sync is true, write the patch of the commit into the file instead of pushing, "-" for the standard output.
+	Patch string
 	// Whether to tag the uploaded repository.
 	NewTag bool
-	// If Sync is true, set the Git tag. If not specified, the default is in the format "v1.1.1",
-	// with the latest tag incremented by 1, and carried over in base 100.
+	// If Sync is true, set the Git tag. If not specified, the latest semantic version tag is bumped
+	// by the API changes since then, or incremented in base 100 by the versioning setting of sync_git.
+	// It is also the version published into GoProxy.
 	Tag string
+	// Publish the stubs as a module into the directory of the GOPROXY layout, which can be used by GOPROXY=file://.
+	GoProxy string
 }
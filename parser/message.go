@@ -0,0 +1,223 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	protov1 "github.com/golang/protobuf/proto"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+
+	"trpc.group/trpc-go/trpc-cmdline/descriptor"
+	"trpc.group/trpc-go/trpc-cmdline/util/log"
+)
+
+// fillMessages fills the messages and enums defined in the file, the nested ones are filled into their parents.
+func fillMessages(fd descriptor.Desc, nfd *descriptor.FileDescriptor) error {
+	for _, md := range fd.GetMessageTypes() {
+		// GetMessageTypes of protobuf includes the nested messages, which are excluded from the top level ones.
+		if pmd, ok := md.(*descriptor.ProtoMessageDescriptor); ok && pmd.MD.GetParent() != pmd.MD.GetFile() {
+			continue
+		}
+		nmd, err := newMessageDescriptor(md)
+		if err != nil {
+			return err
+		}
+		nfd.Messages = append(nfd.Messages, nmd)
+	}
+	enums, err := newEnumDescriptors(fd.GetEnumTypes())
+	if err != nil {
+		return err
+	}
+	nfd.Enums = enums
+	return nil
+}
+
+func newMessageDescriptor(md descriptor.MessageDesc) (*descriptor.MessageDescriptor, error) {
+	nmd := &descriptor.MessageDescriptor{
+		Name:               md.GetName(),
+		FullyQualifiedName: md.GetFullyQualifiedName(),
+		LeadingComments:    formatComments(md.GetSourceInfo().GetLeadingComments()),
+		TrailingComments:   formatComments(md.GetSourceInfo().GetTrailingComments()),
+	}
+	if pmd, ok := md.(*descriptor.ProtoMessageDescriptor); ok {
+		opts, err := buildProtoOptions(pmd.MD.GetOptions(), pmd.MD.GetFile())
+		if err != nil {
+			return nil, err
+		}
+		nmd.Options = opts
+	}
+	oneofs := make(map[string]*descriptor.OneofDescriptor)
+	for _, fd := range md.GetFields() {
+		nfd, err := newFieldDescriptor(fd)
+		if err != nil {
+			return nil, err
+		}
+		nmd.Fields = append(nmd.Fields, nfd)
+		if nfd.Oneof == "" {
+			continue
+		}
+		od, ok := oneofs[nfd.Oneof]
+		if !ok {
+			od = &descriptor.OneofDescriptor{Name: nfd.Oneof}
+			oneofs[nfd.Oneof] = od
+			nmd.Oneofs = append(nmd.Oneofs, od)
+		}
+		od.Fields = append(od.Fields, nfd)
+	}
+	for _, md := range md.GetNestedMessageTypes() {
+		nested, err := newMessageDescriptor(md)
+		if err != nil {
+			return nil, err
+		}
+		nmd.Messages = append(nmd.Messages, nested)
+	}
+	enums, err := newEnumDescriptors(md.GetNestedEnumTypes())
+	if err != nil {
+		return nil, err
+	}
+	nmd.Enums = enums
+	return nmd, nil
+}
+
+func newFieldDescriptor(fd descriptor.FieldDesc) (*descriptor.FieldDescriptor, error) {
+	nfd := &descriptor.FieldDescriptor{
+		Name:             fd.GetName(),
+		Number:           fd.GetNumber(),
+		Type:             fd.GetType(),
+		TypeName:         fd.GetTypeName(),
+		Repeated:         fd.IsRepeated(),
+		Map:              fd.IsMap(),
+		Oneof:            fd.GetOneOf(),
+		LeadingComments:  formatComments(fd.GetSourceInfo().GetLeadingComments()),
+		TrailingComments: formatComments(fd.GetSourceInfo().GetTrailingComments()),
+	}
+	if fd.IsMap() {
+		var err error
+		if nfd.MapKey, err = newFieldDescriptor(fd.GetMapKeyType()); err != nil {
+			return nil, err
+		}
+		if nfd.MapValue, err = newFieldDescriptor(fd.GetMapValueType()); err != nil {
+			return nil, err
+		}
+	}
+	if pfd, ok := fd.(*descriptor.ProtoFieldDescriptor); ok {
+		opts, err := buildProtoOptions(pfd.FD.GetOptions(), pfd.FD.GetFile())
+		if err != nil {
+			return nil, err
+		}
+		nfd.Options = opts
+	}
+	return nfd, nil
+}
+
+func newEnumDescriptors(eds []descriptor.EnumDesc) ([]*descriptor.EnumDescriptor, error) {
+	var neds []*descriptor.EnumDescriptor
+	for _, ed := range eds {
+		ned := &descriptor.EnumDescriptor{
+			Name:               ed.GetName(),
+			FullyQualifiedName: ed.GetFullyQualifiedName(),
+			LeadingComments:    formatComments(ed.GetSourceInfo().GetLeadingComments()),
+			TrailingComments:   formatComments(ed.GetSourceInfo().GetTrailingComments()),
+		}
+		if ped, ok := ed.(*descriptor.ProtoEnumDescriptor); ok {
+			opts, err := buildProtoOptions(ped.ED.GetOptions(), ped.ED.GetFile())
+			if err != nil {
+				return nil, err
+			}
+			ned.Options = opts
+		}
+		for _, vd := range ed.GetValues() {
+			nvd := &descriptor.EnumValueDescriptor{
+				Name:             vd.GetName(),
+				Number:           vd.GetNumber(),
+				LeadingComments:  formatComments(vd.GetSourceInfo().GetLeadingComments()),
+				TrailingComments: formatComments(vd.GetSourceInfo().GetTrailingComments()),
+			}
+			if pvd, ok := vd.(*descriptor.ProtoEnumValueDescriptor); ok {
+				opts, err := buildProtoOptions(pvd.VD.GetOptions(), pvd.VD.GetFile())
+				if err != nil {
+					return nil, err
+				}
+				nvd.Options = opts
+			}
+			ned.Values = append(ned.Values, nvd)
+		}
+		neds = append(neds, ned)
+	}
+	return neds, nil
+}
+
+// formatComments formats the comments the same as the ones of RPCDescriptor,
+// so that each line of them can be prefixed by "// " in templates.
+func formatComments(comments string) string {
+	return strings.Replace(strings.TrimSpace(comments), "\n", "\n// ", -1)
+}
+
+// buildProtoOptions builds the options of protobuf into KV pairs in the form of JSON.
+// The extensions, which are parsed as unknown fields, are decoded by the ones defined in the file
+// and its dependencies, and keyed by their fully qualified names, such as validate.rules.
+// The extensions are left out if they fail to be decoded.
+func buildProtoOptions(opts protov1.Message, fd *desc.FileDescriptor) (map[string]interface{}, error) {
+	// Options not set are nil pointers.
+	if opts == nil || !protov1.MessageReflect(opts).IsValid() {
+		return nil, nil
+	}
+	b, err := protov1.Marshal(opts)
+	if err != nil || len(b) == 0 {
+		return nil, err
+	}
+	m, err := decodeProtoOptions(opts, b, fd)
+	if err == nil {
+		return m, nil
+	}
+	log.Debug("decode options with extensions err: %v", err)
+	v, err := json.Marshal(opts)
+	if err != nil {
+		return nil, err
+	}
+	m = make(map[string]interface{})
+	if err := json.Unmarshal(v, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func decodeProtoOptions(opts protov1.Message, b []byte, fd *desc.FileDescriptor) (map[string]interface{}, error) {
+	md, err := desc.LoadMessageDescriptorForMessage(opts)
+	if err != nil {
+		return nil, err
+	}
+	er := &dynamic.ExtensionRegistry{}
+	er.AddExtensionsFromFileRecursively(fd)
+	msg := dynamic.NewMessageWithExtensionRegistry(md, er)
+	if err := msg.Unmarshal(b); err != nil {
+		return nil, err
+	}
+	s, err := msg.MarshalJSONPB(&jsonpb.Marshaler{OrigName: true})
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]interface{})
+	if err := json.Unmarshal(s, &m); err != nil {
+		return nil, err
+	}
+	for k, v := range m {
+		// Extensions are keyed by [name] in JSON.
+		if strings.HasPrefix(k, "[") && strings.HasSuffix(k, "]") {
+			delete(m, k)
+			m[strings.Trim(k, "[]")] = v
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,87 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFillMessages(t *testing.T) {
+	t.Run("protobuf", func(t *testing.T) {
+		fd, err := ParseProtoFile("message.proto", []string{"testcase/message", "../install/submodules/trpc-protocol"})
+		require.Nil(t, err)
+		require.Len(t, fd.Messages, 2)
+		require.Len(t, fd.Enums, 1)
+		require.Equal(t, "Status of users.", fd.Enums[0].LeadingComments)
+		require.Equal(t, "STATUS_OK", fd.Enums[0].Values[1].Name)
+		require.Equal(t, int32(1), fd.Enums[0].Values[1].Number)
+		require.Equal(t, "Normal.", fd.Enums[0].Values[1].TrailingComments)
+
+		user := fd.FindMessage("trpc.test.message.User")
+		require.NotNil(t, user)
+		require.Equal(t, "User", user.Name)
+		require.Equal(t, "User is a user.", user.LeadingComments)
+		require.Equal(t, true, user.Options["deprecated"])
+		require.Len(t, user.Fields, 9)
+
+		id := user.Fields[0]
+		require.Equal(t, "id", id.Name)
+		require.Equal(t, int32(1), id.Number)
+		require.Equal(t, "uint64", id.Type)
+		require.Empty(t, id.TypeName)
+		require.Equal(t, "ID of the user.", id.LeadingComments)
+		require.Equal(t, map[string]interface{}{"string": map[string]interface{}{"min_len": "1"}},
+			user.Fields[1].Options["trpc.validate.rules"])
+		require.Equal(t, "enum", user.Fields[2].Type)
+		require.Equal(t, "trpc.test.message.Status", user.Fields[2].TypeName)
+		require.True(t, user.Fields[3].Repeated)
+
+		addresses := user.Fields[4]
+		require.True(t, addresses.Map)
+		require.False(t, addresses.Repeated)
+		require.Equal(t, "string", addresses.MapKey.Type)
+		require.Equal(t, "trpc.test.message.User.Address", addresses.MapValue.TypeName)
+
+		require.Len(t, user.Oneofs, 1, "the synthetic oneof of the optional field is excluded")
+		require.Equal(t, "contact", user.Oneofs[0].Name)
+		require.Equal(t, []string{"phone", "email"},
+			[]string{user.Oneofs[0].Fields[0].Name, user.Oneofs[0].Fields[1].Name})
+		require.Empty(t, user.Fields[7].Oneof)
+		require.Equal(t, true, user.Fields[7].Options["deprecated"])
+		require.Equal(t, "message", user.Fields[8].Type)
+
+		require.Len(t, user.Messages, 1, "the map entry is excluded")
+		require.Same(t, user.Messages[0], fd.FindMessage("trpc.test.message.User.Address"))
+		require.NotNil(t, fd.FindEnum("trpc.test.message.User.Level"))
+		require.Nil(t, fd.FindMessage("trpc.test.message.Nope"))
+	})
+	t.Run("flatbuffers", func(t *testing.T) {
+		fd, err := ParseFlatbuffers("message.fbs", []string{"testcase/message"})
+		require.Nil(t, err)
+		require.Len(t, fd.Messages, 2)
+		require.Len(t, fd.Enums, 1)
+		require.Equal(t, ".trpc.test.message.Status", fd.Enums[0].FullyQualifiedName)
+		require.Equal(t, "OK", fd.Enums[0].Values[1].Name)
+
+		user := fd.FindMessage(".trpc.test.message.User")
+		require.NotNil(t, user)
+		require.Len(t, user.Fields, 4)
+		require.Equal(t, "ulong", user.Fields[0].Type)
+		require.Equal(t, int32(1), user.Fields[1].Number)
+		require.Equal(t, "enum", user.Fields[1].Type)
+		require.Equal(t, ".trpc.test.message.Status", user.Fields[1].TypeName)
+		require.True(t, user.Fields[2].Repeated)
+		require.Equal(t, "string", user.Fields[2].Type)
+		require.Equal(t, "message", user.Fields[3].Type)
+		require.Equal(t, ".trpc.test.message.Address", user.Fields[3].TypeName)
+	})
+}
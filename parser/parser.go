@@ -57,14 +57,6 @@ func Parse(protofile string, dirs []string, typ config.IDLType, opts ...Option)
 
 // LoadDescriptorSet loads the file descriptor from the given file name.
 func LoadDescriptorSet(descriptorSetInFile, protofile string, opts ...Option) (*descriptor.FileDescriptor, error) {
-	option := &options{
-		aliasOn:  false,
-		language: "go",
-		rpcOnly:  false,
-	}
-	for _, o := range opts {
-		o(option)
-	}
 	bytes, err := os.ReadFile(descriptorSetInFile)
 	if err != nil {
 		return nil, fmt.Errorf("os.ReadFile load descriptor_set_in err: %w", err)
@@ -73,14 +65,36 @@ func LoadDescriptorSet(descriptorSetInFile, protofile string, opts ...Option) (*
 	if err := proto.Unmarshal(bytes, dbpFileDescriptorSet); err != nil {
 		return nil, err
 	}
-	fileDescriptorMap, err := desc.CreateFileDescriptors(dbpFileDescriptorSet.File)
+	fd, err := LoadFileDescriptorProtos(dbpFileDescriptorSet.File, protofile, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("load descriptor_set_in file %s err: %w", descriptorSetInFile, err)
+	}
+	return fd, nil
+}
+
+// LoadFileDescriptorProtos builds the file descriptor of protofile from the given raw file descriptors,
+// such as the ones inside a descriptor set or a protoc plugin request.
+// files must contain protofile and all of its dependencies.
+func LoadFileDescriptorProtos(
+	files []*descriptorpb.FileDescriptorProto,
+	protofile string,
+	opts ...Option,
+) (*descriptor.FileDescriptor, error) {
+	option := &options{
+		aliasOn:  false,
+		language: "go",
+		rpcOnly:  false,
+	}
+	for _, o := range opts {
+		o(option)
+	}
+	fileDescriptorMap, err := desc.CreateFileDescriptors(files)
 	if err != nil {
 		return nil, err
 	}
 	d, ok := fileDescriptorMap[protofile]
 	if !ok {
-		return nil, fmt.Errorf(
-			"protofile %s not found in descriptor_set_in file %s", protofile, descriptorSetInFile)
+		return nil, fmt.Errorf("protofile %s not found in the file descriptors", protofile)
 	}
 	wd, err := os.Getwd()
 	if err != nil {
@@ -149,6 +163,8 @@ func convertFileDescriptor(
 	mustNilError(fillAppServerName(fd, fileDescriptor, option))
 	// SetMessageTypes sets the definitions of the request and response types of the RPC
 	mustNilError(fillRPCMessageTypes(fd, fileDescriptor))
+	// Set messages and enums defined in the file
+	mustNilError(fillMessages(fd, fileDescriptor))
 
 	fileDescriptor.RelatvieFilePath = protofile
 	if filepath.IsAbs(protofile) {
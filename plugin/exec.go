@@ -0,0 +1,281 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"trpc.group/trpc-go/trpc-cmdline/config"
+	"trpc.group/trpc-go/trpc-cmdline/descriptor"
+	"trpc.group/trpc-go/trpc-cmdline/params"
+	"trpc.group/trpc-go/trpc-cmdline/util/log"
+)
+
+// ExecProtocolVersion is the version of the JSON protocol spoken with external plugins.
+const ExecProtocolVersion = "v1"
+
+// Exec is an external executable plugin.
+//
+// Like protoc plugins, the executable reads an ExecRequest encoded as JSON from stdin,
+// and writes an ExecResponse encoded as JSON to stdout.
+// The files returned are applied to the output directory by trpc-cmdline.
+type Exec struct {
+	name     string
+	path     string
+	args     []string
+	settings map[string]interface{}
+}
+
+// NewExec creates an external plugin from its configuration.
+func NewExec(cfg *config.PluginConfig) *Exec {
+	return &Exec{
+		name:     cfg.Name,
+		path:     cfg.Exec,
+		args:     cfg.Args,
+		settings: cfg.Settings,
+	}
+}
+
+// ExecRequest is sent to the external plugin through stdin.
+type ExecRequest struct {
+	Version string `json:"version"` // Protocol version, see ExecProtocolVersion.
+	// FileDescriptor is the parsed IDL, FileDescriptor.FD is omitted.
+	FileDescriptor *descriptor.FileDescriptor `json:"file_descriptor"`
+	Options        *ExecOptions               `json:"options"`
+	OutputDir      string                     `json:"output_dir"`         // Absolute path of the output directory.
+	Settings       map[string]interface{}     `json:"settings,omitempty"` // Settings block from trpc.yaml.
+	ToolVersion    string                     `json:"tool_version"`       // Version of trpc-cmdline.
+}
+
+// ExecOptions contains the params.Option fields passed to the external plugin.
+type ExecOptions struct {
+	Protofile     string                 `json:"protofile"`
+	Protodirs     []string               `json:"protodirs"`
+	IDLType       string                 `json:"idl_type"`
+	Language      string                 `json:"language"`
+	Protocol      string                 `json:"protocol"`
+	RPCOnly       bool                   `json:"rpc_only"`
+	PerMethod     bool                   `json:"per_method"`
+	AliasOn       bool                   `json:"alias_on"`
+	GoMod         string                 `json:"go_mod"`
+	GoVersion     string                 `json:"go_version"`
+	TRPCGoVersion string                 `json:"trpc_go_version"`
+	Domain        string                 `json:"domain"`
+	GroupName     string                 `json:"group_name"`
+	VersionSuffix string                 `json:"version_suffix"`
+	KVs           map[string]interface{} `json:"kvs,omitempty"`
+}
+
+// ExecResponse is read from the stdout of the external plugin.
+type ExecResponse struct {
+	// Error is set if the plugin failed, no file will be applied in this case.
+	Error       string            `json:"error,omitempty"`
+	Files       []*ExecFile       `json:"files,omitempty"`
+	Diagnostics []*ExecDiagnostic `json:"diagnostics,omitempty"`
+}
+
+// ExecFile is a file write or delete requested by the external plugin.
+type ExecFile struct {
+	Name    string `json:"name"`    // Slash separated path relative to the output directory.
+	Content string `json:"content"` // File content, ignored if Delete is true.
+	Delete  bool   `json:"delete"`  // Whether to delete the file.
+}
+
+// Diagnostic levels of ExecDiagnostic.
+const (
+	DiagnosticInfo    = "info"
+	DiagnosticWarning = "warning"
+	DiagnosticError   = "error"
+)
+
+// ExecDiagnostic is a message reported by the external plugin.
+type ExecDiagnostic struct {
+	Level   string `json:"level"` // One of info, warning and error.
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// String returns the diagnostic in the form of file:line: message.
+func (d *ExecDiagnostic) String() string {
+	switch {
+	case d.File != "" && d.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", d.File, d.Line, d.Message)
+	case d.File != "":
+		return fmt.Sprintf("%s: %s", d.File, d.Message)
+	default:
+		return d.Message
+	}
+}
+
+// Name returns plugin's name.
+func (p *Exec) Name() string {
+	return p.name
+}
+
+// Check always runs the external plugin, since it is configured explicitly.
+func (p *Exec) Check(fd *descriptor.FileDescriptor, _ *params.Option) bool {
+	return fd != nil
+}
+
+// Run runs the external plugin and applies the files it returns.
+func (p *Exec) Run(fd *descriptor.FileDescriptor, opt *params.Option) error {
+	outputDir, err := filepath.Abs(opt.OutputDir)
+	if err != nil {
+		return fmt.Errorf("get absolute path of %s err: %w", opt.OutputDir, err)
+	}
+	req, err := json.Marshal(p.newRequest(fd, opt, outputDir))
+	if err != nil {
+		return fmt.Errorf("json marshal request err: %w", err)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(p.path, p.args...)
+	cmd.Dir = outputDir
+	cmd.Stdin = bytes.NewReader(req)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	log.Debug("run external plugin %s: %s %s", p.name, p.path, strings.Join(p.args, " "))
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("run %s err: %w, stderr: %s", p.path, err, stderr.String())
+	}
+	if stderr.Len() != 0 {
+		log.Debug("external plugin %s stderr: %s", p.name, stderr.String())
+	}
+	rsp := &ExecResponse{}
+	if err := json.Unmarshal(stdout.Bytes(), rsp); err != nil {
+		return fmt.Errorf("json unmarshal response of %s err: %w", p.path, err)
+	}
+	return applyExecResponse(p.name, outputDir, rsp)
+}
+
+func (p *Exec) newRequest(fd *descriptor.FileDescriptor, opt *params.Option, outputDir string) *ExecRequest {
+	// The IDL interface carries the raw descriptors of protoreflect/fbs, which are not meant to be serialized.
+	nfd := *fd
+	nfd.FD = nil
+	return &ExecRequest{
+		Version:        ExecProtocolVersion,
+		FileDescriptor: &nfd,
+		Options: &ExecOptions{
+			Protofile:     opt.Protofile,
+			Protodirs:     opt.Protodirs,
+			IDLType:       opt.IDLType.String(),
+			Language:      opt.Language,
+			Protocol:      opt.Protocol,
+			RPCOnly:       opt.RPCOnly,
+			PerMethod:     opt.PerMethod,
+			AliasOn:       opt.AliasOn,
+			GoMod:         opt.GoMod,
+			GoVersion:     opt.GoVersion,
+			TRPCGoVersion: opt.TRPCGoVersion,
+			Domain:        opt.Domain,
+			GroupName:     opt.GroupName,
+			VersionSuffix: opt.VersionSuffix,
+			KVs:           opt.KVs,
+		},
+		OutputDir:   outputDir,
+		Settings:    jsonCompatible(p.settings).(map[string]interface{}),
+		ToolVersion: config.TRPCCliVersion,
+	}
+}
+
+// applyExecResponse reports the diagnostics and applies the files returned by an external plugin.
+// Nothing is written if the plugin reports an error.
+func applyExecResponse(name, outputDir string, rsp *ExecResponse) error {
+	var errs []string
+	for _, d := range rsp.Diagnostics {
+		switch d.Level {
+		case DiagnosticError:
+			log.Error("[%s] %s", name, d)
+			errs = append(errs, d.String())
+		case DiagnosticWarning:
+			log.Info("[%s] warning: %s", name, d)
+		default:
+			log.Debug("[%s] %s", name, d)
+		}
+	}
+	if rsp.Error != "" {
+		errs = append([]string{rsp.Error}, errs...)
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("external plugin %s failed: %s", name, strings.Join(errs, "; "))
+	}
+	for _, f := range rsp.Files {
+		fp, err := resolveExecFile(outputDir, f.Name)
+		if err != nil {
+			return err
+		}
+		if f.Delete {
+			if err := os.RemoveAll(fp); err != nil {
+				return fmt.Errorf("delete %s err: %w", fp, err)
+			}
+			log.Debug("[%s] delete %s", name, fp)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(fp), os.ModePerm); err != nil {
+			return fmt.Errorf("mkdir for %s err: %w", fp, err)
+		}
+		if err := os.WriteFile(fp, []byte(f.Content), 0644); err != nil {
+			return fmt.Errorf("write %s err: %w", fp, err)
+		}
+		log.Debug("[%s] write %s", name, fp)
+	}
+	return nil
+}
+
+// resolveExecFile returns the path of the file inside outputDir, files outside outputDir are rejected.
+func resolveExecFile(outputDir, name string) (string, error) {
+	if name == "" {
+		return "", errors.New("external plugin returns a file with empty name")
+	}
+	if filepath.IsAbs(name) || path.IsAbs(name) {
+		return "", fmt.Errorf("external plugin returns an absolute file name %s", name)
+	}
+	fp := filepath.Join(outputDir, filepath.FromSlash(name))
+	rel, err := filepath.Rel(outputDir, fp)
+	if err != nil || rel == "." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || rel == ".." {
+		return "", fmt.Errorf("external plugin returns file %s outside of the output directory", name)
+	}
+	return fp, nil
+}
+
+// jsonCompatible converts the map[interface{}]interface{} decoded by yaml into map[string]interface{},
+// so that the value can be encoded as JSON.
+func jsonCompatible(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(vv))
+		for k, val := range vv {
+			m[fmt.Sprint(k)] = jsonCompatible(val)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(vv))
+		for k, val := range vv {
+			m[k] = jsonCompatible(val)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(vv))
+		for i, val := range vv {
+			l[i] = jsonCompatible(val)
+		}
+		return l
+	default:
+		return v
+	}
+}
@@ -0,0 +1,97 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package plugin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-cmdline/config"
+	"trpc.group/trpc-go/trpc-cmdline/descriptor"
+	"trpc.group/trpc-go/trpc-cmdline/params"
+)
+
+func TestExec_Run(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script plugin is not supported on windows")
+	}
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	require.Nil(t, os.MkdirAll(out, os.ModePerm))
+	require.Nil(t, os.WriteFile(filepath.Join(out, "obsolete.go"), []byte("package main"), 0644))
+
+	rsp, err := json.Marshal(&ExecResponse{
+		Files: []*ExecFile{
+			{Name: "extra/hello.txt", Content: "hello"},
+			{Name: "obsolete.go", Delete: true},
+		},
+		Diagnostics: []*ExecDiagnostic{{Level: DiagnosticWarning, File: "hello.proto", Line: 1, Message: "hi"}},
+	})
+	require.Nil(t, err)
+	script := filepath.Join(dir, "plugin.sh")
+	require.Nil(t, os.WriteFile(script, []byte("#!/bin/sh\ncat > request.json\necho '"+string(rsp)+"'\n"), 0755))
+
+	p := NewExec(&config.PluginConfig{
+		Name:     "script",
+		Exec:     script,
+		Settings: map[string]interface{}{"nested": map[interface{}]interface{}{"k": "v"}},
+	})
+	require.Equal(t, "script", p.Name())
+	fd := &descriptor.FileDescriptor{PackageName: "trpc.app.server"}
+	require.True(t, p.Check(fd, &params.Option{}))
+	require.Nil(t, p.Run(fd, &params.Option{OutputDir: out, Language: "go"}))
+
+	b, err := os.ReadFile(filepath.Join(out, "extra/hello.txt"))
+	require.Nil(t, err)
+	require.Equal(t, "hello", string(b))
+	_, err = os.Stat(filepath.Join(out, "obsolete.go"))
+	require.True(t, os.IsNotExist(err))
+
+	b, err = os.ReadFile(filepath.Join(out, "request.json"))
+	require.Nil(t, err)
+	req := &ExecRequest{}
+	require.Nil(t, json.Unmarshal(b, req))
+	require.Equal(t, ExecProtocolVersion, req.Version)
+	require.Equal(t, "trpc.app.server", req.FileDescriptor.PackageName)
+	require.Equal(t, "go", req.Options.Language)
+	require.Equal(t, out, req.OutputDir)
+	require.Equal(t, map[string]interface{}{"k": "v"}, req.Settings["nested"])
+}
+
+func TestApplyExecResponse(t *testing.T) {
+	dir := t.TempDir()
+	t.Run("error reported", func(t *testing.T) {
+		err := applyExecResponse("p", dir, &ExecResponse{
+			Files:       []*ExecFile{{Name: "a.txt", Content: "a"}},
+			Diagnostics: []*ExecDiagnostic{{Level: DiagnosticError, Message: "bad input"}},
+		})
+		require.Contains(t, err.Error(), "bad input")
+		_, err = os.Stat(filepath.Join(dir, "a.txt"))
+		require.True(t, os.IsNotExist(err))
+	})
+	t.Run("file outside of output dir", func(t *testing.T) {
+		for _, name := range []string{"../a.txt", "/etc/passwd", "", "."} {
+			err := applyExecResponse("p", dir, &ExecResponse{Files: []*ExecFile{{Name: name}}})
+			require.NotNil(t, err, name)
+		}
+	})
+}
+
+func TestChain_Exec(t *testing.T) {
+	chain, err := Chain("go", []*config.PluginConfig{{Name: "my_plugin", Exec: "my-plugin"}}, nil)
+	require.Nil(t, err)
+	require.Len(t, chain, 1)
+	require.IsType(t, &Exec{}, chain[0])
+}
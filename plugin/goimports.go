@@ -35,22 +35,30 @@ func (p *GoImports) Check(_ *descriptor.FileDescriptor, opt *params.Option) bool
 }
 
 // Run runs goimports action.
-func (p *GoImports) Run(_ *descriptor.FileDescriptor, _ *params.Option) error {
+//
+// Supported settings:
+//
+//	local: put imports beginning with this string after 3rd-party packages, same as `goimports -local`.
+func (p *GoImports) Run(_ *descriptor.FileDescriptor, opt *params.Option) error {
 	goimports, err := exec.LookPath("goimports")
 	if err != nil {
 		return fmt.Errorf("goimports not found, install it first")
 	}
+	var args []string
+	if local := settingString(opt, p.Name(), "local"); local != "" {
+		args = append(args, "-local", local)
+	}
 
 	// Under some rare circumstances, we need run goimports multiple times to
 	// prevent duplicate imports.
 	const maxGoImports = 5
 	for i := 0; i < maxGoImports; i++ {
-		buf, err := exec.Command(goimports, "-w", ".").CombinedOutput()
+		buf, err := exec.Command(goimports, append(args, "-w", ".")...).CombinedOutput()
 		if err != nil {
 			log.Error("run goimports -w . error: %+v,\n%s", err, string(buf))
 			return err
 		}
-		buf, err = exec.Command(goimports, "-d", ".").CombinedOutput()
+		buf, err = exec.Command(goimports, append(args, "-d", ".")...).CombinedOutput()
 		if err != nil {
 			log.Error("run goimports -d . error: %+v,\n%s", err, string(buf))
 			return err
@@ -21,6 +21,7 @@ var Plugins = []Plugin{
 	&Validate{}, // protoc-gen-secv
 	sync.NewGit(sync.DefaultFileManager, sync.DefaultGitManager,
 		sync.AuthSupplier), // sync stub to git repository
+	sync.NewGoProxy(), // publish stub into GOPROXY directory
 }
 
 // PluginsExt is the language-specific plugin chain.
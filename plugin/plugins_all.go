@@ -19,8 +19,9 @@ var Plugins = []Plugin{
 	&Swagger{},  // swagger apidoc
 	&OpenAPI{},  // openapi apidoc
 	&Validate{}, // protoc-gen-secv
-	sync.NewSyncGit(sync.NewDefaultFileManager(), sync.NewDefaultGitManager(),
+	sync.NewGit(sync.DefaultFileManager, sync.DefaultGitManager,
 		sync.AuthSupplier), // sync stub to git repository
+	sync.NewGoProxy(), // publish stub into GOPROXY directory
 }
 
 // PluginsExt is the language-specific plugin chain.
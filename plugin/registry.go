@@ -0,0 +1,114 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package plugin
+
+import (
+	"fmt"
+
+	"trpc.group/trpc-go/trpc-cmdline/config"
+	"trpc.group/trpc-go/trpc-cmdline/params"
+)
+
+// registry maps plugin names to plugins.
+var registry = make(map[string]Plugin)
+
+func init() {
+	for _, p := range Plugins {
+		Register(p)
+	}
+	for _, ps := range PluginsExt {
+		for _, p := range ps {
+			Register(p)
+		}
+	}
+}
+
+// Register registers the plugin by its name, a plugin registered earlier with the same name will be replaced.
+func Register(p Plugin) {
+	registry[p.Name()] = p
+}
+
+// Get returns the plugin registered with the given name.
+func Get(name string) (Plugin, bool) {
+	p, ok := registry[name]
+	return p, ok
+}
+
+// Chain returns the plugin chain of the given language.
+//
+// The order and the enable/disable state of the plugins are decided by cfgs,
+// and the settings of each plugin are recorded into settings, keyed by plugin name.
+// An external plugin is created for each configuration with Exec specified.
+// The built-in chain, i.e. Plugins followed by PluginsExt[lang], is returned if cfgs is empty.
+func Chain(lang string, cfgs []*config.PluginConfig, settings map[string]map[string]interface{}) ([]Plugin, error) {
+	if len(cfgs) == 0 {
+		return append(append([]Plugin{}, Plugins...), PluginsExt[lang]...), nil
+	}
+	var chain []Plugin
+	for _, cfg := range cfgs {
+		if cfg.Disabled {
+			continue
+		}
+		var p Plugin = NewExec(cfg)
+		if cfg.Exec == "" {
+			var ok bool
+			if p, ok = Get(cfg.Name); !ok {
+				return nil, fmt.Errorf("plugin %s for language %s is not registered", cfg.Name, lang)
+			}
+		}
+		if settings != nil && cfg.Settings != nil {
+			settings[cfg.Name] = cfg.Settings
+		}
+		chain = append(chain, p)
+	}
+	return chain, nil
+}
+
+// Filter returns the plugins in chain whose names are listed in names, the order of chain is kept.
+func Filter(chain []Plugin, names ...string) []Plugin {
+	var filtered []Plugin
+	for _, p := range chain {
+		for _, name := range names {
+			if p.Name() == name {
+				filtered = append(filtered, p)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// NonIDLPlugins lists the plugins allowed for projects generated without IDL, such as kafka, http.
+var NonIDLPlugins = []string{
+	"goimports",
+	"gofmt",
+}
+
+// setting returns the value of key inside the settings block of the named plugin.
+func setting(opt *params.Option, name, key string) (interface{}, bool) {
+	if opt == nil || opt.PluginSettings == nil {
+		return nil, false
+	}
+	v, ok := opt.PluginSettings[name][key]
+	return v, ok
+}
+
+// settingString returns the string value of key inside the settings block of the named plugin.
+func settingString(opt *params.Option, name, key string) string {
+	v, ok := setting(opt, name, key)
+	if !ok {
+		return ""
+	}
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Sprint(v)
+	}
+	return s
+}
@@ -0,0 +1,88 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package plugin
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"trpc.group/trpc-go/trpc-cmdline/config"
+	"trpc.group/trpc-go/trpc-cmdline/params"
+)
+
+func TestRegistry(t *testing.T) {
+	for _, name := range []string{"swagger", "openapi", "validate", "sync_git", "sync_goproxy",
+		"goimports", "gofmt", "mockgen", "gotag", "cpp_move"} {
+		p, ok := Get(name)
+		require.True(t, ok, name)
+		require.Equal(t, name, p.Name())
+	}
+	_, ok := Get("not_exist")
+	require.False(t, ok)
+}
+
+func TestChain(t *testing.T) {
+	t.Run("built-in chain", func(t *testing.T) {
+		chain, err := Chain("go", nil, nil)
+		require.Nil(t, err)
+		require.Equal(t, len(Plugins)+len(PluginsExt["go"]), len(chain))
+	})
+	t.Run("order, disabled and settings", func(t *testing.T) {
+		settings := make(map[string]map[string]interface{})
+		chain, err := Chain("go", []*config.PluginConfig{
+			{Name: "mockgen"},
+			{Name: "gotag", Disabled: true},
+			{Name: "goimports", Settings: map[string]interface{}{"local": "trpc.group"}},
+		}, settings)
+		require.Nil(t, err)
+		require.Equal(t, []string{"mockgen", "goimports"}, names(chain))
+		opt := &params.Option{PluginSettings: settings}
+		require.Equal(t, "trpc.group", settingString(opt, "goimports", "local"))
+		require.Equal(t, "", settingString(opt, "mockgen", "local"))
+	})
+	t.Run("unknown plugin", func(t *testing.T) {
+		_, err := Chain("go", []*config.PluginConfig{{Name: "not_exist"}}, nil)
+		require.NotNil(t, err)
+	})
+}
+
+func TestFilter(t *testing.T) {
+	chain, err := Chain("go", nil, nil)
+	require.Nil(t, err)
+	require.Equal(t, []string{"goimports", "gofmt"}, names(Filter(chain, NonIDLPlugins...)))
+}
+
+func names(chain []Plugin) []string {
+	var ns []string
+	for _, p := range chain {
+		ns = append(ns, p.Name())
+	}
+	return ns
+}
+
+// TestDefaultChains checks that the default chain of every language in install/trpc.yaml
+// contains the public plugins, which used to run for all languages.
+func TestDefaultChains(t *testing.T) {
+	b, err := os.ReadFile("../install/trpc.yaml")
+	require.Nil(t, err)
+	var cfg config.Config
+	require.Nil(t, yaml.Unmarshal(b, &cfg))
+	require.Contains(t, cfg.Plugins, "cpp")
+	for lang, cfgs := range cfg.Plugins {
+		chain, err := Chain(lang, cfgs, nil)
+		require.Nil(t, err)
+		for _, p := range Plugins {
+			require.Contains(t, names(chain), p.Name(), lang)
+		}
+	}
+}
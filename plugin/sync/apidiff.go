@@ -0,0 +1,272 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package sync
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"trpc.group/trpc-go/trpc-cmdline/descriptor"
+)
+
+// Levels of the API changes, which decide the part of the semantic version to bump.
+const (
+	bumpPatch = iota // Changes of comments or options.
+	bumpMinor        // Additions.
+	bumpMajor        // Removals, renames and type changes, which break the users.
+)
+
+var bumpNames = []string{bumpPatch: "patch", bumpMinor: "minor", bumpMajor: "major"}
+
+// apiChange is a change of the API between two versions of the IDL file.
+type apiChange struct {
+	level  int
+	reason string
+}
+
+// apiDiff collects the changes of the API.
+type apiDiff []apiChange
+
+func (d *apiDiff) add(level int, format string, args ...interface{}) {
+	*d = append(*d, apiChange{level: level, reason: fmt.Sprintf(format, args...)})
+}
+
+// level returns the highest level of the changes, which is patch if there are none.
+func (d apiDiff) level() int {
+	level := bumpPatch
+	for _, c := range d {
+		if c.level > level {
+			level = c.level
+		}
+	}
+	return level
+}
+
+// diffAPI compares the services, messages and enums of the old IDL file with the new one.
+func diffAPI(old, cur *descriptor.FileDescriptor) apiDiff {
+	var d apiDiff
+	if !reflect.DeepEqual(old.FileOptions, cur.FileOptions) {
+		d.add(bumpPatch, "change file options")
+	}
+	d.diffServices(old.Services, cur.Services)
+	d.diffMessages(flattenMessages(old.Messages), flattenMessages(cur.Messages))
+	d.diffEnums(flattenEnums(old.Enums, old.Messages), flattenEnums(cur.Enums, cur.Messages))
+	return d
+}
+
+func (d *apiDiff) diffServices(old, cur []*descriptor.ServiceDescriptor) {
+	oldServices := make(map[string]*descriptor.ServiceDescriptor)
+	for _, sd := range old {
+		oldServices[sd.Name] = sd
+	}
+	for _, sd := range cur {
+		oldSD, ok := oldServices[sd.Name]
+		if !ok {
+			d.add(bumpMinor, "add service %s", sd.Name)
+			continue
+		}
+		delete(oldServices, sd.Name)
+		d.diffRPCs(sd.Name, oldSD.RPC, sd.RPC)
+	}
+	for _, name := range sortedKeys(oldServices) {
+		d.add(bumpMajor, "remove service %s", name)
+	}
+}
+
+func (d *apiDiff) diffRPCs(service string, old, cur []*descriptor.RPCDescriptor) {
+	oldRPCs := make(map[string]*descriptor.RPCDescriptor)
+	for _, rpc := range old {
+		oldRPCs[rpc.Name] = rpc
+	}
+	for _, rpc := range cur {
+		name := service + "." + rpc.Name
+		oldRPC, ok := oldRPCs[rpc.Name]
+		if !ok {
+			d.add(bumpMinor, "add rpc %s", name)
+			continue
+		}
+		delete(oldRPCs, rpc.Name)
+		switch {
+		case oldRPC.RequestType != rpc.RequestType:
+			d.add(bumpMajor, "change request type of rpc %s from %s to %s", name, oldRPC.RequestType, rpc.RequestType)
+		case oldRPC.ResponseType != rpc.ResponseType:
+			d.add(bumpMajor, "change response type of rpc %s from %s to %s", name, oldRPC.ResponseType, rpc.ResponseType)
+		case oldRPC.ClientStreaming != rpc.ClientStreaming || oldRPC.ServerStreaming != rpc.ServerStreaming:
+			d.add(bumpMajor, "change streaming of rpc %s", name)
+		case oldRPC.LeadingComments != rpc.LeadingComments || oldRPC.TrailingComments != rpc.TrailingComments:
+			d.add(bumpPatch, "change comments of rpc %s", name)
+		case !reflect.DeepEqual(oldRPC, rpc):
+			d.add(bumpPatch, "change options of rpc %s", name)
+		}
+	}
+	for _, name := range sortedKeys(oldRPCs) {
+		d.add(bumpMajor, "remove rpc %s.%s", service, name)
+	}
+}
+
+func (d *apiDiff) diffMessages(old, cur map[string]*descriptor.MessageDescriptor) {
+	for _, name := range sortedKeys(cur) {
+		md := cur[name]
+		oldMD, ok := old[name]
+		if !ok {
+			d.add(bumpMinor, "add message %s", name)
+			continue
+		}
+		if oldMD.LeadingComments != md.LeadingComments || oldMD.TrailingComments != md.TrailingComments {
+			d.add(bumpPatch, "change comments of message %s", name)
+		}
+		if !reflect.DeepEqual(oldMD.Options, md.Options) {
+			d.add(bumpPatch, "change options of message %s", name)
+		}
+		d.diffFields(name, oldMD.Fields, md.Fields)
+	}
+	for _, name := range sortedKeys(old) {
+		if _, ok := cur[name]; !ok {
+			d.add(bumpMajor, "remove message %s", name)
+		}
+	}
+}
+
+// diffFields compares the fields by their numbers, so that the renamed fields are found.
+func (d *apiDiff) diffFields(message string, old, cur []*descriptor.FieldDescriptor) {
+	oldFields := make(map[int32]*descriptor.FieldDescriptor)
+	for _, f := range old {
+		oldFields[f.Number] = f
+	}
+	for _, f := range cur {
+		name := message + "." + f.Name
+		oldF, ok := oldFields[f.Number]
+		if !ok {
+			d.add(bumpMinor, "add field %s = %d", name, f.Number)
+			continue
+		}
+		delete(oldFields, f.Number)
+		switch {
+		case oldF.Name != f.Name:
+			d.add(bumpMajor, "rename field %s.%s to %s", message, oldF.Name, f.Name)
+		case fieldType(oldF) != fieldType(f):
+			d.add(bumpMajor, "change type of field %s from %s to %s", name, fieldType(oldF), fieldType(f))
+		case oldF.Oneof != f.Oneof:
+			d.add(bumpMajor, "move field %s from oneof %q to %q", name, oldF.Oneof, f.Oneof)
+		case oldF.LeadingComments != f.LeadingComments || oldF.TrailingComments != f.TrailingComments:
+			d.add(bumpPatch, "change comments of field %s", name)
+		case !reflect.DeepEqual(oldF.Options, f.Options):
+			d.add(bumpPatch, "change options of field %s", name)
+		}
+	}
+	for _, f := range old {
+		if _, ok := oldFields[f.Number]; ok {
+			d.add(bumpMajor, "remove field %s.%s = %d", message, f.Name, f.Number)
+		}
+	}
+}
+
+func (d *apiDiff) diffEnums(old, cur map[string]*descriptor.EnumDescriptor) {
+	for _, name := range sortedKeys(cur) {
+		ed := cur[name]
+		oldED, ok := old[name]
+		if !ok {
+			d.add(bumpMinor, "add enum %s", name)
+			continue
+		}
+		if oldED.LeadingComments != ed.LeadingComments || oldED.TrailingComments != ed.TrailingComments {
+			d.add(bumpPatch, "change comments of enum %s", name)
+		}
+		if !reflect.DeepEqual(oldED.Options, ed.Options) {
+			d.add(bumpPatch, "change options of enum %s", name)
+		}
+		oldValues := make(map[int32]*descriptor.EnumValueDescriptor)
+		for _, v := range oldED.Values {
+			oldValues[v.Number] = v
+		}
+		for _, v := range ed.Values {
+			oldV, ok := oldValues[v.Number]
+			switch {
+			case !ok:
+				d.add(bumpMinor, "add enum value %s.%s = %d", name, v.Name, v.Number)
+				continue
+			case oldV.Name != v.Name:
+				d.add(bumpMajor, "rename enum value %s.%s to %s", name, oldV.Name, v.Name)
+			case oldV.LeadingComments != v.LeadingComments || oldV.TrailingComments != v.TrailingComments:
+				d.add(bumpPatch, "change comments of enum value %s.%s", name, v.Name)
+			case !reflect.DeepEqual(oldV.Options, v.Options):
+				d.add(bumpPatch, "change options of enum value %s.%s", name, v.Name)
+			}
+			delete(oldValues, v.Number)
+		}
+		for _, v := range oldED.Values {
+			if _, ok := oldValues[v.Number]; ok {
+				d.add(bumpMajor, "remove enum value %s.%s = %d", name, v.Name, v.Number)
+			}
+		}
+	}
+	for _, name := range sortedKeys(old) {
+		if _, ok := cur[name]; !ok {
+			d.add(bumpMajor, "remove enum %s", name)
+		}
+	}
+}
+
+// fieldType returns the type of the field as it is written in the IDL, such as repeated int32 and map<string, Foo>.
+func fieldType(f *descriptor.FieldDescriptor) string {
+	if f == nil {
+		return ""
+	}
+	if f.Map {
+		return fmt.Sprintf("map<%s, %s>", fieldType(f.MapKey), fieldType(f.MapValue))
+	}
+	typ := f.Type
+	if f.TypeName != "" {
+		typ = f.TypeName
+	}
+	if f.Repeated {
+		return "repeated " + typ
+	}
+	return typ
+}
+
+// flattenMessages returns the messages including the nested ones keyed by their fully qualified names.
+func flattenMessages(mds []*descriptor.MessageDescriptor) map[string]*descriptor.MessageDescriptor {
+	res := make(map[string]*descriptor.MessageDescriptor)
+	var walk func([]*descriptor.MessageDescriptor)
+	walk = func(mds []*descriptor.MessageDescriptor) {
+		for _, md := range mds {
+			res[md.FullyQualifiedName] = md
+			walk(md.Messages)
+		}
+	}
+	walk(mds)
+	return res
+}
+
+// flattenEnums returns the enums including the ones nested in the messages keyed by their fully qualified names.
+func flattenEnums(eds []*descriptor.EnumDescriptor,
+	mds []*descriptor.MessageDescriptor) map[string]*descriptor.EnumDescriptor {
+	res := make(map[string]*descriptor.EnumDescriptor)
+	for _, ed := range eds {
+		res[ed.FullyQualifiedName] = ed
+	}
+	for _, md := range flattenMessages(mds) {
+		for _, ed := range md.Enums {
+			res[ed.FullyQualifiedName] = ed
+		}
+	}
+	return res
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,274 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package sync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"gopkg.in/yaml.v2"
+
+	"trpc.group/trpc-go/trpc-cmdline/params"
+	"trpc.group/trpc-go/trpc-cmdline/util/log"
+)
+
+// Authentication methods of the remote repository, see Settings.Auth.
+const (
+	// AuthAuto picks the credentials by the protocol of the remote:
+	// ssh uses ssh_key if set, then ssh-agent if $SSH_AUTH_SOCK is set, then ~/.ssh/id_ed25519, id_ecdsa and id_rsa;
+	// http(s) uses the token if its environment variable is set, then the git credential helpers,
+	// and accesses anonymously if neither provides one; file needs no credentials.
+	AuthAuto             = "auto"
+	AuthSSHAgent         = "ssh-agent"         // Keys of the ssh-agent listening on $SSH_AUTH_SOCK.
+	AuthSSHKey           = "ssh-key"           // The private key ssh_key, ~/.ssh/id_rsa by default.
+	AuthCredentialHelper = "credential-helper" // Username and password from `git credential fill`.
+	AuthToken            = "token"             // Token from the environment variable token_env.
+	AuthNone             = "none"              // No credentials.
+)
+
+// Protocols of the remote derived from go_package, see Settings.Scheme.
+const (
+	schemeSSH   = "ssh"
+	schemeHTTPS = "https"
+)
+
+const (
+	defaultSSHUser   = "git"
+	defaultTokenEnv  = "TRPC_GIT_TOKEN"
+	defaultTokenUser = "oauth2"
+)
+
+// defaultSSHKeys are the private keys tried in auto mode, relative to the home directory.
+var defaultSSHKeys = []string{".ssh/id_ed25519", ".ssh/id_ecdsa", ".ssh/id_rsa"}
+
+// Settings is the settings block of the sync_git plugin in trpc.yaml or .trpc.yaml, e.g.
+//
+//	plugins:
+//	  go:
+//	    - name: sync_git
+//	      settings:
+//	        auth: ssh-key
+//	        ssh_key: ~/.ssh/id_ed25519
+//	        ssh_key_passphrase_env: TRPC_GIT_SSH_PASSPHRASE
+type Settings struct {
+	Auth string `yaml:"auth"` // Authentication method, defaults to auto.
+	// Scheme is the protocol of the remote derived from go_package without --remote, ssh (default) or https,
+	// e.g. git@trpc.group:foo/bar.git or https://trpc.group/foo/bar.git.
+	Scheme string `yaml:"scheme"`
+
+	SSHUser string `yaml:"ssh_user"` // User of ssh, defaults to the one in the URL or git.
+	SSHKey  string `yaml:"ssh_key"`  // Path of the private key, ~ is expanded to the home directory.
+	// SSHKeyPassphrase is the passphrase of the private key, prefer SSHKeyPassphraseEnv to keep it out of the file.
+	SSHKeyPassphrase    string `yaml:"ssh_key_passphrase"`
+	SSHKeyPassphraseEnv string `yaml:"ssh_key_passphrase_env"` // Environment variable of the passphrase.
+
+	TokenEnv  string `yaml:"token_env"`  // Environment variable of the token, defaults to TRPC_GIT_TOKEN.
+	TokenUser string `yaml:"token_user"` // Username sent along with the token, defaults to oauth2.
+
+	// Branch is the branch to push when --branch is not given, auto to generate its name by the proto file.
+	// The default branch of the remote is pushed if it is empty.
+	Branch string `yaml:"branch"`
+	// CommitMessage is the text/template of the commit message executed with CommitInfo.
+	CommitMessage string `yaml:"commit_message"`
+	// Versioning is how --newtag names the tag without --tag, semver (default) or base100.
+	Versioning string `yaml:"versioning"`
+
+	// Layout is where the stubs are placed in the repository, flat (default) or monorepo.
+	Layout string `yaml:"layout"`
+	// ModulePath is the module path of the root of the monorepo, which defaults to the host and the path
+	// of the repository URL, e.g. trpc.group/foo/protocols for git@trpc.group:foo/protocols.git.
+	ModulePath string `yaml:"module_path"`
+}
+
+// loadSettings loads the settings of the sync_git plugin from the options.
+func loadSettings(opt *params.Option) (*Settings, error) {
+	s := &Settings{}
+	if m := opt.PluginSettings[pluginName]; len(m) != 0 {
+		b, err := yaml.Marshal(m)
+		if err != nil {
+			return nil, fmt.Errorf("yaml marshal settings of %s err: %w", pluginName, err)
+		}
+		if err := yaml.UnmarshalStrict(b, s); err != nil {
+			return nil, fmt.Errorf("invalid settings of %s: %w", pluginName, err)
+		}
+	}
+	if s.Auth == "" {
+		s.Auth = AuthAuto
+	}
+	switch s.Auth {
+	case AuthAuto, AuthSSHAgent, AuthSSHKey, AuthCredentialHelper, AuthToken, AuthNone:
+	default:
+		return nil, fmt.Errorf("invalid auth %q of %s, supported: %s", s.Auth, pluginName,
+			strings.Join([]string{AuthAuto, AuthSSHAgent, AuthSSHKey, AuthCredentialHelper, AuthToken, AuthNone}, ", "))
+	}
+	if s.Scheme == "" {
+		s.Scheme = schemeSSH
+	}
+	if s.Scheme != schemeSSH && s.Scheme != schemeHTTPS {
+		return nil, fmt.Errorf("invalid scheme %q of %s, supported: %s, %s", s.Scheme, pluginName, schemeSSH, schemeHTTPS)
+	}
+	if s.Versioning == "" {
+		s.Versioning = VersioningSemver
+	}
+	if s.Versioning != VersioningSemver && s.Versioning != VersioningBase100 {
+		return nil, fmt.Errorf("invalid versioning %q of %s, supported: %s, %s",
+			s.Versioning, pluginName, VersioningSemver, VersioningBase100)
+	}
+	if s.Layout == "" {
+		s.Layout = LayoutFlat
+	}
+	if s.Layout != LayoutFlat && s.Layout != LayoutMonorepo {
+		return nil, fmt.Errorf("invalid layout %q of %s, supported: %s, %s",
+			s.Layout, pluginName, LayoutFlat, LayoutMonorepo)
+	}
+	if s.TokenEnv == "" {
+		s.TokenEnv = defaultTokenEnv
+	}
+	if s.TokenUser == "" {
+		s.TokenUser = defaultTokenUser
+	}
+	return s, nil
+}
+
+// AuthSupplier provides access to the remote git repository at ep by the authentication method of settings.
+// A nil method means no credentials.
+func AuthSupplier(fileManager FileManager, gitManager GitManager, ep *transport.Endpoint,
+	settings *Settings) (transport.AuthMethod, error) {
+	a := &authenticator{fileManager: fileManager, gitManager: gitManager, ep: ep, settings: settings}
+	isSSH := ep.Protocol == "ssh"
+	isHTTP := ep.Protocol == "http" || ep.Protocol == "https"
+	switch settings.Auth {
+	case AuthNone:
+		return nil, nil
+	case AuthSSHAgent, AuthSSHKey:
+		if !isSSH {
+			return nil, fmt.Errorf("auth %s does not support %s remote %s", settings.Auth, ep.Protocol, ep)
+		}
+		if settings.Auth == AuthSSHAgent {
+			return a.sshAgent()
+		}
+		key := settings.SSHKey
+		if key == "" {
+			key = "~/.ssh/id_rsa"
+		}
+		return a.sshKey(key)
+	case AuthToken, AuthCredentialHelper:
+		if !isHTTP {
+			return nil, fmt.Errorf("auth %s does not support %s remote %s", settings.Auth, ep.Protocol, ep)
+		}
+		if settings.Auth == AuthToken {
+			return a.token()
+		}
+		return a.credentialHelper()
+	}
+	switch {
+	case isSSH:
+		return a.autoSSH()
+	case isHTTP:
+		return a.autoHTTP(), nil
+	default:
+		return nil, nil
+	}
+}
+
+type authenticator struct {
+	fileManager FileManager
+	gitManager  GitManager
+	ep          *transport.Endpoint
+	settings    *Settings
+}
+
+func (a *authenticator) sshUser() string {
+	if a.ep.User != "" {
+		return a.ep.User
+	}
+	if a.settings.SSHUser != "" {
+		return a.settings.SSHUser
+	}
+	return defaultSSHUser
+}
+
+func (a *authenticator) sshAgent() (transport.AuthMethod, error) {
+	auth, err := a.gitManager.NewSSHAgentAuth(a.sshUser())
+	if err != nil {
+		return nil, fmt.Errorf("git ssh new ssh agent auth err: %w", err)
+	}
+	return auth, nil
+}
+
+func (a *authenticator) sshKey(key string) (transport.AuthMethod, error) {
+	if strings.HasPrefix(key, "~/") {
+		home, err := a.fileManager.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("os user home dir err: %w", err)
+		}
+		key = filepath.Join(home, key[2:])
+	}
+	passphrase := a.settings.SSHKeyPassphrase
+	if a.settings.SSHKeyPassphraseEnv != "" {
+		passphrase = os.Getenv(a.settings.SSHKeyPassphraseEnv)
+	}
+	publicKeys, err := a.gitManager.NewPublicKeysFromFile(a.sshUser(), key, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("git ssh new public keys from file error: %w, file location %s", err, key)
+	}
+	return publicKeys, nil
+}
+
+func (a *authenticator) autoSSH() (transport.AuthMethod, error) {
+	if a.settings.SSHKey != "" {
+		return a.sshKey(a.settings.SSHKey)
+	}
+	if os.Getenv("SSH_AUTH_SOCK") != "" {
+		return a.sshAgent()
+	}
+	var errs []string
+	for _, key := range defaultSSHKeys {
+		auth, err := a.sshKey("~/" + key)
+		if err == nil {
+			return auth, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return nil, fmt.Errorf("no ssh credentials for %s, start ssh-agent or configure ssh_key of %s: %s",
+		a.ep, pluginName, strings.Join(errs, "; "))
+}
+
+func (a *authenticator) token() (transport.AuthMethod, error) {
+	token := os.Getenv(a.settings.TokenEnv)
+	if token == "" {
+		return nil, fmt.Errorf("environment variable %s of the git token is empty", a.settings.TokenEnv)
+	}
+	return &http.BasicAuth{Username: a.settings.TokenUser, Password: token}, nil
+}
+
+func (a *authenticator) credentialHelper() (transport.AuthMethod, error) {
+	username, password, err := a.gitManager.CredentialFill(a.ep.String())
+	if err != nil {
+		return nil, err
+	}
+	return &http.BasicAuth{Username: username, Password: password}, nil
+}
+
+func (a *authenticator) autoHTTP() transport.AuthMethod {
+	if auth, err := a.token(); err == nil {
+		return auth
+	}
+	auth, err := a.credentialHelper()
+	if err != nil {
+		log.Debug("no credentials for %s, access anonymously: %v", a.ep, err)
+		return nil
+	}
+	return auth
+}
@@ -0,0 +1,211 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package sync
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"trpc.group/trpc-go/trpc-cmdline/descriptor"
+	"trpc.group/trpc-go/trpc-cmdline/params"
+)
+
+func TestAuthSupplier(t *testing.T) {
+	endpoint := func(url string) *transport.Endpoint {
+		ep, err := transport.NewEndpoint(url)
+		require.Nil(t, err)
+		return ep
+	}
+	settings := func(m map[string]interface{}) *Settings {
+		s, err := loadSettings(&params.Option{PluginSettings: map[string]map[string]interface{}{pluginName: m}})
+		require.Nil(t, err)
+		return s
+	}
+	keys := &ssh.PublicKeys{User: "git"}
+	agent := &ssh.PublicKeysCallback{User: "git"}
+
+	t.Run("ssh key with passphrase", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		fm, gm := NewMockFileManager(ctrl), NewMockGitManager(ctrl)
+		fm.EXPECT().UserHomeDir().Return("/home/dev", nil)
+		gm.EXPECT().NewPublicKeysFromFile("git", "/home/dev/.ssh/id_ed25519", "secret").Return(keys, nil)
+		t.Setenv("TEST_PASSPHRASE", "secret")
+		auth, err := AuthSupplier(fm, gm, endpoint("git@trpc.group:foo/bar.git"), settings(map[string]interface{}{
+			"ssh_key":                "~/.ssh/id_ed25519",
+			"ssh_key_passphrase_env": "TEST_PASSPHRASE",
+		}))
+		require.Nil(t, err)
+		require.Equal(t, keys, auth)
+	})
+
+	t.Run("ssh agent", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		gm := NewMockGitManager(ctrl)
+		gm.EXPECT().NewSSHAgentAuth("deploy").Return(agent, nil)
+		t.Setenv("SSH_AUTH_SOCK", "/tmp/agent.sock")
+		auth, err := AuthSupplier(NewMockFileManager(ctrl), gm, endpoint("ssh://deploy@trpc.group/foo/bar.git"),
+			settings(nil))
+		require.Nil(t, err)
+		require.Equal(t, agent, auth)
+	})
+
+	t.Run("default ssh keys", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		fm, gm := NewMockFileManager(ctrl), NewMockGitManager(ctrl)
+		fm.EXPECT().UserHomeDir().Return("/home/dev", nil).AnyTimes()
+		gomock.InOrder(
+			gm.EXPECT().NewPublicKeysFromFile("git", "/home/dev/.ssh/id_ed25519", "").
+				Return(nil, os.ErrNotExist),
+			gm.EXPECT().NewPublicKeysFromFile("git", "/home/dev/.ssh/id_ecdsa", "").Return(keys, nil),
+		)
+		t.Setenv("SSH_AUTH_SOCK", "")
+		auth, err := AuthSupplier(fm, gm, endpoint("git@trpc.group:foo/bar.git"), settings(nil))
+		require.Nil(t, err)
+		require.Equal(t, keys, auth)
+	})
+
+	t.Run("https token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		t.Setenv("CI_TOKEN", "t0ken")
+		auth, err := AuthSupplier(NewMockFileManager(ctrl), NewMockGitManager(ctrl),
+			endpoint("https://trpc.group/foo/bar.git"),
+			settings(map[string]interface{}{"token_env": "CI_TOKEN", "token_user": "ci"}))
+		require.Nil(t, err)
+		require.Equal(t, &http.BasicAuth{Username: "ci", Password: "t0ken"}, auth)
+
+		_, err = AuthSupplier(NewMockFileManager(ctrl), NewMockGitManager(ctrl),
+			endpoint("https://trpc.group/foo/bar.git"), settings(map[string]interface{}{"auth": AuthToken}))
+		require.ErrorContains(t, err, "TRPC_GIT_TOKEN")
+	})
+
+	t.Run("https credential helper", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		gm := NewMockGitManager(ctrl)
+		gomock.InOrder(
+			gm.EXPECT().CredentialFill("https://trpc.group/foo/bar.git").Return("dev", "pass", nil),
+			gm.EXPECT().CredentialFill("https://trpc.group/foo/bar.git").Return("", "", errors.New("none")),
+		)
+		t.Setenv(defaultTokenEnv, "")
+		auth, err := AuthSupplier(NewMockFileManager(ctrl), gm, endpoint("https://trpc.group/foo/bar.git"),
+			settings(nil))
+		require.Nil(t, err)
+		require.Equal(t, &http.BasicAuth{Username: "dev", Password: "pass"}, auth)
+
+		// Anonymous access without credentials.
+		auth, err = AuthSupplier(NewMockFileManager(ctrl), gm, endpoint("https://trpc.group/foo/bar.git"),
+			settings(nil))
+		require.Nil(t, err)
+		require.Nil(t, auth)
+	})
+
+	t.Run("file and none", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		auth, err := AuthSupplier(NewMockFileManager(ctrl), NewMockGitManager(ctrl),
+			endpoint("file:///srv/git/bar.git"), settings(nil))
+		require.Nil(t, err)
+		require.Nil(t, auth)
+		auth, err = AuthSupplier(NewMockFileManager(ctrl), NewMockGitManager(ctrl),
+			endpoint("git@trpc.group:foo/bar.git"), settings(map[string]interface{}{"auth": AuthNone}))
+		require.Nil(t, err)
+		require.Nil(t, auth)
+	})
+
+	t.Run("protocol mismatch", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		_, err := AuthSupplier(NewMockFileManager(ctrl), NewMockGitManager(ctrl),
+			endpoint("https://trpc.group/foo/bar.git"), settings(map[string]interface{}{"auth": AuthSSHAgent}))
+		require.ErrorContains(t, err, "auth ssh-agent does not support https remote")
+	})
+}
+
+func TestLoadSettingsErr(t *testing.T) {
+	for _, tt := range []struct {
+		settings map[string]interface{}
+		err      string
+	}{
+		{map[string]interface{}{"auth": "password"}, `invalid auth "password"`},
+		{map[string]interface{}{"scheme": "ftp"}, `invalid scheme "ftp"`},
+		{map[string]interface{}{"versioning": "calver"}, `invalid versioning "calver"`},
+		{map[string]interface{}{"layout": "nested"}, `invalid layout "nested"`},
+		{map[string]interface{}{"ssh_keys": "x"}, "field ssh_keys not found"},
+	} {
+		_, err := loadSettings(&params.Option{PluginSettings: map[string]map[string]interface{}{pluginName: tt.settings}})
+		require.ErrorContains(t, err, tt.err)
+	}
+}
+
+// newLocalRemote creates an empty bare repository as the remote, and returns its path and the stub directory.
+func newLocalRemote(t *testing.T) (bare, out, stub string) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is required by the file transport")
+	}
+	dir := t.TempDir()
+	// The commit author is read from the global git config.
+	t.Setenv("HOME", dir)
+	require.Nil(t, os.WriteFile(filepath.Join(dir, ".gitconfig"),
+		[]byte("[user]\n\tname = trpc\n\temail = trpc@trpc.group\n"), 0644))
+	bare = filepath.Join(dir, "remote", "helloworld.git")
+	_, err := git.PlainInit(bare, true)
+	require.Nil(t, err)
+
+	out = filepath.Join(dir, "out")
+	stub = filepath.Join(out, "stub", "trpc.group", "test", "helloworld")
+	require.Nil(t, os.MkdirAll(stub, os.ModePerm))
+	require.Nil(t, os.WriteFile(filepath.Join(stub, "hello.pb.go"), []byte("package helloworld\n"), 0644))
+	return bare, out, stub
+}
+
+// commitFiles returns the files of the commit referenced by name.
+func commitFiles(t *testing.T, r *git.Repository, name plumbing.ReferenceName) (*object.Commit, []string) {
+	ref, err := r.Reference(name, true)
+	require.Nil(t, err)
+	c, err := r.CommitObject(ref.Hash())
+	require.Nil(t, err)
+	iter, err := c.Files()
+	require.Nil(t, err)
+	var files []string
+	require.Nil(t, iter.ForEach(func(f *object.File) error {
+		files = append(files, f.Name)
+		return nil
+	}))
+	return c, files
+}
+
+func TestSyncGit_LocalBareRepo(t *testing.T) {
+	bare, out, stub := newLocalRemote(t)
+	s := NewGit(DefaultFileManager, DefaultGitManager, AuthSupplier)
+	opt := &params.Option{Sync: true, OutputDir: out, Remote: "file://" + filepath.ToSlash(bare), NewTag: true}
+	fd := &descriptor.FileDescriptor{GoPackage: "trpc.group/test/helloworld"}
+	// The first run initializes the empty remote, and the second one clones it.
+	require.Nil(t, s.Run(fd, opt))
+	require.Nil(t, os.WriteFile(filepath.Join(stub, "hello.trpc.go"), []byte("package helloworld\n"), 0644))
+	require.Nil(t, s.Run(fd, opt))
+
+	r, err := git.PlainOpen(bare)
+	require.Nil(t, err)
+	_, files := commitFiles(t, r, plumbing.HEAD)
+	require.Equal(t, []string{"hello.pb.go", "hello.trpc.go"}, files)
+	for _, tag := range []string{"v1.0.0", "v1.1.0"} {
+		_, err = r.Tag(tag)
+		require.Nil(t, err)
+	}
+}
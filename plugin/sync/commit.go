@@ -0,0 +1,242 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package sync
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"trpc.group/trpc-go/trpc-cmdline/config"
+	"trpc.group/trpc-go/trpc-cmdline/descriptor"
+	"trpc.group/trpc-go/trpc-cmdline/params"
+)
+
+const (
+	// autoBranch generates the name of the branch by the proto file.
+	autoBranch       = "auto"
+	autoBranchPrefix = "trpc-sync/"
+	// rpcTrailer records the signatures of the RPCs in the commit message,
+	// by which the RPCs changed since the last sync are summarized.
+	rpcTrailer = "Trpc-Rpc: "
+	// patchStdout writes the patch into the standard output.
+	patchStdout = "-"
+)
+
+// Kinds of RPC changes.
+const (
+	ChangeAdded   = "add"
+	ChangeChanged = "change"
+	ChangeRemoved = "remove"
+)
+
+// defaultCommitMessage is the default template of the commit message.
+const defaultCommitMessage = `sync stubs of {{ .ProtoFile }}: {{ .Summary }}
+{{ if .Changes }}
+{{ range .Changes }}* {{ .Kind }} {{ .RPC }}
+{{ end }}{{ end }}
+Proto: {{ .ProtoFile }}{{ if .ProtoHash }} (sha256 {{ .ProtoHash }}){{ end }}
+Generator: trpc-cmdline {{ .Version }}`
+
+// CommitInfo is the data of the commit message template, see Settings.CommitMessage.
+type CommitInfo struct {
+	ProtoFile string      // Path of the proto file, such as helloworld.proto.
+	ProtoHash string      // Hex SHA-256 of the proto file, empty if it is not readable.
+	Version   string      // Version of trpc-cmdline.
+	Changes   []RPCChange // RPCs changed since the last sync, sorted by the RPC names.
+	Summary   string      // One line summary of Changes, such as "add Greeter.SayHello, remove Greeter.Hi".
+
+	rpcs map[string]string // Signatures of the current RPCs keyed by the names, which are recorded as trailers.
+}
+
+// RPCChange is an RPC added, changed or removed since the last sync.
+type RPCChange struct {
+	Kind      string // add, change or remove.
+	RPC       string // Service and name of the RPC, such as Greeter.SayHello.
+	Signature string // Such as /trpc.foo.bar.Greeter/SayHello(SayHelloRequest) returns (SayHelloReply).
+}
+
+// newCommitInfo collects the commit info of the proto file, whose RPC changes are summarized by diffRPCs.
+func newCommitInfo(fd *descriptor.FileDescriptor, opt *params.Option) *CommitInfo {
+	info := &CommitInfo{ProtoFile: filepath.ToSlash(opt.Protofile), Version: config.TRPCCliVersion,
+		rpcs: make(map[string]string)}
+	if opt.ProtofileAbs != "" {
+		if b, err := os.ReadFile(opt.ProtofileAbs); err == nil {
+			sum := sha256.Sum256(b)
+			info.ProtoHash = hex.EncodeToString(sum[:])
+		}
+	}
+	for _, sd := range fd.Services {
+		for _, rpc := range sd.RPC {
+			info.rpcs[sd.Name+"."+rpc.Name] = rpcSignature(fd.PackageName, sd, rpc)
+		}
+	}
+	return info
+}
+
+// diffRPCs compares the RPCs with those recorded in the message of the last commit.
+func (info *CommitInfo) diffRPCs(lastMessage string) {
+	last := parseRPCTrailers(lastMessage)
+	info.Changes = nil
+	for name, sig := range info.rpcs {
+		switch lastSig, ok := last[name]; {
+		case !ok:
+			info.Changes = append(info.Changes, RPCChange{Kind: ChangeAdded, RPC: name, Signature: sig})
+		case lastSig != sig:
+			info.Changes = append(info.Changes, RPCChange{Kind: ChangeChanged, RPC: name, Signature: sig})
+		}
+	}
+	for name, sig := range last {
+		if _, ok := info.rpcs[name]; !ok {
+			info.Changes = append(info.Changes, RPCChange{Kind: ChangeRemoved, RPC: name, Signature: sig})
+		}
+	}
+	sort.Slice(info.Changes, func(i, j int) bool { return info.Changes[i].RPC < info.Changes[j].RPC })
+	summary := make([]string, 0, len(info.Changes))
+	for _, c := range info.Changes {
+		summary = append(summary, c.Kind+" "+c.RPC)
+	}
+	info.Summary = strings.Join(summary, ", ")
+	if info.Summary == "" {
+		info.Summary = "no rpc changes"
+	}
+}
+
+// rpcSignature returns the signature of the RPC, such as
+// /trpc.foo.bar.Greeter/SayHello(SayHelloRequest) returns (stream SayHelloReply).
+func rpcSignature(pkg string, sd *descriptor.ServiceDescriptor, rpc *descriptor.RPCDescriptor) string {
+	stream := func(b bool) string {
+		if b {
+			return "stream "
+		}
+		return ""
+	}
+	prefix := pkg + "."
+	return fmt.Sprintf("/%s%s/%s(%s%s) returns (%s%s)", prefix, sd.Name, rpc.Name,
+		stream(rpc.ClientStreaming), strings.TrimPrefix(rpc.RequestType, prefix),
+		stream(rpc.ServerStreaming), strings.TrimPrefix(rpc.ResponseType, prefix))
+}
+
+// parseRPCTrailers parses the RPC signatures recorded in the commit message, keyed by the service and RPC names.
+func parseRPCTrailers(message string) map[string]string {
+	rpcs := make(map[string]string)
+	sc := bufio.NewScanner(strings.NewReader(message))
+	for sc.Scan() {
+		sig := strings.TrimPrefix(sc.Text(), rpcTrailer)
+		if sig == sc.Text() {
+			continue
+		}
+		// The signature is /package.Service/RPC(...), the package may contain dots.
+		i := strings.Index(sig, "(")
+		if i < 0 {
+			continue
+		}
+		fullName := strings.TrimPrefix(sig[:i], "/")
+		service, rpc, ok := strings.Cut(fullName, "/")
+		if !ok {
+			continue
+		}
+		if j := strings.LastIndex(service, "."); j >= 0 {
+			service = service[j+1:]
+		}
+		rpcs[service+"."+rpc] = sig
+	}
+	return rpcs
+}
+
+// commitMessage renders the commit message by the template, followed by the trailers of the RPC signatures.
+func (info *CommitInfo) commitMessage(text string) (string, error) {
+	if text == "" {
+		text = defaultCommitMessage
+	}
+	tpl, err := template.New("commit_message").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parse commit message template err: %w", err)
+	}
+	buf := &bytes.Buffer{}
+	if err := tpl.Execute(buf, info); err != nil {
+		return "", fmt.Errorf("render commit message err: %w", err)
+	}
+	msg := strings.TrimSpace(buf.String())
+	if len(info.rpcs) == 0 {
+		return msg + "\n", nil
+	}
+	trailers := make([]string, 0, len(info.rpcs))
+	for _, sig := range info.rpcs {
+		trailers = append(trailers, rpcTrailer+sig)
+	}
+	sort.Strings(trailers)
+	return msg + "\n\n" + strings.Join(trailers, "\n") + "\n", nil
+}
+
+// branchName returns the name of the branch to push, which is generated by the proto file if it is auto.
+func (info *CommitInfo) branchName(branch string) string {
+	if branch != autoBranch {
+		return branch
+	}
+	name := strings.TrimSuffix(filepath.Base(info.ProtoFile), filepath.Ext(info.ProtoFile))
+	if name == "" || name == "." {
+		name = "stub"
+	}
+	if info.ProtoHash != "" {
+		return autoBranchPrefix + name + "-" + info.ProtoHash[:8]
+	}
+	return autoBranchPrefix + name + "-" + time.Now().Format("20060102150405")
+}
+
+// formatPatch formats the patch of the commit against its first parent like `git format-patch`,
+// which can be applied by `git am`.
+func formatPatch(c *object.Commit) (string, error) {
+	var (
+		patch *object.Patch
+		err   error
+	)
+	if c.NumParents() == 0 {
+		tree, terr := c.Tree()
+		if terr != nil {
+			return "", fmt.Errorf("git commit tree err: %w", terr)
+		}
+		changes, derr := object.DiffTree(nil, tree)
+		if derr != nil {
+			return "", fmt.Errorf("git diff tree err: %w", derr)
+		}
+		patch, err = changes.Patch()
+	} else {
+		parent, perr := c.Parent(0)
+		if perr != nil {
+			return "", fmt.Errorf("git commit parent err: %w", perr)
+		}
+		patch, err = parent.Patch(c)
+	}
+	if err != nil {
+		return "", fmt.Errorf("git patch err: %w", err)
+	}
+	subject, body, _ := strings.Cut(c.Message, "\n")
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "From %s Mon Sep 17 00:00:00 2001\n", c.Hash)
+	fmt.Fprintf(buf, "From: %s <%s>\n", c.Author.Name, c.Author.Email)
+	fmt.Fprintf(buf, "Date: %s\n", c.Author.When.Format(time.RFC1123Z))
+	fmt.Fprintf(buf, "Subject: [PATCH] %s\n\n", subject)
+	if body = strings.TrimSpace(body); body != "" {
+		fmt.Fprintf(buf, "%s\n", body)
+	}
+	fmt.Fprintf(buf, "---\n%s", patch.String())
+	return buf.String(), nil
+}
@@ -0,0 +1,128 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-cmdline/config"
+	"trpc.group/trpc-go/trpc-cmdline/descriptor"
+	"trpc.group/trpc-go/trpc-cmdline/params"
+)
+
+func newHelloFileDescriptor(rpcs ...*descriptor.RPCDescriptor) *descriptor.FileDescriptor {
+	return &descriptor.FileDescriptor{
+		PackageName: "trpc.test.helloworld",
+		GoPackage:   "trpc.group/test/helloworld",
+		Services:    []*descriptor.ServiceDescriptor{{Name: "Greeter", RPC: rpcs}},
+	}
+}
+
+func TestCommitInfo(t *testing.T) {
+	proto := filepath.Join(t.TempDir(), "hello.proto")
+	require.Nil(t, os.WriteFile(proto, []byte(`syntax = "proto3";`), 0644))
+	opt := &params.Option{Protofile: "hello.proto", ProtofileAbs: proto}
+	fd := newHelloFileDescriptor(
+		&descriptor.RPCDescriptor{Name: "SayHello", RequestType: "trpc.test.helloworld.HelloRequest",
+			ResponseType: "trpc.test.helloworld.HelloReply"},
+		&descriptor.RPCDescriptor{Name: "Watch", RequestType: "trpc.test.helloworld.HelloRequest",
+			ResponseType: "trpc.test.helloworld.HelloReply", ServerStreaming: true},
+	)
+	info := newCommitInfo(fd, opt)
+	require.Len(t, info.ProtoHash, 64)
+	require.Equal(t, "trpc-sync/hello-"+info.ProtoHash[:8], info.branchName(autoBranch))
+	require.Equal(t, "review", info.branchName("review"))
+
+	info.diffRPCs("")
+	require.Equal(t, "add Greeter.SayHello, add Greeter.Watch", info.Summary)
+	msg, err := info.commitMessage("")
+	require.Nil(t, err)
+	require.Equal(t, `sync stubs of hello.proto: add Greeter.SayHello, add Greeter.Watch
+
+* add Greeter.SayHello
+* add Greeter.Watch
+
+Proto: hello.proto (sha256 `+info.ProtoHash+`)
+Generator: trpc-cmdline `+config.TRPCCliVersion+`
+
+Trpc-Rpc: /trpc.test.helloworld.Greeter/SayHello(HelloRequest) returns (HelloReply)
+Trpc-Rpc: /trpc.test.helloworld.Greeter/Watch(HelloRequest) returns (stream HelloReply)
+`, msg)
+
+	// The RPCs are compared with the trailers of the last commit.
+	last := "old\n\n" + rpcTrailer + "/trpc.test.helloworld.Greeter/SayHello(HelloRequest) returns (HelloReply)\n" +
+		rpcTrailer + "/trpc.test.helloworld.Greeter/Watch(HelloRequest) returns (HelloReply)\n" +
+		rpcTrailer + "/trpc.test.helloworld.Greeter/Bye(ByeRequest) returns (ByeReply)\n"
+	info.diffRPCs(last)
+	require.Equal(t, []RPCChange{
+		{Kind: ChangeRemoved, RPC: "Greeter.Bye",
+			Signature: "/trpc.test.helloworld.Greeter/Bye(ByeRequest) returns (ByeReply)"},
+		{Kind: ChangeChanged, RPC: "Greeter.Watch",
+			Signature: "/trpc.test.helloworld.Greeter/Watch(HelloRequest) returns (stream HelloReply)"},
+	}, info.Changes)
+	msg, err = info.commitMessage("{{ .Summary }} by {{ .Version }}")
+	require.Nil(t, err)
+	require.True(t, strings.HasPrefix(msg, "remove Greeter.Bye, change Greeter.Watch by "+config.TRPCCliVersion+"\n\n"))
+
+	info.diffRPCs(msg)
+	require.Equal(t, "no rpc changes", info.Summary)
+	_, err = info.commitMessage("{{ .Unknown }}")
+	require.ErrorContains(t, err, "render commit message")
+}
+
+func TestSyncGit_BranchAndPatch(t *testing.T) {
+	bare, out, stub := newLocalRemote(t)
+	s := NewGit(DefaultFileManager, DefaultGitManager, AuthSupplier)
+	opt := &params.Option{Sync: true, OutputDir: out, Remote: "file://" + filepath.ToSlash(bare),
+		Protofile: "hello.proto"}
+	fd := newHelloFileDescriptor(&descriptor.RPCDescriptor{Name: "SayHello",
+		RequestType: "trpc.test.helloworld.HelloRequest", ResponseType: "trpc.test.helloworld.HelloReply"})
+	require.Nil(t, s.Run(fd, opt))
+	r, err := git.PlainOpen(bare)
+	require.Nil(t, err)
+	master, _ := commitFiles(t, r, plumbing.HEAD)
+	require.Contains(t, master.Message, "sync stubs of hello.proto: add Greeter.SayHello\n")
+
+	// Identical stubs are not committed.
+	require.Nil(t, s.Run(fd, opt))
+	head, _ := commitFiles(t, r, plumbing.HEAD)
+	require.Equal(t, master.Hash, head.Hash)
+
+	// The changes are pushed to the branch based on the default one, and later on the branch itself.
+	opt.Branch = "review"
+	require.Nil(t, os.WriteFile(filepath.Join(stub, "hello.trpc.go"), []byte("package helloworld\n"), 0644))
+	require.Nil(t, s.Run(fd, opt))
+	fd.Services[0].RPC = append(fd.Services[0].RPC, &descriptor.RPCDescriptor{Name: "Bye",
+		RequestType: "trpc.test.helloworld.ByeRequest", ResponseType: "trpc.test.helloworld.ByeReply"})
+	require.Nil(t, os.WriteFile(filepath.Join(stub, "hello_mock.go"), []byte("package helloworld\n"), 0644))
+	require.Nil(t, s.Run(fd, opt))
+	review, files := commitFiles(t, r, plumbing.NewBranchReferenceName("review"))
+	require.Equal(t, []string{"hello.pb.go", "hello.trpc.go", "hello_mock.go"}, files)
+	require.Contains(t, review.Message, "sync stubs of hello.proto: add Greeter.Bye\n")
+	head, _ = commitFiles(t, r, plumbing.HEAD)
+	require.Equal(t, master.Hash, head.Hash)
+
+	// The patch is written instead of pushing.
+	opt.Branch, opt.Patch = "", filepath.Join(out, "stub.patch")
+	require.Nil(t, s.Run(fd, opt))
+	b, err := os.ReadFile(opt.Patch)
+	require.Nil(t, err)
+	require.Contains(t, string(b), "Subject: [PATCH] sync stubs of hello.proto: add Greeter.Bye\n")
+	require.Contains(t, string(b), "diff --git a/hello_mock.go b/hello_mock.go\n")
+	head, _ = commitFiles(t, r, plumbing.HEAD)
+	require.Equal(t, master.Hash, head.Hash)
+}
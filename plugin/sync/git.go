@@ -10,6 +10,13 @@
 package sync
 
 import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
@@ -31,13 +38,20 @@ type GitManager interface {
 	TagObject(r *git.Repository, h plumbing.Hash) (*object.Tag, error)
 	TagObjects(r *git.Repository) (*object.TagIter, error)
 	Head(r *git.Repository) (*plumbing.Reference, error)
+	Reference(r *git.Repository, name plumbing.ReferenceName, resolved bool) (*plumbing.Reference, error)
+	CommitObject(r *git.Repository, h plumbing.Hash) (*object.Commit, error)
+	Log(r *git.Repository, o *git.LogOptions) (object.CommitIter, error)
 	CreateTag(r *git.Repository, name string, hash plumbing.Hash,
 		opts *git.CreateTagOptions) (*plumbing.Reference, error)
 
+	Checkout(w *git.Worktree, opts *git.CheckoutOptions) error
 	AddWithOptions(w *git.Worktree, opts *git.AddOptions) error
+	Status(w *git.Worktree) (git.Status, error)
 	Commit(w *git.Worktree, msg string, opts *git.CommitOptions) (plumbing.Hash, error)
 
 	NewPublicKeysFromFile(user, pemFile, password string) (*ssh.PublicKeys, error)
+	NewSSHAgentAuth(user string) (*ssh.PublicKeysCallback, error)
+	CredentialFill(url string) (username, password string, err error)
 }
 
 type defaultGitManager struct{}
@@ -95,17 +109,43 @@ func (d *defaultGitManager) Head(r *git.Repository) (*plumbing.Reference, error)
 	return r.Head()
 }
 
+// Reference returns the reference for a given reference name, which is resolved if resolved is true.
+func (d *defaultGitManager) Reference(r *git.Repository, name plumbing.ReferenceName,
+	resolved bool) (*plumbing.Reference, error) {
+	return r.Reference(name, resolved)
+}
+
+// CommitObject return a Commit with the given hash.
+func (d *defaultGitManager) CommitObject(r *git.Repository, h plumbing.Hash) (*object.Commit, error) {
+	return r.CommitObject(h)
+}
+
+// Log returns the commit history from the given LogOptions.
+func (d *defaultGitManager) Log(r *git.Repository, o *git.LogOptions) (object.CommitIter, error) {
+	return r.Log(o)
+}
+
 // CreateTag create a tag.
 func (d *defaultGitManager) CreateTag(r *git.Repository, name string, hash plumbing.Hash,
 	opts *git.CreateTagOptions) (*plumbing.Reference, error) {
 	return r.CreateTag(name, hash, opts)
 }
 
+// Checkout switch branches or restore working tree files.
+func (d *defaultGitManager) Checkout(w *git.Worktree, opts *git.CheckoutOptions) error {
+	return w.Checkout(opts)
+}
+
 // AddWithOptions add with options for worktree.
 func (d *defaultGitManager) AddWithOptions(w *git.Worktree, opts *git.AddOptions) error {
 	return w.AddWithOptions(opts)
 }
 
+// Status returns the working tree status.
+func (d *defaultGitManager) Status(w *git.Worktree) (git.Status, error) {
+	return w.Status()
+}
+
 // Commit stores the current contents of the index in a new commit along with
 // a log message from the user describing the changes.
 func (d *defaultGitManager) Commit(w *git.Worktree, msg string, opts *git.CommitOptions) (plumbing.Hash, error) {
@@ -116,3 +156,39 @@ func (d *defaultGitManager) Commit(w *git.Worktree, msg string, opts *git.Commit
 func (d *defaultGitManager) NewPublicKeysFromFile(user, pemFile, password string) (*ssh.PublicKeys, error) {
 	return ssh.NewPublicKeysFromFile(user, pemFile, password)
 }
+
+// NewSSHAgentAuth returns a PublicKeysCallback based on the ssh-agent listening on $SSH_AUTH_SOCK.
+func (d *defaultGitManager) NewSSHAgentAuth(user string) (*ssh.PublicKeysCallback, error) {
+	return ssh.NewSSHAgentAuth(user)
+}
+
+// CredentialFill asks the git credential helpers for the username and password of the url by `git credential fill`,
+// without prompting on the terminal.
+func (d *defaultGitManager) CredentialFill(url string) (username, password string, err error) {
+	cmd := exec.Command("git", "credential", "fill")
+	cmd.Stdin = strings.NewReader("url=" + url + "\n\n")
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", "", fmt.Errorf("git credential fill err: %w, stderr: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		k, v, ok := strings.Cut(sc.Text(), "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "username":
+			username = v
+		case "password":
+			password = v
+		}
+	}
+	if password == "" {
+		return "", "", fmt.Errorf("git credential fill: no password for %s", url)
+	}
+	return username, password, nil
+}
@@ -63,6 +63,20 @@ func (mr *MockGitManagerMockRecorder) AddWithOptions(w, opts interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWithOptions", reflect.TypeOf((*MockGitManager)(nil).AddWithOptions), w, opts)
 }
 
+// Checkout mocks base method.
+func (m *MockGitManager) Checkout(w *git.Worktree, opts *git.CheckoutOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Checkout", w, opts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Checkout indicates an expected call of Checkout.
+func (mr *MockGitManagerMockRecorder) Checkout(w, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Checkout", reflect.TypeOf((*MockGitManager)(nil).Checkout), w, opts)
+}
+
 // Commit mocks base method.
 func (m *MockGitManager) Commit(w *git.Worktree, msg string, opts *git.CommitOptions) (plumbing.Hash, error) {
 	m.ctrl.T.Helper()
@@ -78,6 +92,21 @@ func (mr *MockGitManagerMockRecorder) Commit(w, msg, opts interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockGitManager)(nil).Commit), w, msg, opts)
 }
 
+// CommitObject mocks base method.
+func (m *MockGitManager) CommitObject(r *git.Repository, h plumbing.Hash) (*object.Commit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitObject", r, h)
+	ret0, _ := ret[0].(*object.Commit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CommitObject indicates an expected call of CommitObject.
+func (mr *MockGitManagerMockRecorder) CommitObject(r, h interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitObject", reflect.TypeOf((*MockGitManager)(nil).CommitObject), r, h)
+}
+
 // CreateRemote mocks base method.
 func (m *MockGitManager) CreateRemote(r *git.Repository, c *config.RemoteConfig) (*git.Remote, error) {
 	m.ctrl.T.Helper()
@@ -108,6 +137,22 @@ func (mr *MockGitManagerMockRecorder) CreateTag(r, name, hash, opts interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTag", reflect.TypeOf((*MockGitManager)(nil).CreateTag), r, name, hash, opts)
 }
 
+// CredentialFill mocks base method.
+func (m *MockGitManager) CredentialFill(url string) (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CredentialFill", url)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CredentialFill indicates an expected call of CredentialFill.
+func (mr *MockGitManagerMockRecorder) CredentialFill(url interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CredentialFill", reflect.TypeOf((*MockGitManager)(nil).CredentialFill), url)
+}
+
 // Head mocks base method.
 func (m *MockGitManager) Head(r *git.Repository) (*plumbing.Reference, error) {
 	m.ctrl.T.Helper()
@@ -123,6 +168,21 @@ func (mr *MockGitManagerMockRecorder) Head(r interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Head", reflect.TypeOf((*MockGitManager)(nil).Head), r)
 }
 
+// Log mocks base method.
+func (m *MockGitManager) Log(r *git.Repository, o *git.LogOptions) (object.CommitIter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Log", r, o)
+	ret0, _ := ret[0].(object.CommitIter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Log indicates an expected call of Log.
+func (mr *MockGitManagerMockRecorder) Log(r, o interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Log", reflect.TypeOf((*MockGitManager)(nil).Log), r, o)
+}
+
 // NewPublicKeysFromFile mocks base method.
 func (m *MockGitManager) NewPublicKeysFromFile(user, pemFile, password string) (*ssh.PublicKeys, error) {
 	m.ctrl.T.Helper()
@@ -138,6 +198,21 @@ func (mr *MockGitManagerMockRecorder) NewPublicKeysFromFile(user, pemFile, passw
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewPublicKeysFromFile", reflect.TypeOf((*MockGitManager)(nil).NewPublicKeysFromFile), user, pemFile, password)
 }
 
+// NewSSHAgentAuth mocks base method.
+func (m *MockGitManager) NewSSHAgentAuth(user string) (*ssh.PublicKeysCallback, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewSSHAgentAuth", user)
+	ret0, _ := ret[0].(*ssh.PublicKeysCallback)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewSSHAgentAuth indicates an expected call of NewSSHAgentAuth.
+func (mr *MockGitManagerMockRecorder) NewSSHAgentAuth(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewSSHAgentAuth", reflect.TypeOf((*MockGitManager)(nil).NewSSHAgentAuth), user)
+}
+
 // PlainClone mocks base method.
 func (m *MockGitManager) PlainClone(path string, isBare bool, o *git.CloneOptions) (*git.Repository, error) {
 	m.ctrl.T.Helper()
@@ -182,6 +257,21 @@ func (mr *MockGitManagerMockRecorder) Push(r, o interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Push", reflect.TypeOf((*MockGitManager)(nil).Push), r, o)
 }
 
+// Reference mocks base method.
+func (m *MockGitManager) Reference(r *git.Repository, name plumbing.ReferenceName, resolved bool) (*plumbing.Reference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reference", r, name, resolved)
+	ret0, _ := ret[0].(*plumbing.Reference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reference indicates an expected call of Reference.
+func (mr *MockGitManagerMockRecorder) Reference(r, name, resolved interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reference", reflect.TypeOf((*MockGitManager)(nil).Reference), r, name, resolved)
+}
+
 // Remote mocks base method.
 func (m *MockGitManager) Remote(r *git.Repository, name string) (*git.Remote, error) {
 	m.ctrl.T.Helper()
@@ -197,6 +287,21 @@ func (mr *MockGitManagerMockRecorder) Remote(r, name interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remote", reflect.TypeOf((*MockGitManager)(nil).Remote), r, name)
 }
 
+// Status mocks base method.
+func (m *MockGitManager) Status(w *git.Worktree) (git.Status, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Status", w)
+	ret0, _ := ret[0].(git.Status)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Status indicates an expected call of Status.
+func (mr *MockGitManagerMockRecorder) Status(w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockGitManager)(nil).Status), w)
+}
+
 // TagObject mocks base method.
 func (m *MockGitManager) TagObject(r *git.Repository, h plumbing.Hash) (*object.Tag, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,373 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package sync
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	modzip "golang.org/x/mod/zip"
+
+	"trpc.group/trpc-go/trpc-cmdline/descriptor"
+	"trpc.group/trpc-go/trpc-cmdline/params"
+	"trpc.group/trpc-go/trpc-cmdline/util/log"
+)
+
+const goProxyPluginName = "sync_goproxy"
+
+// GoProxy publishes the stubs as a module into a directory of the GOPROXY layout, which can be served
+// by a file server or used directly by GOPROXY=file:///path/to/dir.
+// For each version, <module>/@v/<version>.info, .mod and .zip are written and the version is appended
+// to <module>/@v/list, where the module path and the version are case-encoded.
+type GoProxy struct{}
+
+// NewGoProxy is a constructor for publishing stubs into a GOPROXY directory.
+func NewGoProxy() *GoProxy {
+	return &GoProxy{}
+}
+
+// Name of the GOPROXY publishing plugin.
+func (p *GoProxy) Name() string {
+	return goProxyPluginName
+}
+
+// Check checks whether to publish the stubs, which is never done in dry-run mode.
+func (p *GoProxy) Check(_ *descriptor.FileDescriptor, opt *params.Option) bool {
+	return opt.GoProxy != "" && !opt.DryRun
+}
+
+// Run publishes the stubs as a new version of the module, which is --tag, or decided by the versioning
+// setting of sync_git against the versions published before.
+func (p *GoProxy) Run(fd *descriptor.FileDescriptor, opt *params.Option) error {
+	settings, err := loadSettings(opt)
+	if err != nil {
+		return err
+	}
+	modDir, err := os.MkdirTemp("", "trpc-goproxy-")
+	if err != nil {
+		return fmt.Errorf("create temporary directory err: %w", err)
+	}
+	defer os.RemoveAll(modDir)
+	modulePath, err := stageModule(filepath.Join(opt.OutputDir, trpcGeneratedStubName), modDir, fd.GoPackage,
+		opt.GoVersion)
+	if err != nil {
+		return err
+	}
+	proxy := &goProxyModule{root: opt.GoProxy, path: modulePath}
+	versions, err := proxy.versions()
+	if err != nil {
+		return err
+	}
+	if latest, ok := latestSemver(versions); ok {
+		zipped, err := zipModule(modulePath, latest.String(), modDir)
+		if err != nil {
+			return err
+		}
+		if proxy.samePublished(latest.String(), zipped) {
+			log.Info("the generated stubs are identical to %s@%s, skip publishing", modulePath, latest)
+			return nil
+		}
+	}
+	idlPath := idlPathInGitDir(fd, opt, modDir, modDir)
+	version, err := proxy.nextVersion(fd, opt, settings, versions, idlPath)
+	if err != nil {
+		return err
+	}
+	zipped, err := zipModule(modulePath, version, modDir)
+	if err != nil {
+		return err
+	}
+	mod, err := os.ReadFile(filepath.Join(modDir, goMod))
+	if err != nil {
+		return fmt.Errorf("read %s err: %w", goMod, err)
+	}
+	if err := proxy.publish(version, mod, zipped); err != nil {
+		return err
+	}
+	log.Info("publish %s@%s into %s, use it by GOPROXY=file://%s", modulePath, version, opt.GoProxy,
+		filepath.ToSlash(opt.GoProxy))
+	return nil
+}
+
+// stageModule copies the stubs into dir, and makes sure there is a go.mod, whose module path is
+// goPackage if the stubs do not have one. The module path is returned.
+func stageModule(stubDir, dir, goPackage, goVersion string) (string, error) {
+	files, err := stubFiles(stubDir)
+	if err != nil {
+		return "", err
+	}
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return "", fmt.Errorf("read %s err: %w", f, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, filepath.Base(f)), b, 0644); err != nil {
+			return "", fmt.Errorf("write %s err: %w", filepath.Base(f), err)
+		}
+	}
+	modulePath := goPackage
+	if b, err := os.ReadFile(filepath.Join(dir, goMod)); err == nil {
+		if p := modfile.ModulePath(b); p != "" {
+			modulePath = p
+		}
+	}
+	if err := module.CheckPath(modulePath); err != nil {
+		return "", fmt.Errorf("invalid module path of the stubs: %w", err)
+	}
+	if err := writeModuleGoMod(filepath.Join(dir, goMod), modulePath, goVersion); err != nil {
+		return "", err
+	}
+	return modulePath, nil
+}
+
+// stubFiles returns the files of the stubs, which are the ones in the only directory of go_package under stubDir.
+func stubFiles(stubDir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(stubDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk stub dir %s err: %w", stubDir, err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("generated stub file is empty")
+	}
+	if !isSameDir(files) {
+		return nil, fmt.Errorf("generated stub file not is same dir")
+	}
+	return files, nil
+}
+
+// goProxyModule is a module inside the GOPROXY directory.
+type goProxyModule struct {
+	root string // Root of the GOPROXY directory.
+	path string // Module path.
+}
+
+// dir returns the @v directory of the module.
+func (m *goProxyModule) dir() (string, error) {
+	escaped, err := module.EscapePath(m.path)
+	if err != nil {
+		return "", fmt.Errorf("escape module path %s err: %w", m.path, err)
+	}
+	return filepath.Join(m.root, filepath.FromSlash(escaped), "@v"), nil
+}
+
+// file returns the path of the file of the version with the extension, such as .zip.
+func (m *goProxyModule) file(version, ext string) (string, error) {
+	dir, err := m.dir()
+	if err != nil {
+		return "", err
+	}
+	escaped, err := module.EscapeVersion(version)
+	if err != nil {
+		return "", fmt.Errorf("escape version %s err: %w", version, err)
+	}
+	return filepath.Join(dir, escaped+ext), nil
+}
+
+// versions returns the published versions listed in @v/list.
+func (m *goProxyModule) versions() ([]string, error) {
+	dir, err := m.dir()
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "list"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read version list of %s err: %w", m.path, err)
+	}
+	return strings.Fields(string(b)), nil
+}
+
+// nextVersion returns --tag, or the version decided by the versioning of settings after the published ones.
+// The major version must agree with the version suffix of the module path, as required by go modules.
+func (m *goProxyModule) nextVersion(fd *descriptor.FileDescriptor, opt *params.Option, settings *Settings,
+	versions []string, idlPath string) (string, error) {
+	version := opt.Tag
+	if version == "" {
+		version = m.evalVersion(fd, opt, settings, versions, idlPath)
+	}
+	if err := module.Check(m.path, version); err != nil {
+		if v, ok := parseSemver(version); ok && v.major >= 2 {
+			return "", fmt.Errorf("version %s can not be published for module %s, "+
+				"the go_package should end with /v%d: %w", version, m.path, v.major, err)
+		}
+		return "", fmt.Errorf("version %s can not be published for module %s: %w", version, m.path, err)
+	}
+	for _, v := range versions {
+		if v == version {
+			return "", fmt.Errorf("version %s of module %s is already published, "+
+				"which can not be changed", version, m.path)
+		}
+	}
+	return version, nil
+}
+
+// evalVersion bumps the latest published version like the tags of sync_git.
+func (m *goProxyModule) evalVersion(fd *descriptor.FileDescriptor, opt *params.Option, settings *Settings,
+	versions []string, idlPath string) string {
+	latest, ok := latestSemver(versions)
+	if settings.Versioning == VersioningBase100 {
+		if !ok {
+			return defaultGitTag
+		}
+		return genNewTagName(latest.String())
+	}
+	suffix := majorSuffix.FindString(m.path)
+	if !ok {
+		version := semver{major: suffixMajor(suffix)}.String()
+		log.Info("publish %s as the first version", version)
+		return version
+	}
+	old, err := m.publishedDescriptor(latest.String(), opt, idlPath)
+	noop := func(version string) string { return version }
+	return bumpSemver(latest, old, err, fd, suffixMajor(suffix), suffix, noop).String()
+}
+
+// latestSemver returns the highest semantic version of the versions.
+func latestSemver(versions []string) (semver, bool) {
+	var (
+		latest semver
+		found  bool
+	)
+	for _, s := range versions {
+		if v, ok := parseSemver(s); ok && (!found || latest.less(v)) {
+			latest, found = v, true
+		}
+	}
+	return latest, found
+}
+
+// publishedDescriptor parses the IDL file at idlPath inside the zip of the version.
+func (m *goProxyModule) publishedDescriptor(version string, opt *params.Option,
+	idlPath string) (*descriptor.FileDescriptor, error) {
+	if idlPath == "" {
+		return nil, errors.New("the idl file is unknown")
+	}
+	file, err := m.file(version, ".zip")
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("open %s err: %w", file, err)
+	}
+	defer zr.Close()
+	name := m.path + "@" + version + "/" + filepath.ToSlash(idlPath)
+	f, err := zr.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("%s of version %s err: %w", idlPath, version, err)
+	}
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("read %s of version %s err: %w", idlPath, version, err)
+	}
+	fd, err := parseIDLContent(idlPath, content, opt)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s of version %s err: %w", idlPath, version, err)
+	}
+	return fd, nil
+}
+
+// zipModule zips the files of the module in dir as the version by the rules of go modules.
+func zipModule(modulePath, version, dir string) ([]byte, error) {
+	var zipped bytes.Buffer
+	if err := modzip.CreateFromDir(&zipped, module.Version{Path: modulePath, Version: version}, dir); err != nil {
+		return nil, fmt.Errorf("zip module %s@%s err: %w", modulePath, version, err)
+	}
+	return zipped.Bytes(), nil
+}
+
+// samePublished reports whether the files of the published version are identical to the zipped ones.
+func (m *goProxyModule) samePublished(version string, zipped []byte) bool {
+	file, err := m.file(version, ".zip")
+	if err != nil {
+		return false
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return false
+	}
+	digest := zipDigest(b)
+	return digest != "" && digest == zipDigest(zipped)
+}
+
+// zipDigest returns the names, the sizes and the CRC-32 checksums of the files inside the zip.
+func zipDigest(b []byte) string {
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, f := range zr.File {
+		fmt.Fprintf(&sb, "%s %d %08x\n", f.Name, f.UncompressedSize64, f.CRC32)
+	}
+	return sb.String()
+}
+
+// goProxyInfo is the content of the .info file of a version.
+type goProxyInfo struct {
+	Version string
+	Time    time.Time
+}
+
+// publish writes the files of the version, which is appended to @v/list at last, so that the version
+// is not listed until all its files are written.
+func (m *goProxyModule) publish(version string, mod, zipped []byte) error {
+	dir, err := m.dir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return fmt.Errorf("create directory %s err: %w", dir, err)
+	}
+	info, err := json.Marshal(goProxyInfo{Version: version, Time: time.Now().UTC().Truncate(time.Second)})
+	if err != nil {
+		return fmt.Errorf("marshal info of %s err: %w", version, err)
+	}
+	for ext, content := range map[string][]byte{".info": info, ".mod": mod, ".zip": zipped} {
+		file, err := m.file(version, ext)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(file, content, 0644); err != nil {
+			return fmt.Errorf("write %s err: %w", file, err)
+		}
+	}
+	list, err := os.OpenFile(filepath.Join(dir, "list"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("open version list of %s err: %w", m.path, err)
+	}
+	defer list.Close()
+	if _, err := fmt.Fprintln(list, version); err != nil {
+		return fmt.Errorf("append %s to version list of %s err: %w", version, m.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package sync
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/module"
+	modzip "golang.org/x/mod/zip"
+
+	"trpc.group/trpc-go/trpc-cmdline/config"
+	"trpc.group/trpc-go/trpc-cmdline/params"
+)
+
+func TestGoProxy(t *testing.T) {
+	proxyDir, protoDir := t.TempDir(), t.TempDir()
+	p := NewGoProxy()
+	const modulePath = "trpc.group/Test/helloworld"
+	// publish generates the stubs of the proto file, which is copied along with them.
+	publish := func(content, tag string) error {
+		out := t.TempDir()
+		stub := filepath.Join(out, "stub", filepath.FromSlash(modulePath))
+		require.Nil(t, os.MkdirAll(stub, os.ModePerm))
+		require.Nil(t, os.WriteFile(filepath.Join(stub, "hello.pb.go"), []byte("package helloworld\n"), 0644))
+		require.Nil(t, os.WriteFile(filepath.Join(stub, "hello.proto"), []byte(content), 0644))
+		fd := parseProto(t, protoDir, content)
+		fd.GoPackage = modulePath
+		opt := &params.Option{OutputDir: out, GoProxy: proxyDir, Tag: tag, GoVersion: "1.18",
+			Protofile: "hello.proto", Protodirs: []string{protoDir}, IDLType: config.IDLTypeProtobuf}
+		require.True(t, p.Check(fd, opt))
+		return p.Run(fd, opt)
+	}
+	// The upper case letters of the module path are escaped.
+	dir := filepath.Join(proxyDir, "trpc.group", "!test", "helloworld", "@v")
+	list := func() string {
+		b, err := os.ReadFile(filepath.Join(dir, "list"))
+		require.Nil(t, err)
+		return string(b)
+	}
+
+	require.Nil(t, publish(helloProto, ""))
+	require.Equal(t, "v1.0.0\n", list())
+	b, err := os.ReadFile(filepath.Join(dir, "v1.0.0.mod"))
+	require.Nil(t, err)
+	require.Equal(t, "module trpc.group/Test/helloworld\n\ngo 1.18\n", string(b))
+	b, err = os.ReadFile(filepath.Join(dir, "v1.0.0.info"))
+	require.Nil(t, err)
+	var info goProxyInfo
+	require.Nil(t, json.Unmarshal(b, &info))
+	require.Equal(t, "v1.0.0", info.Version)
+	require.False(t, info.Time.IsZero())
+	files, err := modzip.CheckZip(module.Version{Path: modulePath, Version: "v1.0.0"},
+		filepath.Join(dir, "v1.0.0.zip"))
+	require.Nil(t, err)
+	require.Nil(t, files.Err())
+	prefix := modulePath + "@v1.0.0/"
+	require.ElementsMatch(t, []string{prefix + "go.mod", prefix + "hello.pb.go", prefix + "hello.proto"}, files.Valid)
+
+	// Identical stubs are not published again.
+	require.Nil(t, publish(helloProto, ""))
+	require.Equal(t, "v1.0.0\n", list())
+
+	added := replaceOnce(t, helloProto, "  Kind kind = 2;\n", "  Kind kind = 2;\n  int32 count = 3;\n")
+	require.Nil(t, publish(added, ""))
+	require.Equal(t, "v1.0.0\nv1.1.0\n", list())
+	require.Nil(t, publish(helloProto, "v1.1.5"))
+	require.Equal(t, "v1.0.0\nv1.1.0\nv1.1.5\n", list())
+
+	err = publish(added, "v1.1.0")
+	require.ErrorContains(t, err, "is already published")
+	// The major version can not be bumped without the version suffix of the module path.
+	err = publish(replaceOnce(t, helloProto, "  Kind kind = 2;\n", ""), "")
+	require.ErrorContains(t, err, "the go_package should end with /v2")
+	require.Equal(t, "v1.0.0\nv1.1.0\nv1.1.5\n", list())
+}
+
+func TestGoProxy_Base100(t *testing.T) {
+	m := &goProxyModule{root: t.TempDir(), path: "trpc.group/test/helloworld"}
+	settings := &Settings{Versioning: VersioningBase100}
+	version, err := m.nextVersion(nil, &params.Option{}, settings, nil, "")
+	require.Nil(t, err)
+	require.Equal(t, defaultGitTag, version)
+	version, err = m.nextVersion(nil, &params.Option{}, settings, []string{"v1.1.99", "v1.0.3"}, "")
+	require.Nil(t, err)
+	require.Equal(t, "v1.2.1", version)
+	_, err = m.nextVersion(nil, &params.Option{Tag: "1.0.0"}, settings, nil, "")
+	require.ErrorContains(t, err, "can not be published")
+}
@@ -0,0 +1,165 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+
+	"trpc.group/trpc-go/trpc-cmdline/descriptor"
+	"trpc.group/trpc-go/trpc-cmdline/params"
+)
+
+// Layouts of the stubs in the git repository, see Settings.Layout.
+const (
+	// LayoutFlat copies the stubs into the directory of the same name as the package found in the repository,
+	// or the path of go_package after the repository.
+	LayoutFlat = "flat"
+	// LayoutMonorepo copies the stubs into the path of go_package relative to the module path of the repository,
+	// each of which is a module with its own go.mod and tags prefixed by the path, such as app/server/v1.3.0.
+	LayoutMonorepo = "monorepo"
+)
+
+const goMod = "go.mod"
+
+// majorSuffix matches the major version suffix of a module path, such as /v2.
+var majorSuffix = regexp.MustCompile(`/v[2-9][0-9]*$`)
+
+// monorepoModule is the module of the stubs inside the monorepo.
+type monorepoModule struct {
+	path      string // Module path, which is go_package.
+	dir       string // Directory relative to the root of the repository, such as app/server.
+	tagPrefix string // Prefix of the tags, which is dir without the major version suffix, such as app/server.
+}
+
+// newMonorepoModule locates the module of go_package inside the repository, whose module path is root.
+func newMonorepoModule(goPackage, root string) (*monorepoModule, error) {
+	root = strings.TrimSuffix(root, gitURLPathSep)
+	if !strings.HasPrefix(goPackage, root+gitURLPathSep) {
+		return nil, fmt.Errorf("go_package %s is not inside the module path %s of the monorepo, "+
+			"configure module_path of %s", goPackage, root, pluginName)
+	}
+	dir := strings.TrimPrefix(goPackage, root+gitURLPathSep)
+	return &monorepoModule{
+		path:      goPackage,
+		dir:       dir,
+		tagPrefix: majorSuffix.ReplaceAllString(dir, ""),
+	}, nil
+}
+
+// tag returns the tag of the version, which is prefixed by the directory of the module.
+func (m *monorepoModule) tag(version string) string {
+	if m == nil || strings.HasPrefix(version, m.tagPrefix+gitURLPathSep) {
+		return version
+	}
+	return m.tagPrefix + gitURLPathSep + version
+}
+
+// modulePathOfURL returns the module path of the repository, which is the host and the path of its URL,
+// e.g. trpc.group/foo/bar for git@trpc.group:foo/bar.git.
+func modulePathOfURL(url string) (string, error) {
+	u, err := parseGitURLComponent("", url, "")
+	if err != nil {
+		return "", err
+	}
+	host := u.prefix
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+	host = strings.TrimRight(host, sshGitDomainSep+gitURLPathSep)
+	// Drop the port of ssh://host:port/.
+	if i := strings.Index(host, sshGitDomainSep); i >= 0 {
+		host = host[:i]
+	}
+	if host == "" {
+		return "", fmt.Errorf("no host in git url %s, configure module_path of %s", url, pluginName)
+	}
+	return path.Join(append([]string{host}, u.paths...)...), nil
+}
+
+// monorepoModuleOf returns the module of the stubs for the monorepo layout, or nil for the flat one.
+func (s *Git) monorepoModuleOf(fd *descriptor.FileDescriptor, settings *Settings,
+	remoteURL string) (*monorepoModule, error) {
+	if settings.Layout != LayoutMonorepo {
+		return nil, nil
+	}
+	root := settings.ModulePath
+	if root == "" {
+		var err error
+		if root, err = modulePathOfURL(remoteURL); err != nil {
+			return nil, err
+		}
+	}
+	return newMonorepoModule(fd.GoPackage, root)
+}
+
+// copyStubToModuleDir copies the stubs into the directory of the module, and maintains its go.mod.
+func (s *Git) copyStubToModuleDir(localStubDir, localGitDir string, m *monorepoModule,
+	opt *params.Option) (string, error) {
+	pbFiles, err := s.collectPBFileFullPaths(localStubDir)
+	if err != nil {
+		return "", err
+	}
+	dst := filepath.Join(localGitDir, filepath.FromSlash(m.dir))
+	if err := s.fileManager.MkdirAll(dst, os.ModePerm); err != nil {
+		return "", fmt.Errorf("mk git local destination dir %s error: %w", dst, err)
+	}
+	for _, f := range pbFiles {
+		if err := s.copyPBFileToGitDir(f, dst); err != nil {
+			return "", fmt.Errorf("copy stub to git local dir error: %w, localStubDir: %s, localGitDir: %s",
+				err, localStubDir, localGitDir)
+		}
+	}
+	if err := writeModuleGoMod(filepath.Join(dst, goMod), m.path, opt.GoVersion); err != nil {
+		return "", err
+	}
+	return dst, nil
+}
+
+// writeModuleGoMod makes the module path of go.mod the one of the stubs, which is created if it does not exist.
+// The requirements of an existing go.mod are kept.
+func writeModuleGoMod(file, modulePath, goVersion string) error {
+	b, err := os.ReadFile(file)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read %s err: %w", file, err)
+	}
+	f, err := modfile.Parse(file, b, nil)
+	if err != nil {
+		return fmt.Errorf("parse %s err: %w", file, err)
+	}
+	if f.Module != nil && f.Module.Mod.Path == modulePath {
+		return nil
+	}
+	if err := f.AddModuleStmt(modulePath); err != nil {
+		return fmt.Errorf("set module of %s err: %w", file, err)
+	}
+	if f.Go == nil && goVersion != "" {
+		if err := f.AddGoStmt(goVersion); err != nil {
+			return fmt.Errorf("set go version of %s err: %w", file, err)
+		}
+	}
+	if b, err = f.Format(); err != nil {
+		return fmt.Errorf("format %s err: %w", file, err)
+	}
+	if err := os.WriteFile(file, b, 0644); err != nil {
+		return fmt.Errorf("write %s err: %w", file, err)
+	}
+	return nil
+}
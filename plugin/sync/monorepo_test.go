@@ -0,0 +1,122 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-cmdline/config"
+	"trpc.group/trpc-go/trpc-cmdline/params"
+)
+
+func TestModulePathOfURL(t *testing.T) {
+	for url, want := range map[string]string{
+		"git@trpc.group:test/protocols.git":              "trpc.group/test/protocols",
+		"ssh://git@trpc.group:2222/test/protocols.git":   "trpc.group/test/protocols",
+		"https://oauth2@trpc.group/test/protocols":       "trpc.group/test/protocols",
+		"http://trpc.group/test/protocols/proto-all.git": "trpc.group/test/protocols/proto-all",
+	} {
+		got, err := modulePathOfURL(url)
+		require.Nil(t, err, url)
+		require.Equal(t, want, got)
+	}
+	_, err := modulePathOfURL("file:///srv/git/protocols.git")
+	require.ErrorContains(t, err, "configure module_path")
+}
+
+func TestMonorepoModule(t *testing.T) {
+	m, err := newMonorepoModule("trpc.group/test/protocols/app/server/v2", "trpc.group/test/protocols/")
+	require.Nil(t, err)
+	require.Equal(t, "app/server/v2", m.dir)
+	require.Equal(t, "app/server/v2.1.0", m.tag("v2.1.0"))
+	require.Equal(t, "app/server/v2.1.0", m.tag("app/server/v2.1.0"))
+	require.Equal(t, "v1.0.0", (*monorepoModule)(nil).tag("v1.0.0"))
+
+	_, err = newMonorepoModule("trpc.group/test/protocols", "trpc.group/test/protocols")
+	require.ErrorContains(t, err, "is not inside the module path")
+	_, err = newMonorepoModule("trpc.group/test/protocols-x/app", "trpc.group/test/protocols")
+	require.ErrorContains(t, err, "is not inside the module path")
+}
+
+func TestWriteModuleGoMod(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "go.mod")
+	require.Nil(t, writeModuleGoMod(file, "trpc.group/test/protocols/app/server", "1.18"))
+	b, err := os.ReadFile(file)
+	require.Nil(t, err)
+	require.Equal(t, "module trpc.group/test/protocols/app/server\n\ngo 1.18\n", string(b))
+
+	// The requirements are kept when the module path changes.
+	require.Nil(t, os.WriteFile(file, []byte("module old\n\ngo 1.20\n\nrequire trpc.group/trpc-go/trpc-go v1.0.0\n"),
+		0644))
+	require.Nil(t, writeModuleGoMod(file, "trpc.group/test/protocols/app/server/v2", "1.18"))
+	b, err = os.ReadFile(file)
+	require.Nil(t, err)
+	require.Equal(t, "module trpc.group/test/protocols/app/server/v2\n\ngo 1.20\n\n"+
+		"require trpc.group/trpc-go/trpc-go v1.0.0\n", string(b))
+}
+
+func TestSyncGit_Monorepo(t *testing.T) {
+	bare, _, _ := newLocalRemote(t)
+	protoDir := t.TempDir()
+	s := NewGit(DefaultFileManager, DefaultGitManager, AuthSupplier)
+	r, err := git.PlainOpen(bare)
+	require.Nil(t, err)
+	// sync generates the stubs of the proto file into the module of go_package.
+	sync := func(goPackage, content, tag string) {
+		out := t.TempDir()
+		stub := filepath.Join(out, "stub", filepath.FromSlash(goPackage))
+		require.Nil(t, os.MkdirAll(stub, os.ModePerm))
+		require.Nil(t, os.WriteFile(filepath.Join(stub, "hello.pb.go"), []byte(content), 0644))
+		fd := parseProto(t, protoDir, content)
+		fd.GoPackage = goPackage
+		require.Nil(t, os.WriteFile(filepath.Join(stub, "hello.proto"), []byte(content), 0644))
+		opt := &params.Option{Sync: true, OutputDir: out, Remote: "file://" + filepath.ToSlash(bare), NewTag: true,
+			Protofile: "hello.proto", Protodirs: []string{protoDir}, IDLType: config.IDLTypeProtobuf,
+			GoVersion: "1.18", PluginSettings: map[string]map[string]interface{}{pluginName: {
+				"layout": LayoutMonorepo, "module_path": "trpc.group/test/protocols"}}}
+		require.Nil(t, s.Run(fd, opt))
+		_, err = r.Tag(tag)
+		require.Nil(t, err, tag)
+	}
+	sync("trpc.group/test/protocols/app/server", helloProto, "app/server/v1.0.0")
+	sync("trpc.group/test/protocols/app/other", helloProto, "app/other/v1.0.0")
+	require.Contains(t, headCommit(t, r).Message, "sync stubs of hello.proto: add Greeter.SayHello\n",
+		"the rpcs are compared with the last commit of the module")
+	added := replaceOnce(t, helloProto, "  Kind kind = 2;\n", "  Kind kind = 2;\n  int32 count = 3;\n")
+	sync("trpc.group/test/protocols/app/server", added, "app/server/v1.1.0")
+	// The other module is compared with its own tag.
+	sync("trpc.group/test/protocols/app/other", added, "app/other/v1.1.0")
+
+	_, files := commitFiles(t, r, plumbing.HEAD)
+	require.Equal(t, []string{
+		"app/other/go.mod", "app/other/hello.pb.go", "app/other/hello.proto",
+		"app/server/go.mod", "app/server/hello.pb.go", "app/server/hello.proto",
+	}, files)
+	f, err := headCommit(t, r).File("app/server/go.mod")
+	require.Nil(t, err)
+	content, err := f.Contents()
+	require.Nil(t, err)
+	require.Equal(t, "module trpc.group/test/protocols/app/server\n\ngo 1.18\n", content)
+}
+
+func headCommit(t *testing.T, r *git.Repository) *object.Commit {
+	head, err := r.Head()
+	require.Nil(t, err)
+	c, err := r.CommitObject(head.Hash())
+	require.Nil(t, err)
+	return c
+}
@@ -0,0 +1,324 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"trpc.group/trpc-go/trpc-cmdline/descriptor"
+	"trpc.group/trpc-go/trpc-cmdline/params"
+	"trpc.group/trpc-go/trpc-cmdline/parser"
+	"trpc.group/trpc-go/trpc-cmdline/util/log"
+)
+
+// Versioning of the tags created by --newtag, see Settings.Versioning.
+const (
+	VersioningSemver  = "semver"  // Bump the semantic version by the API changes since the latest tag.
+	VersioningBase100 = "base100" // Increment the latest tag in base 100, such as v1.1.99 to v1.2.1.
+)
+
+var (
+	semverPattern        = regexp.MustCompile(`^v(\d+)\.(\d+)\.(\d+)$`)
+	versionSuffixPattern = regexp.MustCompile(`^/v(\d+)$`)
+)
+
+// semver is a semantic version of the form vMAJOR.MINOR.PATCH.
+type semver struct {
+	major, minor, patch int
+}
+
+func parseSemver(s string) (semver, bool) {
+	m := semverPattern.FindStringSubmatch(s)
+	if m == nil {
+		return semver{}, false
+	}
+	var v semver
+	for i, p := range []*int{&v.major, &v.minor, &v.patch} {
+		n, err := strconv.Atoi(m[i+1])
+		if err != nil {
+			return semver{}, false
+		}
+		*p = n
+	}
+	return v, true
+}
+
+func (v semver) String() string {
+	return fmt.Sprintf("v%d.%d.%d", v.major, v.minor, v.patch)
+}
+
+func (v semver) less(o semver) bool {
+	if v.major != o.major {
+		return v.major < o.major
+	}
+	if v.minor != o.minor {
+		return v.minor < o.minor
+	}
+	return v.patch < o.patch
+}
+
+// bump returns the next version by the level of the changes. The major version 0 is not bumped,
+// as its minor version is allowed to break the users.
+func (v semver) bump(level int) semver {
+	switch {
+	case level == bumpMajor && v.major > 0:
+		return semver{major: v.major + 1}
+	case level >= bumpMinor:
+		return semver{major: v.major, minor: v.minor + 1}
+	default:
+		return semver{major: v.major, minor: v.minor, patch: v.patch + 1}
+	}
+}
+
+// suffixMajor returns the major version of the version suffix of the module path, such as 2 of /v2,
+// which is 1 if there is no suffix.
+func suffixMajor(versionSuffix string) int {
+	m := versionSuffixPattern.FindStringSubmatch(versionSuffix)
+	if m == nil {
+		return 1
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil || n < 2 {
+		return 1
+	}
+	return n
+}
+
+// nextTag returns the tag of the commit, which is empty if --newtag is not set.
+// The tags of the module m of the monorepo are prefixed by its directory, and m is nil for the flat layout.
+// idlPath is the path of the IDL file copied into the git repository, by which the API of the latest tag is read.
+func (s *Git) nextTag(r *git.Repository, fd *descriptor.FileDescriptor, opt *params.Option,
+	settings *Settings, m *monorepoModule, idlPath string) (string, error) {
+	if !opt.NewTag {
+		return "", nil
+	}
+	if opt.Tag != "" {
+		tag := m.tag(opt.Tag)
+		return tag, s.tagExists(r, tag)
+	}
+	if settings.Versioning == VersioningBase100 {
+		if m == nil {
+			return s.evalTagName(r)
+		}
+		latest, latestRef, err := s.latestSemverTag(r, m)
+		if err != nil || latestRef == nil {
+			return m.tag(defaultGitTag), err
+		}
+		return m.tag(genNewTagName(latest.String())), nil
+	}
+	return s.evalSemverTag(r, fd, opt, m, idlPath)
+}
+
+// evalSemverTag bumps the latest semantic version tag by the API changes since then, and prints the decision.
+func (s *Git) evalSemverTag(r *git.Repository, fd *descriptor.FileDescriptor, opt *params.Option,
+	m *monorepoModule, idlPath string) (string, error) {
+	latest, latestRef, err := s.latestSemverTag(r, m)
+	if err != nil {
+		return "", err
+	}
+	major := suffixMajor(opt.VersionSuffix)
+	if m != nil {
+		// The module path of the monorepo module may end with the major version suffix.
+		if n := suffixMajor(majorSuffix.FindString(m.path)); n > major {
+			major = n
+		}
+	}
+	if latestRef == nil {
+		tag := m.tag(semver{major: major}.String())
+		log.Info("tag %s as the first version", tag)
+		return tag, nil
+	}
+	old, err := s.taggedDescriptor(r, latestRef, opt, idlPath)
+	next := bumpSemver(latest, old, err, fd, major, opt.VersionSuffix, m.tag)
+	// Go can not resolve the tag vN.x.x for N >= 2 unless the module path ends with /vN.
+	if next.major >= 2 && major != next.major {
+		return "", fmt.Errorf("the major version is bumped to %d, but the go module path does not end with /v%d, "+
+			"please regenerate the stubs with --versionsuffix v%d", next.major, next.major, next.major)
+	}
+	return m.tag(next.String()), nil
+}
+
+// bumpSemver bumps the latest version by the API changes of fd since old, which is the descriptor of the IDL file
+// at the latest version, or oldErr if it can not be read, and prints the decision.
+// The major version is at least the one of the version suffix of the module path, and name formats the versions.
+func bumpSemver(latest semver, old *descriptor.FileDescriptor, oldErr error, fd *descriptor.FileDescriptor,
+	major int, versionSuffix string, name func(string) string) semver {
+	var diff apiDiff
+	if oldErr != nil {
+		diff.add(bumpMinor, "the api of %s can not be compared: %v", latest, oldErr)
+	} else {
+		diff = diffAPI(old, fd)
+	}
+	if len(diff) == 0 {
+		diff.add(bumpPatch, "no api changes")
+	}
+	level := diff.level()
+	next := latest.bump(level)
+	if next.major < major {
+		next, level = semver{major: major}, bumpMajor
+		diff.add(bumpMajor, "the module path has the version suffix %s", versionSuffix)
+	}
+	reasons := make([]string, 0, len(diff))
+	for _, c := range diff {
+		reasons = append(reasons, fmt.Sprintf("  - [%s] %s", bumpNames[c.level], c.reason))
+	}
+	log.Info("tag %s, a %s version after %s:\n%s", name(next.String()), bumpNames[level],
+		name(latest.String()), strings.Join(reasons, "\n"))
+	return next
+}
+
+// latestSemverTag returns the highest semantic version among the tags of the module and its reference,
+// which is nil if there is none.
+func (s *Git) latestSemverTag(r *git.Repository, m *monorepoModule) (semver, *plumbing.Reference, error) {
+	tags, err := s.gitManager.Tags(r)
+	if err != nil {
+		return semver{}, nil, fmt.Errorf("git get tags err: %w", err)
+	}
+	var (
+		latest    semver
+		latestRef *plumbing.Reference
+	)
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+		if m != nil {
+			name = strings.TrimPrefix(name, m.tagPrefix+gitURLPathSep)
+		}
+		v, ok := parseSemver(name)
+		if ok && (latestRef == nil || latest.less(v)) {
+			latest, latestRef = v, ref
+		}
+		return nil
+	})
+	if err != nil {
+		return semver{}, nil, fmt.Errorf("git iterate tags err: %w", err)
+	}
+	return latest, latestRef, nil
+}
+
+// taggedDescriptor parses the IDL file at idlPath of the tagged commit, whose imports are searched in opt.Protodirs.
+func (s *Git) taggedDescriptor(r *git.Repository, ref *plumbing.Reference, opt *params.Option,
+	idlPath string) (*descriptor.FileDescriptor, error) {
+	if idlPath == "" {
+		return nil, errors.New("the idl file is unknown")
+	}
+	hash := ref.Hash()
+	// An annotated tag points to the tag object, which targets the commit.
+	if tag, err := s.gitManager.TagObject(r, hash); err == nil {
+		hash = tag.Target
+	}
+	c, err := s.gitManager.CommitObject(r, hash)
+	if err != nil {
+		return nil, fmt.Errorf("git commit object of tag %s err: %w", ref.Name().Short(), err)
+	}
+	f, err := c.File(path.Clean(filepath.ToSlash(idlPath)))
+	if err != nil {
+		return nil, fmt.Errorf("%s of tag %s err: %w", idlPath, ref.Name().Short(), err)
+	}
+	content, err := f.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("read %s of tag %s err: %w", idlPath, ref.Name().Short(), err)
+	}
+	fd, err := parseIDLContent(idlPath, []byte(content), opt)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s of tag %s err: %w", idlPath, ref.Name().Short(), err)
+	}
+	return fd, nil
+}
+
+// parseIDLContent parses the content of the IDL file, which is an older version of opt.Protofile,
+// or the file of the same base name as idlPath if it is unknown. Its imports are searched in opt.Protodirs.
+func parseIDLContent(idlPath string, content []byte, opt *params.Option) (*descriptor.FileDescriptor, error) {
+	dir, err := os.MkdirTemp("", "trpc-sync-")
+	if err != nil {
+		return nil, fmt.Errorf("create temporary directory err: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	name := opt.Protofile
+	if filepath.IsAbs(name) || name == "" {
+		name = filepath.Base(idlPath)
+	}
+	dirs, err := shadowProtodirs(dir, opt.Protodirs, name, content)
+	if err != nil {
+		return nil, err
+	}
+	return parser.Parse(name, dirs, opt.IDLType)
+}
+
+// shadowProtodirs returns the search paths in which the IDL file name has the old content.
+// Each of the protodirs containing the current file is replaced by a mirror of symbolic links under tmp,
+// so that the other files it imports are still found.
+func shadowProtodirs(tmp string, protodirs []string, name string, content []byte) ([]string, error) {
+	dirs := make([]string, 0, len(protodirs)+1)
+	shadowed := false
+	for i, d := range protodirs {
+		if _, err := os.Stat(filepath.Join(d, name)); err != nil {
+			dirs = append(dirs, d)
+			continue
+		}
+		abs, err := filepath.Abs(d)
+		if err != nil {
+			return nil, fmt.Errorf("get absolute path of %s err: %w", d, err)
+		}
+		mirror := filepath.Join(tmp, strconv.Itoa(i))
+		if err := mirrorDir(abs, mirror, name); err != nil {
+			return nil, fmt.Errorf("mirror %s err: %w", d, err)
+		}
+		if err := os.WriteFile(filepath.Join(mirror, name), content, 0644); err != nil {
+			return nil, fmt.Errorf("write %s err: %w", name, err)
+		}
+		dirs, shadowed = append(dirs, mirror), true
+	}
+	if shadowed {
+		return dirs, nil
+	}
+	mirror := filepath.Join(tmp, "idl")
+	if err := os.MkdirAll(filepath.Dir(filepath.Join(mirror, name)), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("create directory of %s err: %w", name, err)
+	}
+	if err := os.WriteFile(filepath.Join(mirror, name), content, 0644); err != nil {
+		return nil, fmt.Errorf("write %s err: %w", name, err)
+	}
+	return append([]string{mirror}, dirs...), nil
+}
+
+// mirrorDir links the entries of src into dst except the ones along the path of name,
+// whose directories are mirrored recursively and whose file is left out.
+func mirrorDir(src, dst, name string) error {
+	first, rest, nested := strings.Cut(filepath.ToSlash(name), "/")
+	if err := os.MkdirAll(dst, os.ModePerm); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.Name() == first {
+			continue
+		}
+		if err := os.Symlink(filepath.Join(src, e.Name()), filepath.Join(dst, e.Name())); err != nil {
+			return err
+		}
+	}
+	if nested {
+		return mirrorDir(filepath.Join(src, first), filepath.Join(dst, first), rest)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-cmdline/config"
+	"trpc.group/trpc-go/trpc-cmdline/descriptor"
+	"trpc.group/trpc-go/trpc-cmdline/params"
+	"trpc.group/trpc-go/trpc-cmdline/parser"
+)
+
+const helloProto = `syntax = "proto3";
+package trpc.test.helloworld;
+option go_package = "trpc.group/test/helloworld";
+
+service Greeter {
+  // SayHello says hello.
+  rpc SayHello(HelloRequest) returns (HelloReply);
+}
+
+message HelloRequest {
+  string msg = 1;
+  Kind kind = 2;
+}
+
+message HelloReply {
+  string msg = 1;
+}
+
+enum Kind {
+  KIND_UNSPECIFIED = 0;
+  KIND_FRIEND = 1;
+}
+`
+
+// parseProto writes the proto file into dir and parses it.
+func parseProto(t *testing.T, dir, content string) *descriptor.FileDescriptor {
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "hello.proto"), []byte(content), 0644))
+	fd, err := parser.Parse("hello.proto", []string{dir}, config.IDLTypeProtobuf)
+	require.Nil(t, err)
+	return fd
+}
+
+func TestDiffAPI(t *testing.T) {
+	dir := t.TempDir()
+	old := parseProto(t, dir, helloProto)
+	for _, tt := range []struct {
+		name    string
+		old     string
+		new     string
+		level   int
+		reasons []string
+	}{
+		{"identical", helloProto, helloProto, bumpPatch, nil},
+		{"comment", "// SayHello says hello.", "// SayHello greets.", bumpPatch,
+			[]string{"change comments of rpc Greeter.SayHello"}},
+		{"add rpc", "rpc SayHello(HelloRequest) returns (HelloReply);",
+			"rpc SayHello(HelloRequest) returns (HelloReply);\n  rpc Hi(HelloRequest) returns (HelloReply);",
+			bumpMinor, []string{"add rpc Greeter.Hi"}},
+		{"add field", "  Kind kind = 2;\n", "  Kind kind = 2;\n  int32 count = 3;\n", bumpMinor,
+			[]string{"add field trpc.test.helloworld.HelloRequest.count = 3"}},
+		{"rename rpc", "rpc SayHello(", "rpc Greet(", bumpMajor,
+			[]string{"add rpc Greeter.Greet", "remove rpc Greeter.SayHello"}},
+		{"rename field", "string msg = 1;\n  Kind", "string text = 1;\n  Kind", bumpMajor,
+			[]string{"rename field trpc.test.helloworld.HelloRequest.msg to text"}},
+		{"change type", "Kind kind = 2;", "repeated Kind kind = 2;", bumpMajor,
+			[]string{"change type of field trpc.test.helloworld.HelloRequest.kind from " +
+				"trpc.test.helloworld.Kind to repeated trpc.test.helloworld.Kind"}},
+		{"remove enum value", "  KIND_FRIEND = 1;\n", "", bumpMajor,
+			[]string{"remove enum value trpc.test.helloworld.Kind.KIND_FRIEND = 1"}},
+		{"change response", "returns (HelloReply);", "returns (HelloRequest);", bumpMajor,
+			[]string{"change response type of rpc Greeter.SayHello from " +
+				"trpc.test.helloworld.HelloReply to trpc.test.helloworld.HelloRequest"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cur := parseProto(t, dir, replaceOnce(t, helloProto, tt.old, tt.new))
+			diff := diffAPI(old, cur)
+			var reasons []string
+			for _, c := range diff {
+				reasons = append(reasons, c.reason)
+			}
+			require.Equal(t, tt.reasons, reasons)
+			require.Equal(t, tt.level, diff.level())
+		})
+	}
+}
+
+func replaceOnce(t *testing.T, s, old, new string) string {
+	require.Contains(t, s, old)
+	return strings.Replace(s, old, new, 1)
+}
+
+func TestSemverBump(t *testing.T) {
+	v, ok := parseSemver("v1.2.3")
+	require.True(t, ok)
+	require.Equal(t, "v1.2.4", v.bump(bumpPatch).String())
+	require.Equal(t, "v1.3.0", v.bump(bumpMinor).String())
+	require.Equal(t, "v2.0.0", v.bump(bumpMajor).String())
+	v, _ = parseSemver("v0.3.1")
+	require.Equal(t, "v0.4.0", v.bump(bumpMajor).String())
+	_, ok = parseSemver("v1.2")
+	require.False(t, ok)
+	require.True(t, semver{1, 9, 0}.less(semver{1, 10, 0}))
+	require.Equal(t, 1, suffixMajor(""))
+	require.Equal(t, 3, suffixMajor("/v3"))
+}
+
+func TestSyncGit_SemverTag(t *testing.T) {
+	bare, out, stub := newLocalRemote(t)
+	protoDir := t.TempDir()
+	s := NewGit(DefaultFileManager, DefaultGitManager, AuthSupplier)
+	opt := &params.Option{Sync: true, OutputDir: out, Remote: "file://" + filepath.ToSlash(bare), NewTag: true,
+		Protofile: "hello.proto", Protodirs: []string{protoDir}, IDLType: config.IDLTypeProtobuf}
+	r, err := git.PlainOpen(bare)
+	require.Nil(t, err)
+	// run generates the stubs of the proto file, which is copied along with them.
+	run := func(content string) error {
+		fd := parseProto(t, protoDir, content)
+		b, err := os.ReadFile(filepath.Join(protoDir, "hello.proto"))
+		require.Nil(t, err)
+		require.Nil(t, os.WriteFile(filepath.Join(stub, "hello.proto"), b, 0644))
+		return s.Run(fd, opt)
+	}
+	sync := func(content, tag string) {
+		require.Nil(t, run(content))
+		_, err = r.Tag(tag)
+		require.Nil(t, err, tag)
+	}
+	sync(helloProto, "v1.0.0")
+	sync(replaceOnce(t, helloProto, "// SayHello says hello.", "// SayHello greets."), "v1.0.1")
+	sync(replaceOnce(t, helloProto, "  KIND_FRIEND = 1;\n", "  KIND_FRIEND = 1;\n  KIND_FAMILY = 2;\n"), "v1.1.0")
+
+	// The breaking changes are not tagged as v2 without the version suffix, which go can not resolve.
+	removed := replaceOnce(t, helloProto, "  Kind kind = 2;\n", "")
+	require.ErrorContains(t, run(removed), "please regenerate the stubs with --versionsuffix v2")
+	_, err = r.Tag("v2.0.0")
+	require.ErrorIs(t, err, git.ErrTagNotFound)
+	opt.VersionSuffix = "/v2"
+	sync(removed, "v2.0.0")
+
+	// The version suffix of the module path decides the major version.
+	opt.VersionSuffix = "/v3"
+	sync(helloProto, "v3.0.0")
+}
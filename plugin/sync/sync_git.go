@@ -21,6 +21,7 @@ import (
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport"
 
@@ -30,6 +31,7 @@ import (
 )
 
 const (
+	pluginName            = "sync_git"
 	sshGitURLPrefix       = "git@"
 	sshGitDomainSep       = ":"
 	gitURLSuffix          = ".git"
@@ -42,8 +44,8 @@ const (
 
 // Git provides git synchronization capabilities.
 type Git struct {
-	auth   transport.AuthMethod
-	newErr error
+	auth     transport.AuthMethod
+	supplier func(FileManager, GitManager, *transport.Endpoint, *Settings) (transport.AuthMethod, error)
 
 	fileManager FileManager
 	gitManager  GitManager
@@ -52,50 +54,48 @@ type Git struct {
 // NewGit is a constructor for syncing stubs to a git repository.
 // fileManager: File management interface
 // gitManager: Git management interface
-// supplier: Injection of Git access authorization methods
+// supplier: Injection of Git access authorization methods, which is called with the remote endpoint
+// and the settings of the plugin on each run
 func NewGit(
 	fileManager FileManager,
 	gitManager GitManager,
-	supplier func(FileManager, GitManager) (transport.AuthMethod, error),
+	supplier func(FileManager, GitManager, *transport.Endpoint, *Settings) (transport.AuthMethod, error),
 ) *Git {
-	sg := &Git{
+	return &Git{
 		fileManager: fileManager,
 		gitManager:  gitManager,
+		supplier:    supplier,
 	}
-	sg.auth, sg.newErr = supplier(fileManager, gitManager)
-	return sg
-}
-
-// AuthSupplier provides access to a git remote repository via SSH by default.
-func AuthSupplier(fileManager FileManager, gitManager GitManager) (transport.AuthMethod, error) {
-	homePath, err := fileManager.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("os user home dir err: %w", err)
-	}
-	rsaFilePath := filepath.Join(homePath, ".ssh/id_rsa")
-	publicKeys, err := gitManager.NewPublicKeysFromFile("git", rsaFilePath, "")
-	if err != nil {
-		return nil, fmt.Errorf("git ssh new public keys from file error: %w, file location %s", err, rsaFilePath)
-	}
-	return publicKeys, nil
 }
 
 // Name of the remote git repository sync plugin.
 func (s *Git) Name() string {
-	return "sync_git"
+	return pluginName
 }
 
-// Check checks whether to perform remote synchronization.
+// Check checks whether to perform remote synchronization, which is never done in dry-run mode.
 func (s *Git) Check(_ *descriptor.FileDescriptor, opt *params.Option) bool {
-	return opt.Sync
+	return opt.Sync && !opt.DryRun
 }
 
 // Run syncs the remote Git repository.
 func (s *Git) Run(fd *descriptor.FileDescriptor, opt *params.Option) error {
-	if s.newErr != nil {
-		return s.newErr
+	settings, err := loadSettings(opt)
+	if err != nil {
+		return err
+	}
+	u, err := parseGitURLComponent(fd.GoPackage, opt.Remote, settings.Scheme)
+	if err != nil {
+		return err
+	}
+	ep, err := transport.NewEndpoint(u.String(len(u.paths)))
+	if err != nil {
+		return fmt.Errorf("parse git url %s err: %w", u.String(len(u.paths)), err)
+	}
+	if s.auth, err = s.supplier(s.fileManager, s.gitManager, ep, settings); err != nil {
+		return err
 	}
-	gitDir, r, err := s.cloneOrInitGitDir(fd.GoPackage, opt)
+	gitDir, r, err := s.cloneOrInitGitDir(u, opt)
 	if err != nil {
 		return err
 	}
@@ -104,51 +104,167 @@ func (s *Git) Run(fd *descriptor.FileDescriptor, opt *params.Option) error {
 			log.Error("sync git plugin run file manager remove dir:%s error:%v", gitDir, err)
 		}
 	}()
+	info := newCommitInfo(fd, opt)
+	branch := opt.Branch
+	if branch == "" {
+		branch = settings.Branch
+	}
+	branch = info.branchName(branch)
+	if branch != "" {
+		if err := s.checkoutBranch(r, branch); err != nil {
+			return err
+		}
+	}
+	module, err := s.monorepoModuleOf(fd, settings, s.getRemoteGitURL(r))
+	if err != nil {
+		return err
+	}
 	outStubDir := filepath.Join(opt.OutputDir, trpcGeneratedStubName)
-	if err := s.copyStubToGitDir(outStubDir, gitDir, s.getRemoteGitURLBase(r)); err != nil {
+	var dstDir string
+	if module != nil {
+		dstDir, err = s.copyStubToModuleDir(outStubDir, gitDir, module, opt)
+	} else {
+		dstDir, err = s.copyStubToGitDir(outStubDir, gitDir, s.getRemoteGitURLBase(r))
+	}
+	if err != nil {
+		return err
+	}
+	w, changed, err := s.addGitDir(r)
+	if err != nil {
 		return err
 	}
-	return s.commitAndPushGitDir(r, opt)
+	if !changed {
+		log.Info("the generated stubs are identical to the remote git repository, skip the commit")
+		return nil
+	}
+	info.diffRPCs(s.lastCommitMessage(r, module))
+	msg, err := info.commitMessage(settings.CommitMessage)
+	if err != nil {
+		return err
+	}
+	var tag string
+	if opt.Patch == "" {
+		idlPath := idlPathInGitDir(fd, opt, gitDir, dstDir)
+		if tag, err = s.nextTag(r, fd, opt, settings, module, idlPath); err != nil {
+			return err
+		}
+	}
+	return s.commitAndPushGitDir(r, w, opt, msg, branch, tag)
 }
 
-func (s *Git) cloneOrInitGitDir(goPackage string, opt *params.Option) (string, *git.Repository, error) {
-	urlPrefix, paths, err := parseGitURLComponent(goPackage, opt)
+// idlPathInGitDir returns the path of the IDL file copied along with the stubs relative to the git directory.
+func idlPathInGitDir(fd *descriptor.FileDescriptor, opt *params.Option, gitDir, dstDir string) string {
+	name := fd.FilePath
+	if name == "" {
+		name = opt.Protofile
+	}
+	if name == "" {
+		return ""
+	}
+	name = filepath.Base(name)
+	rel, err := filepath.Rel(gitDir, dstDir)
 	if err != nil {
-		return "", nil, err
+		return name
+	}
+	return filepath.Join(rel, name)
+}
+
+// checkoutBranch checks out the branch if it exists in the remote, otherwise the commit is based on
+// the default branch and pushed as the new branch.
+func (s *Git) checkoutBranch(r *git.Repository, branch string) error {
+	remoteRef, err := s.gitManager.Reference(r, plumbing.NewRemoteReferenceName("origin", branch), true)
+	if err == plumbing.ErrReferenceNotFound {
+		log.Debug("branch %s does not exist in the remote, create it from the default branch", branch)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("git reference of remote branch %s err: %w", branch, err)
 	}
+	w, err := s.gitManager.Worktree(r)
+	if err != nil {
+		return fmt.Errorf("git repository work tree err: %w", err)
+	}
+	name := plumbing.NewBranchReferenceName(branch)
+	opts := &git.CheckoutOptions{Branch: name}
+	// The default branch is checked out by clone, the others are created from the remote ones.
+	if head, err := s.gitManager.Head(r); err != nil || head.Name() != name {
+		opts.Hash, opts.Create = remoteRef.Hash(), true
+	}
+	if err := s.gitManager.Checkout(w, opts); err != nil {
+		return fmt.Errorf("git checkout branch %s err: %w", branch, err)
+	}
+	return nil
+}
+
+// lastCommitMessage returns the message of HEAD, or the latest commit changing the module of the monorepo,
+// which is empty for a new repository.
+func (s *Git) lastCommitMessage(r *git.Repository, m *monorepoModule) string {
+	head, err := s.gitManager.Head(r)
+	if err != nil {
+		return ""
+	}
+	if m == nil {
+		c, err := s.gitManager.CommitObject(r, head.Hash())
+		if err != nil {
+			log.Debug("git commit object of %s err: %v", head.Hash(), err)
+			return ""
+		}
+		return c.Message
+	}
+	commits, err := s.gitManager.Log(r, &git.LogOptions{From: head.Hash(), PathFilter: func(p string) bool {
+		return strings.HasPrefix(p, m.dir+gitURLPathSep)
+	}})
+	if err != nil {
+		log.Debug("git log of %s err: %v", m.dir, err)
+		return ""
+	}
+	defer commits.Close()
+	c, err := commits.Next()
+	if err != nil {
+		return ""
+	}
+	return c.Message
+}
+
+func (s *Git) cloneOrInitGitDir(u *gitURL, opt *params.Option) (string, *git.Repository, error) {
 	tempGitDir := filepath.Join(opt.OutputDir, "stub_temp")
 	if err := s.fileManager.RemoveAll(tempGitDir); err != nil {
 		return "", nil, err
 	}
-	r, err := s.cloneOrInitGitRepo(urlPrefix+sshGitDomainSep, tempGitDir, paths)
+	r, err := s.cloneOrInitGitRepo(u, tempGitDir, len(u.paths))
 	if err != nil {
 		return "", nil, err
 	}
 	return tempGitDir, r, nil
 }
 
-// cloneOrInitGitRepo recursively constructs git url using path.
+// cloneOrInitGitRepo recursively constructs git url using the first n paths.
 // For example, the git address of trpc.group/veteranchen/test/helloworld may be:
 // trpc.group/veteranchen.git
 // trpc.group/veteranchen/test.git
 // trpc.group/veteranchen/test/helloworld.git
-func (s *Git) cloneOrInitGitRepo(urlPrefix string, tempDir string, paths []string) (*git.Repository, error) {
-	if len(paths) == 0 {
+func (s *Git) cloneOrInitGitRepo(u *gitURL, tempDir string, n int) (*git.Repository, error) {
+	if n == 0 {
 		return nil, fmt.Errorf("not found clone git repository, please create git repository")
 	}
-	gitURL := urlPrefix + strings.Join(paths, gitURLPathSep) + gitURLSuffix
+	url := u.String(n)
 	r, err := s.gitManager.PlainClone(tempDir, false, &git.CloneOptions{
 		Auth:              s.auth,
-		URL:               gitURL,
+		URL:               url,
 		RecurseSubmodules: git.DefaultSubmoduleRecursionDepth,
 	})
 	if err == transport.ErrEmptyRemoteRepository {
-		return s.initGitRepo(tempDir, gitURL)
+		return s.initGitRepo(tempDir, url)
 	}
 	if err == nil && r != nil {
 		return r, nil
 	}
-	return s.cloneOrInitGitRepo(urlPrefix, tempDir, paths[:len(paths)-1])
+	log.Debug("git clone %s err: %v", url, err)
+	// A failed clone may leave the directory behind.
+	if err := s.fileManager.RemoveAll(tempDir); err != nil {
+		return nil, err
+	}
+	return s.cloneOrInitGitRepo(u, tempDir, n-1)
 }
 
 func (s *Git) initGitRepo(tempDir, gitURL string) (*git.Repository, error) {
@@ -165,47 +281,109 @@ func (s *Git) initGitRepo(tempDir, gitURL string) (*git.Repository, error) {
 	return r, nil
 }
 
-// parseGitURLComponent parses the given git URL and returns the prefix, as well as the array of path components.
-func parseGitURLComponent(goPackage string, opt *params.Option) (string, []string, error) {
-	gitURL := opt.Remote
-	if gitURL == "" {
-		gitURL = sshGitURLPrefix + strings.Replace(goPackage, gitURLPathSep, sshGitDomainSep, 1) + gitURLSuffix
+// gitURL is the URL of the remote git repository, whose paths are split, so that the repositories
+// of the parent paths can be tried.
+type gitURL struct {
+	prefix string   // Such as git@trpc.group:, https://trpc.group/ or file:///.
+	paths  []string // Path components.
+	suffix string   // .git or empty.
+}
+
+// String returns the URL with the first n paths.
+func (u *gitURL) String(n int) string {
+	return u.prefix + strings.Join(u.paths[:n], gitURLPathSep) + u.suffix
+}
+
+// parseGitURLComponent parses the remote git URL, which is derived from goPackage by the scheme if remote is empty.
+// The URLs supported are [user@]host:path, ssh://[user@]host[:port]/path, http(s)://host/path and file:///path.
+func parseGitURLComponent(goPackage, remote, scheme string) (*gitURL, error) {
+	if remote == "" {
+		if scheme == schemeHTTPS {
+			remote = "https://" + goPackage + gitURLSuffix
+		} else {
+			remote = sshGitURLPrefix + strings.Replace(goPackage, gitURLPathSep, sshGitDomainSep, 1) + gitURLSuffix
+		}
 	}
-	if !strings.Contains(gitURL, sshGitDomainSep) {
-		return "", nil, fmt.Errorf("ssh git url pattern is invalid %s", gitURL)
+	u := &gitURL{}
+	var path string
+	if i := strings.Index(remote, "://"); i >= 0 {
+		switch remote[:i] {
+		case "ssh", "http", "https", "file":
+		default:
+			return nil, fmt.Errorf("git url %s is invalid, unsupported scheme %s", remote, remote[:i])
+		}
+		// The path starts at the first slash after the host, the host is empty for file.
+		rest := remote[i+3:]
+		j := strings.Index(rest, gitURLPathSep)
+		if j < 0 {
+			return nil, fmt.Errorf("git url %s is invalid, no path", remote)
+		}
+		u.prefix, path = remote[:i+3+j+1], rest[j+1:]
+	} else {
+		// The scp-like syntax, such as git@trpc.group:foo/bar.git.
+		i := strings.Index(remote, sshGitDomainSep)
+		if i <= 0 || strings.Contains(remote[:i], gitURLPathSep) {
+			return nil, fmt.Errorf("git url %s is invalid, supported: [user@]host:path, "+
+				"ssh://[user@]host[:port]/path, http(s)://host/path, file:///path", remote)
+		}
+		u.prefix, path = remote[:i+1], remote[i+1:]
 	}
-	if !strings.HasPrefix(gitURL, sshGitURLPrefix) {
-		return "", nil, fmt.Errorf("ssh git url pattern is invalid %s", gitURL)
+	if strings.HasSuffix(path, gitURLSuffix) {
+		u.suffix, path = gitURLSuffix, strings.TrimSuffix(path, gitURLSuffix)
 	}
-	if !strings.HasSuffix(gitURL, gitURLSuffix) {
-		return "", nil, fmt.Errorf("ssh git url pattern is invalid %s", gitURL)
+	path = strings.Trim(path, gitURLPathSep)
+	if path == "" {
+		return nil, fmt.Errorf("git url %s is invalid, no path", remote)
 	}
-	urlComps := strings.Split(gitURL, sshGitDomainSep)
-	return urlComps[0], strings.Split(strings.TrimSuffix(urlComps[1], gitURLSuffix), gitURLPathSep), nil
+	u.paths = strings.Split(path, gitURLPathSep)
+	return u, nil
 }
 
-func (s *Git) commitAndPushGitDir(r *git.Repository, opt *params.Option) error {
+// addGitDir adds the stubs to the index, and reports whether they are changed.
+func (s *Git) addGitDir(r *git.Repository) (*git.Worktree, bool, error) {
 	w, err := s.gitManager.Worktree(r)
 	if err != nil {
-		return fmt.Errorf("git repository work tree err: %w", err)
+		return nil, false, fmt.Errorf("git repository work tree err: %w", err)
 	}
 	if err := s.gitManager.AddWithOptions(w, &git.AddOptions{
 		All: true,
 	}); err != nil {
-		return fmt.Errorf("git add err: %w", err)
+		return nil, false, fmt.Errorf("git add err: %w", err)
+	}
+	status, err := s.gitManager.Status(w)
+	if err != nil {
+		return nil, false, fmt.Errorf("git status err: %w", err)
 	}
-	if _, err := s.gitManager.Commit(w, "the generated stub pb is pushed to git repository",
-		&git.CommitOptions{All: true}); err != nil {
+	return w, !status.IsClean(), nil
+}
+
+// commitAndPushGitDir commits the stubs with the message, tags the commit if tag is not empty,
+// and pushes them to the branch, or the default branch if it is empty.
+func (s *Git) commitAndPushGitDir(r *git.Repository, w *git.Worktree, opt *params.Option,
+	msg, branch, tag string) error {
+	hash, err := s.gitManager.Commit(w, msg, &git.CommitOptions{All: true})
+	if err != nil {
 		return fmt.Errorf("git commit err: %w", err)
 	}
+	if opt.Patch != "" {
+		return s.writePatch(r, hash, opt.Patch)
+	}
 	// Tag the target repo.
-	if opt.NewTag {
-		if err = s.setTag(r, opt); err != nil {
+	if tag != "" {
+		if err = s.setTag(r, tag); err != nil {
 			return err
 		}
 	}
+	refSpecs := []config.RefSpec{"refs/heads/*:refs/heads/*", "refs/tags/*:refs/tags/*"}
+	if branch != "" {
+		head, err := s.gitManager.Head(r)
+		if err != nil {
+			return fmt.Errorf("git head err: %w", err)
+		}
+		refSpecs[0] = config.RefSpec(head.Name().String() + ":" + plumbing.NewBranchReferenceName(branch).String())
+	}
 	if err := s.gitManager.Push(r, &git.PushOptions{
-		RefSpecs: []config.RefSpec{"refs/heads/*:refs/heads/*", "refs/tags/*:refs/tags/*"},
+		RefSpecs: refSpecs,
 		Auth:     s.auth,
 	}); err != nil {
 		return fmt.Errorf("git push err: %w", err)
@@ -213,26 +391,48 @@ func (s *Git) commitAndPushGitDir(r *git.Repository, opt *params.Option) error {
 	return nil
 }
 
-func (s *Git) copyStubToGitDir(localStubDir, localGitDir, gitURLBase string) error {
-	pbFiles, err := s.collectPBFileFullPaths(localStubDir)
+// writePatch writes the patch of the commit into the file, or the standard output if it is -, instead of pushing.
+func (s *Git) writePatch(r *git.Repository, hash plumbing.Hash, file string) error {
+	c, err := s.gitManager.CommitObject(r, hash)
+	if err != nil {
+		return fmt.Errorf("git commit object of %s err: %w", hash, err)
+	}
+	patch, err := formatPatch(c)
 	if err != nil {
 		return err
 	}
+	if file == patchStdout {
+		fmt.Print(patch)
+		return nil
+	}
+	if err := os.WriteFile(file, []byte(patch), 0644); err != nil {
+		return fmt.Errorf("write patch %s err: %w", file, err)
+	}
+	log.Info("the patch of the stubs is written to %s, which is not pushed", file)
+	return nil
+}
+
+// copyStubToGitDir copies the stubs into the git directory, and returns the destination directory.
+func (s *Git) copyStubToGitDir(localStubDir, localGitDir, gitURLBase string) (string, error) {
+	pbFiles, err := s.collectPBFileFullPaths(localStubDir)
+	if err != nil {
+		return "", err
+	}
 	pbDir := filepath.Dir(pbFiles[0])
 	pbDirBase := filepath.Base(pbDir) // Get the last segment of the directory.
 	defaultSuffix := parseDefaultPathSuffix(pbDir, gitURLBase)
 	gitDstPath, err := s.extractGitDstDirFullPath(pbDirBase, localGitDir, defaultSuffix)
 	if err != nil {
-		return err
+		return "", err
 	}
 	for _, f := range pbFiles {
 		if err := s.copyPBFileToGitDir(f, gitDstPath); err != nil {
-			return fmt.Errorf(
+			return "", fmt.Errorf(
 				"copy stub to git local dir error: %w, localStubDir: %s, localGitDir: %s",
 				err, localStubDir, localGitDir)
 		}
 	}
-	return nil
+	return gitDstPath, nil
 }
 
 func parseDefaultPathSuffix(pbDir, gitURLBase string) string {
@@ -321,19 +521,8 @@ func isSameDir(files []string) bool {
 	return true
 }
 
-func (s *Git) setTag(r *git.Repository, opt *params.Option) error {
-	tag := opt.Tag
-	if tag == "" {
-		var err error
-		tag, err = s.evalTagName(r)
-		if err != nil {
-			return err
-		}
-	} else {
-		if err := s.tagExists(r, tag); err != nil {
-			return err
-		}
-	}
+// setTag tags HEAD.
+func (s *Git) setTag(r *git.Repository, tag string) error {
 	h, err := s.gitManager.Head(r)
 	if err != nil {
 		return err
@@ -402,12 +591,17 @@ func genNewTagName(lastTagName string) string {
 	return strings.Join(tags, gitTagNameSep)
 }
 
-func (s *Git) getRemoteGitURLBase(repo *git.Repository) string {
+// getRemoteGitURL returns the URL of the origin remote.
+func (s *Git) getRemoteGitURL(repo *git.Repository) string {
 	r, err := s.gitManager.Remote(repo, "origin")
-	if err != nil {
+	if err != nil || len(r.Config().URLs) == 0 {
 		return ""
 	}
-	url := r.Config().URLs[0]
+	return r.Config().URLs[0]
+}
+
+func (s *Git) getRemoteGitURLBase(repo *git.Repository) string {
+	url := s.getRemoteGitURL(repo)
 	index := strings.LastIndex(url, gitURLPathSep)
 	if index == -1 {
 		return ""
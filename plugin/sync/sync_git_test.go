@@ -10,7 +10,7 @@
 package sync
 
 import (
-	"fmt"
+	"errors"
 	"io/fs"
 	"os"
 	"strings"
@@ -140,8 +140,16 @@ func executeGoMock(t *testing.T) (*gomock.Controller, FileManager, GitManager) {
 	gm.EXPECT().CreateTag(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(ref, nil).AnyTimes()
 	gm.EXPECT().AddWithOptions(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	gm.EXPECT().Commit(gomock.Any(), gomock.Any(), gomock.Any()).Return(plumbing.NewHash("12345678"), nil).AnyTimes()
+	gm.EXPECT().Status(gomock.Any()).Return(git.Status{"hello.pb.go": &git.FileStatus{Staging: git.Added}}, nil).
+		AnyTimes()
+	gm.EXPECT().Reference(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, plumbing.ErrReferenceNotFound).
+		AnyTimes()
+	gm.EXPECT().CommitObject(gomock.Any(), gomock.Any()).Return(nil, plumbing.ErrObjectNotFound).AnyTimes()
+	gm.EXPECT().Checkout(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	publicKeys, _ := ssh.NewPublicKeys("git", []byte("1234"), "12345")
 	gm.EXPECT().NewPublicKeysFromFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(publicKeys, nil).AnyTimes()
+	gm.EXPECT().NewSSHAgentAuth(gomock.Any()).Return(&ssh.PublicKeysCallback{User: "git"}, nil).AnyTimes()
+	gm.EXPECT().CredentialFill(gomock.Any()).Return("", "", errors.New("no credential helper")).AnyTimes()
 	return ctrl, fm, gm
 }
 
@@ -174,25 +182,43 @@ func TestSyncGit_Run(t *testing.T) {
 func TestSyncGit_cloneGitDir(t *testing.T) {
 	sGit, opts, _, ctrl := buildGitAndOptions(t)
 	defer ctrl.Finish()
-	dir, r, err := sGit.cloneOrInitGitDir("trpc.group/veteranchen/test/helloworld", opts)
+	u, err := parseGitURLComponent("trpc.group/veteranchen/test/helloworld", "", schemeSSH)
+	require.Nil(t, err)
+	dir, r, err := sGit.cloneOrInitGitDir(u, opts)
 	require.Nil(t, err)
 	require.NotNil(t, r)
 	require.NotEmpty(t, dir)
+}
 
-	opts.Remote = "https://trpc.group/veteranchen/test.git"
-	dir, r, err = sGit.cloneOrInitGitDir("trpc.group/veteranchen/test/helloworld", opts)
-	fmt.Println(err)
-	require.NotNil(t, err)
-
-	opts.Remote = "git@trpc.group/veteranchen/test.git"
-	dir, r, err = sGit.cloneOrInitGitDir("trpc.group/veteranchen/test/helloworld", opts)
-	fmt.Println(err)
-	require.NotNil(t, err)
-
-	opts.Remote = "git@trpc.group:veteranchen/test"
-	dir, r, err = sGit.cloneOrInitGitDir("trpc.group/veteranchen/test/helloworld", opts)
-	fmt.Println(err)
-	require.NotNil(t, err)
+func TestParseGitURLComponent(t *testing.T) {
+	const goPackage = "trpc.group/veteranchen/test/helloworld"
+	for _, tt := range []struct {
+		remote, scheme string
+		url, parent    string // URLs with all the paths and the first path.
+		err            string
+	}{
+		{"", schemeSSH, "git@trpc.group:veteranchen/test/helloworld.git", "git@trpc.group:veteranchen.git", ""},
+		{"", schemeHTTPS, "https://trpc.group/veteranchen/test/helloworld.git", "https://trpc.group/veteranchen.git", ""},
+		{"git@trpc.group:veteranchen/test", schemeSSH, "git@trpc.group:veteranchen/test", "git@trpc.group:veteranchen", ""},
+		{"ssh://git@trpc.group:2222/veteranchen/test.git", schemeSSH,
+			"ssh://git@trpc.group:2222/veteranchen/test.git", "ssh://git@trpc.group:2222/veteranchen.git", ""},
+		{"https://trpc.group/veteranchen/test.git", schemeSSH,
+			"https://trpc.group/veteranchen/test.git", "https://trpc.group/veteranchen.git", ""},
+		{"file:///srv/git/test.git", schemeSSH, "file:///srv/git/test.git", "file:///srv.git", ""},
+		{"git@trpc.group/veteranchen/test.git", schemeSSH, "", "", "is invalid, supported"},
+		{"ftp://trpc.group/veteranchen/test.git", schemeSSH, "", "", "unsupported scheme ftp"},
+		{"https://trpc.group", schemeSSH, "", "", "no path"},
+		{"git@trpc.group:.git", schemeSSH, "", "", "no path"},
+	} {
+		u, err := parseGitURLComponent(goPackage, tt.remote, tt.scheme)
+		if tt.err != "" {
+			require.ErrorContains(t, err, tt.err, tt.remote)
+			continue
+		}
+		require.Nil(t, err, tt.remote)
+		require.Equal(t, tt.url, u.String(len(u.paths)))
+		require.Equal(t, tt.parent, u.String(1))
+	}
 }
 
 func TestSyncGit_Run_initGitRepo(t *testing.T) {
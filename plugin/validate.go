@@ -70,6 +70,7 @@ func (p *Validate) Run(fd *descriptor.FileDescriptor, opt *params.Option) error
 		pb.WithPb2ImportPath(fd.Pb2ImportPath),
 		pb.WithPkg2ImportPath(fd.Pkg2ImportPath),
 		pb.WithSecvEnabled(true),
+		pb.WithCompiler(opt.PBCompiler),
 	}
 	// Generate ${protofile}.pb.validate.go
 	if !opt.RPCOnly {
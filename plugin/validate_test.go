@@ -92,6 +92,40 @@ func TestPlugin_Validate(t *testing.T) {
 		require.True(t, u.Check(fd, opt))
 		require.Nil(t, u.Run(fd, opt))
 	})
+
+	// The builtin compiler invokes protoc-gen-secv directly without protoc.
+	t.Run("validate && builtin", func(t *testing.T) {
+		pbf, fd, err := parseValidateSampleProtofile()
+		require.Nil(t, err)
+		bin := t.TempDir()
+		for name, script := range map[string]string{
+			"protoc":             "#!/bin/sh\nexit 1\n",
+			"protoc-gen-secv":    "#!/bin/sh\ncat >/dev/null\n",
+			"protoc-gen-secv-v2": "#!/bin/sh\ncat >/dev/null\n",
+		} {
+			require.Nil(t, os.WriteFile(filepath.Join(bin, name), []byte(script), 0755))
+		}
+		t.Setenv("PATH", bin)
+
+		wd, _ := os.Getwd()
+		defer os.Chdir(wd)
+		opt := &params.Option{
+			Protofile:   "helloworld.proto",
+			Protodirs:   []string{filepath.Clean(filepath.Join(wd, "../install")), filepath.Dir(pbf)},
+			Language:    "go",
+			RPCOnly:     true,
+			OutputDir:   t.TempDir(),
+			SecvEnabled: true,
+			PBCompiler:  pb.CompilerBuiltin,
+		}
+		require.True(t, u.Check(fd, opt))
+		dir, err := paths.Locate(pb.ProtoTRPC)
+		require.Nil(t, err)
+		opt.Protodirs = append(append(opt.Protodirs, dir), paths.ExpandSearch(dir)...)
+
+		os.Chdir(filepath.Dir(pbf))
+		require.Nil(t, u.Run(fd, opt))
+	})
 }
 
 func parseValidateSampleProtofile() (string, *descriptor.FileDescriptor, error) {
@@ -0,0 +1,258 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/imports"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"trpc.group/trpc-go/trpc-cmdline/config"
+	"trpc.group/trpc-go/trpc-cmdline/params"
+	"trpc.group/trpc-go/trpc-cmdline/parser"
+	"trpc.group/trpc-go/trpc-cmdline/tpl"
+	"trpc.group/trpc-go/trpc-cmdline/tpl/pack"
+	"trpc.group/trpc-go/trpc-cmdline/util/fs"
+	"trpc.group/trpc-go/trpc-cmdline/util/lang"
+)
+
+// Values of the paths option, which decides where the output files are placed, the same as protoc-gen-go.
+const (
+	pathsImport         = "import"          // Placed in the directory named after the go_package import path.
+	pathsSourceRelative = "source_relative" // Placed in the same relative directory as the input file.
+)
+
+// generator generates the stub code of the files inside a CodeGeneratorRequest.
+type generator struct {
+	option *params.Option
+	paths  string
+}
+
+// generate generates the stub code for the request, errors are reported by the Error field of the response.
+func generate(req *pluginpb.CodeGeneratorRequest) *pluginpb.CodeGeneratorResponse {
+	resp := &pluginpb.CodeGeneratorResponse{
+		SupportedFeatures: proto.Uint64(uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)),
+	}
+	g, err := newGenerator(req.GetParameter())
+	if err != nil {
+		resp.Error = proto.String(err.Error())
+		return resp
+	}
+	for _, name := range req.GetFileToGenerate() {
+		files, err := g.generateFile(req.GetProtoFile(), name)
+		if err != nil {
+			resp.Error = proto.String(fmt.Sprintf("%s: %v", name, err))
+			return resp
+		}
+		resp.File = append(resp.File, files...)
+	}
+	return resp
+}
+
+// newGenerator creates a generator by the parameter passed by protoc.
+func newGenerator(parameter string) (*generator, error) {
+	g := &generator{
+		option: &params.Option{
+			IDLType:              config.IDLTypeProtobuf,
+			Language:             "go",
+			Protocol:             "trpc",
+			RPCOnly:              true,
+			AliasAsClientRPCName: true,
+			KeepOrigRPCName:      true,
+			GroupName:            "trpc-go",
+		},
+		paths: pathsImport,
+	}
+	if err := g.parseParameter(parameter); err != nil {
+		return nil, err
+	}
+	if _, err := config.Init(); err != nil {
+		return nil, fmt.Errorf("init config err: %w", err)
+	}
+	if g.option.Assetdir == "" {
+		cfg, err := config.GetTemplate(g.option.IDLType, g.option.Language)
+		if err != nil {
+			return nil, fmt.Errorf("config get template err: %w", err)
+		}
+		g.option.Assetdir = cfg.AssetDir
+		// The same as `trpc create`, the default template pack is layered on top of the built-in templates.
+		store, err := pack.OpenDefault()
+		if err != nil {
+			return nil, fmt.Errorf("open template store err: %w", err)
+		}
+		p, err := store.Default(g.option.IDLType.String(), g.option.Language)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			g.option.Overlays = append(g.option.Overlays, p.AssetDir())
+		}
+	}
+	if g.option.Domain == "" {
+		g.option.Domain = config.GlobalConfig().Domain
+	}
+	if g.option.VersionSuffix != "" {
+		g.option.VersionSuffix = "/" + g.option.VersionSuffix
+	}
+	return g, nil
+}
+
+// parseParameter parses the comma separated key=value pairs into the options,
+// the keys are the same as the flags of `trpc create`:
+//
+//	alias                    use rpcname aliases
+//	alias-as-client-rpcname  use alias name as client rpcname, default true
+//	noservicesuffix          do not add the "Service" suffix to the service descriptor
+//	multi-version            support importing multiple versions of protocols
+//	app, server              the app and server name used in the stub code
+//	domain, groupname        the domain and group name of the generated code address
+//	versionsuffix            the version suffix of the generated code address
+//	protocol                 the protocol, default trpc
+//	assetdir                 the template directory, default the protobuf/asset_go inside the installed assets
+//	overlay                  a template directory layered on top of assetdir, repeatable, the former ones take precedence
+//	paths                    where to place the output files, import (default) or source_relative
+func (g *generator) parseParameter(parameter string) error {
+	for _, kv := range strings.Split(parameter, ",") {
+		if kv = strings.TrimSpace(kv); kv == "" {
+			continue
+		}
+		k, v, hasValue := strings.Cut(kv, "=")
+		var err error
+		switch o := g.option; k {
+		case "alias":
+			o.AliasOn, err = parseBool(v, hasValue)
+		case "alias-as-client-rpcname":
+			o.AliasAsClientRPCName, err = parseBool(v, hasValue)
+		case "noservicesuffix":
+			o.NoServiceSuffix, err = parseBool(v, hasValue)
+		case "multi-version":
+			o.MultiVersion, err = parseBool(v, hasValue)
+		case "app":
+			o.CustomAPPName = v
+		case "server":
+			o.CustomServerName = v
+		case "domain":
+			o.Domain = v
+		case "groupname":
+			o.GroupName = v
+		case "versionsuffix":
+			o.VersionSuffix = v
+		case "protocol":
+			o.Protocol = v
+		case "assetdir":
+			if o.Assetdir, err = filepath.Abs(v); err != nil {
+				return fmt.Errorf("get absolute path of assetdir %s err: %w", v, err)
+			}
+		case "overlay":
+			dir, err := filepath.Abs(v)
+			if err != nil {
+				return fmt.Errorf("get absolute path of overlay %s err: %w", v, err)
+			}
+			o.Overlays = append(o.Overlays, dir)
+		case "paths":
+			if v != pathsImport && v != pathsSourceRelative {
+				return fmt.Errorf("invalid value of paths %q, expect %s or %s", v, pathsImport, pathsSourceRelative)
+			}
+			g.paths = v
+		default:
+			return fmt.Errorf("unknown parameter %q", k)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid value of %s: %w", k, err)
+		}
+	}
+	return nil
+}
+
+func parseBool(v string, hasValue bool) (bool, error) {
+	if !hasValue {
+		return true, nil
+	}
+	return strconv.ParseBool(v)
+}
+
+// generateFile renders the client stub templates for the proto file name, files without services are skipped.
+func (g *generator) generateFile(
+	protos []*descriptorpb.FileDescriptorProto,
+	name string,
+) ([]*pluginpb.CodeGeneratorResponse_File, error) {
+	o := g.option
+	fd, err := parser.LoadFileDescriptorProtos(protos, name,
+		parser.WithAliasOn(o.AliasOn),
+		parser.WithAPPName(o.CustomAPPName),
+		parser.WithServerName(o.CustomServerName),
+		parser.WithAliasAsClientRPCName(o.AliasAsClientRPCName),
+		parser.WithLanguage(o.Language),
+		parser.WithRPCOnly(o.RPCOnly),
+		parser.WithMultiVersion(o.MultiVersion),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("parse file descriptor err: %w", err)
+	}
+	if len(fd.Services) == 0 {
+		return nil, nil
+	}
+	cfg, err := config.GetTemplate(o.IDLType, o.Language)
+	if err != nil {
+		return nil, fmt.Errorf("config get template err: %w", err)
+	}
+
+	opt := *o
+	opt.Protofile = name
+	dir := g.outputDir(name, fd.GoPackage)
+	var files []*pluginpb.CodeGeneratorResponse_File
+	for _, stub := range cfg.RPCClientStub {
+		// The same naming as `trpc create`, e.g. helloworld.proto -> helloworld.trpc.go.
+		outfile := strings.TrimSuffix(path.Base(stub), ".tpl")
+		if outfile == "trpc.go" {
+			outfile = fs.BaseNameWithoutExt(name) + ".trpc.go"
+		}
+		infile, err := tpl.Lookup(o, stub)
+		if errors.Is(err, os.ErrNotExist) {
+			continue // Deleted by an overlay.
+		}
+		if err != nil {
+			return nil, err
+		}
+		buf := &bytes.Buffer{}
+		if err := tpl.Render(buf, fd, infile, &opt); err != nil {
+			return nil, fmt.Errorf("render template %s err: %w", stub, err)
+		}
+		// Same as the goimports plugin of `trpc create`, which fixes the imports and formats the code.
+		content, err := imports.Process(outfile, buf.Bytes(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("goimports the code rendered by %s err: %w", stub, err)
+		}
+		files = append(files, &pluginpb.CodeGeneratorResponse_File{
+			Name:    proto.String(path.Join(dir, outfile)),
+			Content: proto.String(string(content)),
+		})
+	}
+	return files, nil
+}
+
+// outputDir returns the slash separated directory of the output files, which is relative to the --trpc_out directory.
+func (g *generator) outputDir(name, goPackage string) string {
+	if g.paths == pathsSourceRelative || goPackage == "" {
+		return path.Dir(name)
+	}
+	_, importPath := lang.ExplodeImport(goPackage)
+	return importPath
+}
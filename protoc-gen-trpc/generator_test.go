@@ -0,0 +1,101 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"trpc.group/trpc-go/trpc-cmdline/params"
+)
+
+func helloworldRequest(t *testing.T, parameter string) *pluginpb.CodeGeneratorRequest {
+	p := protoparse.Parser{ImportPaths: []string{"../docs/helloworld"}}
+	fds, err := p.ParseFiles("helloworld.proto")
+	require.Nil(t, err)
+	var protos []*descriptorpb.FileDescriptorProto
+	seen := make(map[string]bool)
+	var add func(fd *desc.FileDescriptor)
+	add = func(fd *desc.FileDescriptor) {
+		if seen[fd.GetName()] {
+			return
+		}
+		seen[fd.GetName()] = true
+		for _, dep := range fd.GetDependencies() {
+			add(dep)
+		}
+		protos = append(protos, fd.AsFileDescriptorProto())
+	}
+	add(fds[0])
+	return &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{"helloworld.proto"},
+		Parameter:      proto.String(parameter),
+		ProtoFile:      protos,
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	t.Run("import paths", func(t *testing.T) {
+		resp := generate(helloworldRequest(t, ""))
+		require.Empty(t, resp.GetError())
+		require.Len(t, resp.GetFile(), 1)
+		f := resp.GetFile()[0]
+		require.Equal(t, "github.com/some-repo/examples/helloworld/helloworld.trpc.go", f.GetName())
+		require.Contains(t, f.GetContent(), "package helloworld")
+		require.Contains(t, f.GetContent(), "HelloWorldServiceService")
+	})
+	t.Run("source relative and no service suffix", func(t *testing.T) {
+		resp := generate(helloworldRequest(t, "paths=source_relative,noservicesuffix"))
+		require.Empty(t, resp.GetError())
+		require.Len(t, resp.GetFile(), 1)
+		f := resp.GetFile()[0]
+		require.Equal(t, "helloworld.trpc.go", f.GetName())
+		require.NotContains(t, f.GetContent(), "HelloWorldServiceService")
+	})
+	t.Run("unknown parameter", func(t *testing.T) {
+		resp := generate(helloworldRequest(t, "nosuch=1"))
+		require.Contains(t, resp.GetError(), "unknown parameter")
+		require.Empty(t, resp.GetFile())
+	})
+}
+
+func TestRun(t *testing.T) {
+	in, err := proto.Marshal(helloworldRequest(t, "alias=false"))
+	require.Nil(t, err)
+	out := &bytes.Buffer{}
+	require.Nil(t, run(bytes.NewReader(in), out))
+	resp := &pluginpb.CodeGeneratorResponse{}
+	require.Nil(t, proto.Unmarshal(out.Bytes(), resp))
+	require.Empty(t, resp.GetError())
+	require.Len(t, resp.GetFile(), 1)
+}
+
+func TestParseParameter(t *testing.T) {
+	g := &generator{option: &params.Option{AliasAsClientRPCName: true}, paths: pathsImport}
+	require.Nil(t, g.parseParameter(
+		"alias, alias-as-client-rpcname=false,multi-version=true,app=a,server=s,domain=d,paths=source_relative"))
+	require.True(t, g.option.AliasOn)
+	require.False(t, g.option.AliasAsClientRPCName)
+	require.True(t, g.option.MultiVersion)
+	require.Equal(t, "a", g.option.CustomAPPName)
+	require.Equal(t, "s", g.option.CustomServerName)
+	require.Equal(t, "d", g.option.Domain)
+	require.Equal(t, pathsSourceRelative, g.paths)
+
+	require.NotNil(t, g.parseParameter("alias=maybe"))
+	require.NotNil(t, g.parseParameter("paths=other"))
+}
@@ -0,0 +1,59 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+// Package main provides protoc-gen-trpc, a protoc plugin which generates the tRPC-Go stub code,
+// i.e. the *.trpc.go files that `trpc create --rpconly` writes beside the *.pb.go files.
+//
+// Usage:
+//
+//	protoc --go_out=. --trpc_out=. --trpc_opt=alias,noservicesuffix helloworld.proto
+//
+// The options passed by --trpc_opt are comma separated key=value pairs, the value of bool options can be omitted.
+// See parseParameter for the supported options.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func main() {
+	// protoc reads the response from stdout, keep the logs printed by the shared packages away from it.
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	if err := run(os.Stdin, stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "protoc-gen-trpc: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run reads the CodeGeneratorRequest from r, and writes the CodeGeneratorResponse into w.
+func run(r io.Reader, w io.Writer) error {
+	in, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("read request err: %w", err)
+	}
+	req := &pluginpb.CodeGeneratorRequest{}
+	if err := proto.Unmarshal(in, req); err != nil {
+		return fmt.Errorf("proto unmarshal request err: %w", err)
+	}
+	resp := generate(req)
+	out, err := proto.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("proto marshal response err: %w", err)
+	}
+	if _, err := w.Write(out); err != nil {
+		return fmt.Errorf("write response err: %w", err)
+	}
+	return nil
+}
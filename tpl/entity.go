@@ -0,0 +1,124 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package tpl
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+
+	"github.com/iancoleman/strcase"
+
+	"trpc.group/trpc-go/trpc-cmdline/config"
+	"trpc.group/trpc-go/trpc-cmdline/params"
+	"trpc.group/trpc-go/trpc-cmdline/util/log"
+)
+
+// entityKind is the kind of the definitions which a template is rendered once per.
+type entityKind int
+
+const (
+	entityNone entityKind = iota
+	entityMessage
+	entityEnum
+)
+
+var (
+	// messagePlaceholder in the file name of a template declares that it is rendered per message,
+	// such as model_{{.Message}}.go.tpl, which is replaced by the message name.
+	messagePlaceholder = regexp.MustCompile(`\{\{\s*\.Message\s*\}\}`)
+	// enumPlaceholder in the file name of a template declares that it is rendered per enum.
+	enumPlaceholder = regexp.MustCompile(`\{\{\s*\.Enum\s*\}\}`)
+)
+
+// entityKindOf returns the kind of the definitions which the template is rendered once per,
+// declared by the placeholder in its file name, or the message_stub and enum_stub patterns of cfg.
+func entityKindOf(relPath string, cfg *config.Template) entityKind {
+	base := filepath.Base(relPath)
+	switch {
+	case messagePlaceholder.MatchString(base):
+		return entityMessage
+	case enumPlaceholder.MatchString(base):
+		return entityEnum
+	case cfg == nil:
+		return entityNone
+	case matchStubPatterns(relPath, cfg.MessageStub):
+		return entityMessage
+	case matchStubPatterns(relPath, cfg.EnumStub):
+		return entityEnum
+	default:
+		return entityNone
+	}
+}
+
+// matchStubPatterns reports whether the relative path of the template matches any of the glob patterns,
+// which are separated by slashes.
+func matchStubPatterns(relPath string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, err := path.Match(p, filepath.ToSlash(relPath)); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// generatePerEntity generates a file for each of the top level messages or enums defined in the IDL,
+// outPath is the output path of the template, whose placeholder is replaced by the name of the definition.
+// Without the placeholder, the file is named in the same way as the client stub per service,
+// e.g. user.model.go for model.go.tpl if keep_orig_name is enabled.
+func generatePerEntity(fd *FD, infile, outPath string, kind entityKind, cfg *config.Template, opt *params.Option) error {
+	var (
+		names   []string
+		extOpts []*GenerateOptions
+	)
+	switch kind {
+	case entityMessage:
+		for _, md := range fd.Messages {
+			names = append(names, md.Name)
+			extOpts = append(extOpts, &GenerateOptions{methodIndex: -1, message: md})
+		}
+	case entityEnum:
+		for _, ed := range fd.Enums {
+			names = append(names, ed.Name)
+			extOpts = append(extOpts, &GenerateOptions{methodIndex: -1, enum: ed})
+		}
+	}
+	for i, name := range names {
+		outfile := entityOutfile(outPath, name, kind, cfg)
+		if opt.Update && fileExists(outfile) {
+			log.Debug("update mode, keep existing file %s", outfile)
+			continue
+		}
+		if err := GenerateFile(fd, infile, outfile, opt, extOpts[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// entityOutfile returns the output file of the message or enum named name.
+func entityOutfile(outPath, name string, kind entityKind, cfg *config.Template) string {
+	name = strcase.ToSnake(name)
+	if cfg != nil && cfg.CamelCaseName {
+		name = strcase.ToCamel(name)
+	}
+	dir, base := filepath.Split(outPath)
+	placeholder := messagePlaceholder
+	if kind == entityEnum {
+		placeholder = enumPlaceholder
+	}
+	if placeholder.MatchString(base) {
+		return filepath.Join(dir, placeholder.ReplaceAllLiteralString(base, name))
+	}
+	if cfg.KeepOrigName {
+		return filepath.Join(dir, name+cfg.Separator+base)
+	}
+	return filepath.Join(dir, name+"."+cfg.LangFileExt)
+}
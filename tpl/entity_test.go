@@ -0,0 +1,77 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package tpl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-cmdline/config"
+	"trpc.group/trpc-go/trpc-cmdline/params"
+	"trpc.group/trpc-go/trpc-cmdline/parser"
+)
+
+func TestEntityKindOf(t *testing.T) {
+	cfg := &config.Template{MessageStub: []string{"dao/*.go.tpl"}, EnumStub: []string{"enum.sql.tpl"}}
+	for relPath, want := range map[string]entityKind{
+		"model_{{.Message}}.go.tpl":                    entityMessage,
+		filepath.Join("ddl", "{{ .Message }}.sql.tpl"): entityMessage,
+		"{{.Enum}}.go.tpl":                             entityEnum,
+		filepath.Join("dao", "dao.go.tpl"):             entityMessage,
+		"enum.sql.tpl":                                 entityEnum,
+		filepath.Join("{{.Message}}", "main.go.tpl"):   entityNone,
+		"main.go.tpl":                                  entityNone,
+	} {
+		require.Equal(t, want, entityKindOf(relPath, cfg), relPath)
+	}
+	require.Equal(t, entityNone, entityKindOf(filepath.Join("dao", "dao.go.tpl"), nil))
+}
+
+func TestGeneratePerEntity(t *testing.T) {
+	fd, err := parser.ParseProtoFile("message.proto",
+		[]string{"../parser/testcase/message", "../install/submodules/trpc-protocol"})
+	require.Nil(t, err)
+	assetdir, outdir := t.TempDir(), t.TempDir()
+	writeFiles(t, assetdir, map[string]string{
+		"model_{{.Message}}.go.tpl": `{{ .Message.Name }}:{{ range .Message.Fields }} {{ .Name }}{{ end }}`,
+		"{{.Enum}}.txt.tpl":         `{{ .Enum.Name }}:{{ range .Enum.Values }} {{ .Name }}={{ .Number }}{{ end }}`,
+		"dao.go.tpl":                `package {{ .BaseGoPackageName }} // {{ .Message.FullyQualifiedName }}`,
+	})
+	cfg := &config.Template{KeepOrigName: true, Separator: "."}
+	opt := &params.Option{Assetdir: assetdir}
+	gen := func(tpl string, kind entityKind) {
+		outPath := filepath.Join(outdir, strings.TrimSuffix(tpl, ".tpl"))
+		require.Nil(t, generatePerEntity(fd, filepath.Join(assetdir, tpl), outPath, kind, cfg, opt))
+	}
+	gen("model_{{.Message}}.go.tpl", entityMessage)
+	gen("{{.Enum}}.txt.tpl", entityEnum)
+	gen("dao.go.tpl", entityMessage)
+
+	read := func(name string) string {
+		b, err := os.ReadFile(filepath.Join(outdir, name))
+		require.Nil(t, err)
+		return string(b)
+	}
+	require.Equal(t, "User: id name status tags addresses phone email age home", read("model_user.go"))
+	require.Equal(t, "GetUserReq: id", read("model_get_user_req.go"))
+	require.Equal(t, "Status: STATUS_UNKNOWN=0 STATUS_OK=1", read("status.txt"))
+	require.Equal(t, "package message // trpc.test.message.User", read("user.dao.go"))
+	require.FileExists(t, filepath.Join(outdir, "get_user_req.dao.go"))
+
+	// Files of the existing definitions are kept in update mode.
+	require.Nil(t, os.WriteFile(filepath.Join(outdir, "status.txt"), []byte("edited"), 0644))
+	opt.Update = true
+	gen("{{.Enum}}.txt.tpl", entityEnum)
+	require.Equal(t, "edited", read("status.txt"))
+}
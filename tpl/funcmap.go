@@ -20,6 +20,7 @@ import (
 
 	"trpc.group/trpc-go/trpc-cmdline/util/fs"
 	"trpc.group/trpc-go/trpc-cmdline/util/lang"
+	"trpc.group/trpc-go/trpc-cmdline/util/sample"
 )
 
 // funcMap is a map of custom template functions used in Go templates.
@@ -80,4 +81,6 @@ var funcMap = template.FuncMap{
 	"basenamewithoutext": fs.BaseNameWithoutExt,
 	"dir":                filepath.Dir,
 	"join":               strings.Join,
+	// sample generates a sample of the message type in compact JSON, which is empty for flatbuffers.
+	"sample": sample.TemplateFunc,
 }
@@ -0,0 +1,287 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package lint
+
+import (
+	"trpc.group/trpc-go/trpc-cmdline/config"
+	"trpc.group/trpc-go/trpc-cmdline/params"
+)
+
+// Case is a synthetic IDL which the templates are rendered against.
+type Case struct {
+	Name  string
+	IDL   config.IDLType
+	Main  string            // The IDL file to generate from.
+	Files map[string]string // IDL files keyed by their paths relative to the import path.
+	// Fix adjusts the create options, such as enabling aliases or splitting files by method.
+	Fix func(*params.Option)
+}
+
+// Cases are the synthetic IDLs covering the features the templates deal with.
+var Cases = []*Case{
+	{
+		Name:  "unary",
+		IDL:   config.IDLTypeProtobuf,
+		Main:  "unary.proto",
+		Files: map[string]string{"unary.proto": unaryProto},
+	},
+	{
+		Name:  "streaming",
+		IDL:   config.IDLTypeProtobuf,
+		Main:  "streaming.proto",
+		Files: map[string]string{"streaming.proto": streamingProto},
+	},
+	{
+		Name:  "multi-service",
+		IDL:   config.IDLTypeProtobuf,
+		Main:  "multi.proto",
+		Files: map[string]string{"multi.proto": multiServiceProto},
+	},
+	{
+		Name:  "split-by-method",
+		IDL:   config.IDLTypeProtobuf,
+		Main:  "multi.proto",
+		Files: map[string]string{"multi.proto": multiServiceProto},
+		Fix:   func(o *params.Option) { o.PerMethod = true },
+	},
+	{
+		Name: "imports",
+		IDL:  config.IDLTypeProtobuf,
+		Main: "imports.proto",
+		Files: map[string]string{
+			"imports.proto":       importsProto,
+			"common/common.proto": commonProto,
+		},
+	},
+	{
+		Name:  "alias",
+		IDL:   config.IDLTypeProtobuf,
+		Main:  "alias.proto",
+		Files: map[string]string{"alias.proto": aliasProto},
+		Fix:   func(o *params.Option) { o.AliasOn = true },
+	},
+	{
+		Name:  "restful",
+		IDL:   config.IDLTypeProtobuf,
+		Main:  "restful.proto",
+		Files: map[string]string{"restful.proto": restfulProto},
+	},
+	{
+		Name: "flatbuffers",
+		IDL:  config.IDLTypeFlatBuffers,
+		Main: "greeter.fbs",
+		Files: map[string]string{
+			"greeter.fbs": greeterFbs,
+			"common.fbs":  commonFbs,
+		},
+	},
+}
+
+const unaryProto = `syntax = "proto3";
+package trpc.lint.unary;
+option go_package = "trpc.group/lint/unary";
+
+// Greeter says hello.
+service Greeter {
+  // SayHello says hello.
+  rpc SayHello(HelloReq) returns (HelloRsp);
+}
+
+// HelloReq is the request.
+message HelloReq {
+  string name = 1;
+  uint64 id = 2;
+  repeated string tags = 3;
+  map<string, int32> scores = 4;
+  Kind kind = 5;
+  Inner inner = 6;
+  oneof contact {
+    string phone = 7;
+    string email = 8;
+  }
+  message Inner {
+    bytes data = 1;
+  }
+}
+
+// HelloRsp is the response.
+message HelloRsp {
+  int32 code = 1;
+  string msg = 2;
+}
+
+// Kind is the kind of the greeting.
+enum Kind {
+  KIND_UNKNOWN = 0;
+  KIND_FORMAL = 1;
+}
+`
+
+const streamingProto = `syntax = "proto3";
+package trpc.lint.streaming;
+option go_package = "trpc.group/lint/streaming";
+
+service Streamer {
+  rpc Unary(Req) returns (Rsp);
+  rpc ClientStream(stream Req) returns (Rsp);
+  rpc ServerStream(Req) returns (stream Rsp);
+  rpc BidiStream(stream Req) returns (stream Rsp);
+}
+
+message Req {
+  string msg = 1;
+}
+
+message Rsp {
+  string msg = 1;
+}
+`
+
+const multiServiceProto = `syntax = "proto3";
+package trpc.lint.multi;
+option go_package = "trpc.group/lint/multi";
+
+service Greeter {
+  rpc SayHello(Req) returns (Rsp);
+  rpc SayHi(Req) returns (Rsp);
+}
+
+service Counter {
+  rpc Count(Req) returns (Rsp);
+  rpc Watch(Req) returns (stream Rsp);
+}
+
+message Req {
+  string msg = 1;
+}
+
+message Rsp {
+  string msg = 1;
+}
+`
+
+const importsProto = `syntax = "proto3";
+package trpc.lint.imports;
+option go_package = "trpc.group/lint/imports";
+
+import "common/common.proto";
+import "google/protobuf/empty.proto";
+
+service Greeter {
+  rpc SayHello(trpc.lint.common.Req) returns (trpc.lint.common.Rsp);
+  rpc Ping(google.protobuf.Empty) returns (Pong);
+}
+
+message Pong {
+  trpc.lint.common.Rsp rsp = 1;
+}
+`
+
+const commonProto = `syntax = "proto3";
+package trpc.lint.common;
+option go_package = "trpc.group/lint/common";
+
+message Req {
+  string msg = 1;
+}
+
+message Rsp {
+  string msg = 1;
+}
+`
+
+const aliasProto = `syntax = "proto3";
+package trpc.lint.alias;
+option go_package = "trpc.group/lint/alias";
+
+import "trpc/proto/trpc_options.proto";
+
+service Greeter {
+  rpc SayHello(Req) returns (Rsp) {
+    option (trpc.alias) = "/v1/hello";
+  };
+  // @alias=/v1/hi
+  rpc SayHi(Req) returns (Rsp);
+}
+
+message Req {
+  string msg = 1;
+}
+
+message Rsp {
+  string msg = 1;
+}
+`
+
+const restfulProto = `syntax = "proto3";
+package trpc.lint.restful;
+option go_package = "trpc.group/lint/restful";
+
+import "trpc/api/annotations.proto";
+
+service Greeter {
+  rpc SayHello(Req) returns (Rsp) {
+    option (trpc.api.http) = {
+      get: "/v1/hello/{name}"
+      additional_bindings: {
+        post: "/v1/hello"
+        body: "*"
+      }
+    };
+  };
+}
+
+message Req {
+  string name = 1;
+}
+
+message Rsp {
+  string msg = 1;
+}
+`
+
+const greeterFbs = `include "common.fbs";
+
+namespace trpc.lint.fbs;
+
+attribute "go_package=trpc.group/lint/fbs";
+
+table HelloReq {
+  name:string;
+  kind:common.Kind;
+}
+
+table HelloRsp {
+  msg:string;
+}
+
+rpc_service Greeter {
+  SayHello(HelloReq):HelloRsp;
+  Check(common.Ping):common.Pong;
+  ClientStream(HelloReq):HelloRsp (streaming: "client");
+  ServerStream(HelloReq):HelloRsp (streaming: "server");
+  BidiStream(HelloReq):HelloRsp (streaming: "bidi");
+}
+`
+
+const commonFbs = `namespace common;
+
+attribute "go_package=trpc.group/lint/common";
+
+enum Kind : byte { Unknown = 0, Formal }
+
+table Ping {
+  msg:string;
+}
+
+table Pong {
+  msg:string;
+}
+`
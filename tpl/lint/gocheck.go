@@ -0,0 +1,157 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	goparser "go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"trpc.group/trpc-go/trpc-cmdline/descriptor"
+	"trpc.group/trpc-go/trpc-cmdline/util/lang"
+)
+
+// checkGo parses and type-checks the generated Go files, which map to their templates.
+// Only the standard library is imported, the other imports are left unresolved, and the uses of them are unchecked.
+// The problems fixed by goimports, which trpc create runs on the generated code, are not reported,
+// neither are the undefined names in stubs, which are declared by the code that protoc or flatc generates.
+func checkGo(outdir string, generated map[string]string, stubs map[string]bool) ([]*Issue, error) {
+	var (
+		issues []*Issue
+		fset   = token.NewFileSet()
+		// Files of the same directory and package name are type-checked together.
+		pkgs = make(map[string][]*ast.File)
+		// qualifiers are the positions of the identifiers which may be package names, such as fmt of fmt.Println.
+		qualifiers = make(map[token.Pos]bool)
+	)
+	issue := func(pos token.Position, msg string) *Issue {
+		rel, err := filepath.Rel(outdir, pos.Filename)
+		if err != nil {
+			rel = pos.Filename
+		}
+		return &Issue{
+			File:      generated[pos.Filename],
+			Generated: fmt.Sprintf("%s:%d:%d", filepath.ToSlash(rel), pos.Line, pos.Column),
+			Msg:       msg,
+		}
+	}
+	for _, p := range sortedGoFiles(generated) {
+		f, err := goparser.ParseFile(fset, p, nil, goparser.ParseComments)
+		var list scanner.ErrorList
+		if errors.As(err, &list) {
+			// Only the first syntax error matters, the rest are usually caused by it.
+			issues = append(issues, issue(list[0].Pos, list[0].Msg))
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse %s err: %w", p, err)
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if id, ok := sel.X.(*ast.Ident); ok {
+					qualifiers[id.Pos()] = true
+				}
+			}
+			return true
+		})
+		key := filepath.Dir(p) + ":" + f.Name.Name
+		pkgs[key] = append(pkgs[key], f)
+	}
+
+	keys := make([]string, 0, len(pkgs))
+	for k := range pkgs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	conf := &types.Config{
+		Importer: stdImporter{importer.Default()},
+		Error: func(err error) {
+			var terr types.Error
+			if !errors.As(err, &terr) || ignored(terr, stubs, qualifiers) {
+				return
+			}
+			issues = append(issues, issue(terr.Fset.Position(terr.Pos), terr.Msg))
+		},
+	}
+	for _, k := range keys {
+		files := pkgs[k]
+		_, _ = conf.Check(files[0].Name.Name, fset, files, nil)
+	}
+	return issues, nil
+}
+
+// sortedGoFiles returns the generated Go files in order, so that the issues are reported in a stable order.
+func sortedGoFiles(generated map[string]string) []string {
+	var files []string
+	for p := range generated {
+		if strings.HasSuffix(p, ".go") {
+			files = append(files, p)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// ignored reports whether the type-checking error is caused by the code which is not generated by the templates,
+// or fixed by goimports, i.e. the imports which are missing or not used.
+func ignored(err types.Error, stubs map[string]bool, qualifiers map[token.Pos]bool) bool {
+	if strings.HasPrefix(err.Msg, "could not import") || strings.HasSuffix(err.Msg, "imported and not used") {
+		return true
+	}
+	if name := strings.TrimPrefix(err.Msg, "undefined: "); name != err.Msg {
+		return stubs[name] || qualifiers[err.Pos]
+	}
+	return false
+}
+
+// stdImporter imports the standard library only, which is the only dependency available to the generated code.
+type stdImporter struct {
+	imp types.Importer
+}
+
+// Import implements types.Importer.
+func (i stdImporter) Import(path string) (*types.Package, error) {
+	// The first element of the import paths of the standard library contains no dot.
+	if strings.Contains(strings.Split(path, "/")[0], ".") {
+		return nil, fmt.Errorf("%s is not checked", path)
+	}
+	return i.imp.Import(path)
+}
+
+// stubNames returns the Go names of the messages and enums defined in the IDL,
+// which are declared by the code that protoc or flatc generates.
+func stubNames(fd *descriptor.FileDescriptor) map[string]bool {
+	names := make(map[string]bool)
+	var addMessages func(prefix string, mds []*descriptor.MessageDescriptor)
+	addEnums := func(prefix string, eds []*descriptor.EnumDescriptor) {
+		for _, ed := range eds {
+			names[prefix+lang.Camelcase(ed.Name)] = true
+		}
+	}
+	addMessages = func(prefix string, mds []*descriptor.MessageDescriptor) {
+		for _, md := range mds {
+			name := prefix + lang.Camelcase(md.Name)
+			names[name] = true
+			addMessages(name+"_", md.Messages)
+			addEnums(name+"_", md.Enums)
+		}
+	}
+	addMessages("", fd.Messages)
+	addEnums("", fd.Enums)
+	return names
+}
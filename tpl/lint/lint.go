@@ -0,0 +1,234 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+// Package lint checks the templates by rendering them against synthetic IDLs,
+// and formatting and type-checking the generated Go code.
+package lint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"trpc.group/trpc-go/trpc-cmdline/config"
+	"trpc.group/trpc-go/trpc-cmdline/params"
+	"trpc.group/trpc-go/trpc-cmdline/parser"
+	"trpc.group/trpc-go/trpc-cmdline/tpl"
+)
+
+// Options are the options of Lint.
+type Options struct {
+	Assetdir string   // Template directory.
+	Overlays []string // Template directories layered on top of Assetdir, see tpl.Layers.
+	IDLType  config.IDLType
+	Language string
+	// Protodirs are the search paths of the IDL files imported by the cases, such as the trpc protos.
+	Protodirs []string
+	Cases     []*Case // Cases to render against, defaults to Cases.
+}
+
+// Issue is a problem found in a template.
+type Issue struct {
+	File string // Template file, slash separated and relative to the template directory.
+	Line int    // Line of the template, 0 if unknown.
+	// Generated is the position inside the generated file, such as rpc/greeter.trpc.go:12:3,
+	// if the problem is found in the generated code.
+	Generated string
+	Msg       string
+	Cases     []string // Names of the cases the problem is found with.
+}
+
+// String formats the issue as file:line: message [cases].
+func (i *Issue) String() string {
+	loc := i.File
+	if i.Line > 0 {
+		loc += ":" + strconv.Itoa(i.Line)
+	}
+	msg := i.Msg
+	if i.Generated != "" {
+		msg = i.Generated + ": " + msg
+	}
+	return fmt.Sprintf("%s: %s [%s]", loc, msg, strings.Join(i.Cases, ", "))
+}
+
+// Lint renders the templates against the cases of the IDL type, and reports the parse and execution errors.
+// The generated Go code is also formatted and type-checked.
+func Lint(opts *Options) ([]*Issue, error) {
+	cases := opts.Cases
+	if cases == nil {
+		cases = Cases
+	}
+	var (
+		issues []*Issue
+		seen   = make(map[string]*Issue)
+	)
+	for _, c := range cases {
+		if c.IDL != opts.IDLType {
+			continue
+		}
+		found, err := lintCase(c, opts)
+		if err != nil {
+			return nil, fmt.Errorf("lint case %s err: %w", c.Name, err)
+		}
+		// The same problem is usually found with many cases.
+		for _, i := range found {
+			key := fmt.Sprintf("%s:%d:%s:%s", i.File, i.Line, i.Generated, i.Msg)
+			if s, ok := seen[key]; ok {
+				s.Cases = append(s.Cases, c.Name)
+				continue
+			}
+			i.Cases = []string{c.Name}
+			seen[key] = i
+			issues = append(issues, i)
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		return issues[i].Line < issues[j].Line
+	})
+	return issues, nil
+}
+
+func lintCase(c *Case, opts *Options) ([]*Issue, error) {
+	tmp, err := os.MkdirTemp("", "trpc-lint-*")
+	if err != nil {
+		return nil, fmt.Errorf("create temporary directory err: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	idldir, outdir := filepath.Join(tmp, "idl"), filepath.Join(tmp, "out")
+	for name, content := range c.Files {
+		fp := filepath.Join(idldir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fp), os.ModePerm); err != nil {
+			return nil, fmt.Errorf("create directory of %s err: %w", name, err)
+		}
+		if err := os.WriteFile(fp, []byte(content), 0644); err != nil {
+			return nil, fmt.Errorf("write %s err: %w", name, err)
+		}
+	}
+
+	option, err := newOption(c, opts, idldir)
+	if err != nil {
+		return nil, err
+	}
+	fd, err := parser.Parse(c.Main, option.Protodirs, c.IDL,
+		parser.WithAliasOn(option.AliasOn),
+		parser.WithAliasAsClientRPCName(option.AliasAsClientRPCName),
+		parser.WithLanguage(option.Language),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s err: %w", c.Main, err)
+	}
+
+	var (
+		issues []*Issue
+		// generated maps the generated files to their templates.
+		generated = make(map[string]string)
+		failed    = make(map[string]bool)
+	)
+	err = tpl.ForEachTemplate(fd, outdir, option, func(rel string, err error) error {
+		if err != nil {
+			failed[rel] = true
+			issues = append(issues, renderIssue(rel, err))
+		}
+		return filepath.Walk(outdir, func(p string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			if _, ok := generated[p]; !ok {
+				generated[p] = rel
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if option.Language != "go" {
+		return issues, nil
+	}
+	// The code generated by the failed templates is incomplete, whose errors are not worth reporting.
+	for p, rel := range generated {
+		if failed[rel] {
+			delete(generated, p)
+		}
+	}
+	found, err := checkGo(outdir, generated, stubNames(fd))
+	if err != nil {
+		return nil, err
+	}
+	return append(issues, found...), nil
+}
+
+// newOption returns the options of trpc create for the case, which are the defaults of its flags.
+func newOption(c *Case, opts *Options, idldir string) (*params.Option, error) {
+	assetdir, err := filepath.Abs(opts.Assetdir)
+	if err != nil {
+		return nil, fmt.Errorf("get absolute path of %s err: %w", opts.Assetdir, err)
+	}
+	overlays := make([]string, len(opts.Overlays))
+	for i, dir := range opts.Overlays {
+		if overlays[i], err = filepath.Abs(dir); err != nil {
+			return nil, fmt.Errorf("get absolute path of %s err: %w", dir, err)
+		}
+	}
+	option := &params.Option{
+		Protodirs:            append([]string{idldir}, opts.Protodirs...),
+		Protofile:            c.Main,
+		ProtofileAbs:         filepath.Join(idldir, c.Main),
+		AliasAsClientRPCName: true,
+		Assetdir:             assetdir,
+		Overlays:             overlays,
+		Language:             opts.Language,
+		Protocol:             "trpc",
+		IDLType:              c.IDL,
+		Domain:               config.GlobalConfig().Domain,
+		GroupName:            "trpc-go",
+		GoVersion:            "1.18",
+		Mockgen:              true,
+		Gotag:                true,
+		KVs:                  make(map[string]interface{}),
+		Envs:                 make(map[string]string),
+	}
+	if c.Fix != nil {
+		c.Fix(option)
+	}
+	// The variables of the templates take their defaults.
+	m, err := tpl.LoadManifest(option)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.ResolveVariables(option, nil); err != nil {
+		return nil, err
+	}
+	return option, nil
+}
+
+var (
+	// templateErr matches the errors of text/template, which are like
+	// template: service_rpc.go.tpl:31:5: executing "service_rpc.go.tpl" at <.Foo>: can't evaluate field Foo.
+	templateErr = regexp.MustCompile(`template: [^:\s]+:(\d+)(?::\d+)?: (.*)`)
+	// dataType matches the type of the data passed to the templates, which is too long to read in the errors.
+	dataType = regexp.MustCompile(`type struct \{ \*descriptor\.FileDescriptor;.*\}`)
+)
+
+// renderIssue converts the error of rendering the template rel into an issue.
+func renderIssue(rel string, err error) *Issue {
+	m := templateErr.FindStringSubmatch(err.Error())
+	if m == nil {
+		return &Issue{File: rel, Msg: err.Error()}
+	}
+	line, _ := strconv.Atoi(m[1])
+	return &Issue{File: rel, Line: line, Msg: dataType.ReplaceAllLiteralString(m[2], "the template data")}
+}
@@ -0,0 +1,81 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-cmdline/config"
+)
+
+const trpcProtos = "../../install/submodules/trpc-protocol"
+
+func TestLintBuiltin(t *testing.T) {
+	for _, tt := range []struct {
+		idl      config.IDLType
+		assetdir string
+	}{
+		{config.IDLTypeProtobuf, "../../install/protobuf/asset_go"},
+		{config.IDLTypeFlatBuffers, "../../install/flatbuffers/asset_go"},
+	} {
+		t.Run(tt.idl.String(), func(t *testing.T) {
+			issues, err := Lint(&Options{
+				Assetdir:  tt.assetdir,
+				IDLType:   tt.idl,
+				Language:  "go",
+				Protodirs: []string{trpcProtos},
+			})
+			require.Nil(t, err)
+			require.Empty(t, issues)
+		})
+	}
+}
+
+func TestLintIssues(t *testing.T) {
+	overlay := t.TempDir()
+	for name, content := range map[string]string{
+		"main.go.tpl":            "package main\n\nfunc main() {\n\tvar n int = \"{{ .PackageName }}\"\n\t_ = n\n}\n",
+		"cmd/client/main.go.tpl": "package main\n\n{{ .Nope }}\n",
+		"funcs.go.tpl":           "package main\n\n{{ nope }}\n",
+		"syntax.go.tpl":          "package main\n\nfunc {\n",
+		"imports.go.tpl":         "package main\n\nimport \"os\"\n\nfunc exit() { fmt.Println(); strings.TrimSpace(\"\") }\n",
+	} {
+		fp := filepath.Join(overlay, filepath.FromSlash(name))
+		require.Nil(t, os.MkdirAll(filepath.Dir(fp), os.ModePerm))
+		require.Nil(t, os.WriteFile(fp, []byte(content), 0644))
+	}
+	issues, err := Lint(&Options{
+		Assetdir:  "../../install/protobuf/asset_go",
+		Overlays:  []string{overlay},
+		IDLType:   config.IDLTypeProtobuf,
+		Language:  "go",
+		Protodirs: []string{trpcProtos},
+		Cases:     Cases[:2],
+	})
+	require.Nil(t, err)
+	var got []string
+	for _, i := range issues {
+		got = append(got, i.String())
+	}
+	require.Equal(t, []string{
+		`cmd/client/main.go.tpl:3: executing "main.go.tpl" at <.Nope>: ` +
+			`can't evaluate field Nope in the template data [unary, streaming]`,
+		`funcs.go.tpl:3: function "nope" not defined [unary, streaming]`,
+		`main.go.tpl: main.go:4:14: cannot use "trpc.lint.unary" (untyped string constant) ` +
+			`as int value in variable declaration [unary]`,
+		`main.go.tpl: main.go:4:14: cannot use "trpc.lint.streaming" (untyped string constant) ` +
+			`as int value in variable declaration [streaming]`,
+		`syntax.go.tpl: syntax.go:3:6: expected 'IDENT', found '{' [unary, streaming]`,
+	}, got)
+}
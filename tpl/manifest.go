@@ -0,0 +1,340 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package tpl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"trpc.group/trpc-go/trpc-cmdline/params"
+	"trpc.group/trpc-go/trpc-cmdline/util/log"
+)
+
+// ManifestFile is the optional file inside a template layer declaring the conditions of the template files,
+// the variables of the templates and the hooks run in the output directory, e.g.
+//
+//	variables:
+//	  - name: db
+//	    description: Database of the DAO layer, mysql or none
+//	    default: none
+//	  - name: docker
+//	    description: Whether to generate the Dockerfile
+//	    default: false
+//	files:
+//	  - path: Dockerfile.tpl
+//	    when:
+//	      kvs:
+//	        docker: true
+//	  - path: dao
+//	    when:
+//	      kvs:
+//	        db: [mysql]
+//	  - path: stream_*.go.tpl
+//	    when:
+//	      streaming: true
+//	hooks:
+//	  post:
+//	    - go mod tidy
+//
+// The manifests of the layers are merged, the variables and files of a higher layer override the ones
+// with the same name or path beneath, and the hooks of all the layers run from the lowest layer.
+const ManifestFile = ".trpcmanifest.yaml"
+
+// Manifest is the content of ManifestFile.
+type Manifest struct {
+	Variables []*Variable `yaml:"variables,omitempty"`
+	Files     []*FileRule `yaml:"files,omitempty"`
+	Hooks     Hooks       `yaml:"hooks,omitempty"`
+}
+
+// Variable is a value used by the templates through .KVs, which is provided by --kvfile or --kvrawjson,
+// prompted for interactively, or defaults to Default.
+type Variable struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	// Default is the default value, whose type, string, bool or number, is also the type of the input.
+	Default interface{} `yaml:"default,omitempty"`
+}
+
+// FileRule renders the template files only when the condition holds.
+type FileRule struct {
+	// Path is a slash separated path or glob pattern relative to the layer root,
+	// the files inside the matched directories are also matched.
+	Path string    `yaml:"path"`
+	When Condition `yaml:"when"`
+}
+
+// Condition holds if all of its non-empty fields hold.
+type Condition struct {
+	Protocol stringList `yaml:"protocol,omitempty"` // Any of the protocols, such as trpc or http.
+	// Streaming is whether the IDL has streaming RPCs.
+	Streaming *bool `yaml:"streaming,omitempty"`
+	// RESTful is whether the IDL has RESTful rules, i.e. the trpc.api.http options.
+	RESTful *bool `yaml:"restful,omitempty"`
+	// KVs are the expected values of the KVs, a list means any of its values.
+	KVs map[string]interface{} `yaml:"kvs,omitempty"`
+}
+
+// Hooks are the shell commands run in the output directory.
+type Hooks struct {
+	Pre  []string `yaml:"pre,omitempty"`  // Run before the files are generated.
+	Post []string `yaml:"post,omitempty"` // Run after the files are generated and post-processed by the plugins.
+	// Layers are the template directories providing the hooks, which are filled by LoadManifest,
+	// so that the hooks of the template packs can be told from the ones of the built-in assets.
+	Layers []string `yaml:"-"`
+}
+
+// stringList is a list of strings which can be written as a single string in YAML.
+type stringList []string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (l *stringList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err == nil {
+		*l = stringList{s}
+		return nil
+	}
+	return unmarshal((*[]string)(l))
+}
+
+// LoadManifest loads and merges the manifests of the template layers, see ManifestFile.
+// An empty manifest is returned if no layer has one.
+func LoadManifest(option *params.Option) (*Manifest, error) {
+	merged := &Manifest{}
+	layers := Layers(option)
+	for i := len(layers) - 1; i >= 0; i-- {
+		m, err := loadManifest(layers[i])
+		if err != nil {
+			return nil, err
+		}
+		merged.merge(m)
+		if len(m.Hooks.Pre) != 0 || len(m.Hooks.Post) != 0 {
+			merged.Hooks.Layers = append(merged.Hooks.Layers, layers[i])
+		}
+	}
+	return merged, nil
+}
+
+func loadManifest(layer string) (*Manifest, error) {
+	fp := filepath.Join(layer, ManifestFile)
+	b, err := os.ReadFile(fp)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Manifest{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s err: %w", fp, err)
+	}
+	m := &Manifest{}
+	if err := yaml.UnmarshalStrict(b, m); err != nil {
+		return nil, fmt.Errorf("yaml unmarshal %s err: %w", fp, err)
+	}
+	for _, v := range m.Variables {
+		if v.Name == "" {
+			return nil, fmt.Errorf("variable without name in %s", fp)
+		}
+	}
+	for _, f := range m.Files {
+		f.Path = strings.Trim(f.Path, "/")
+		if _, err := path.Match(f.Path, ""); err != nil || f.Path == "" {
+			return nil, fmt.Errorf("invalid path %q in %s", f.Path, fp)
+		}
+	}
+	return m, nil
+}
+
+// merge merges the manifest of a higher layer into m.
+func (m *Manifest) merge(higher *Manifest) {
+	for _, v := range higher.Variables {
+		if i := m.variableIndex(v.Name); i >= 0 {
+			m.Variables[i] = v
+			continue
+		}
+		m.Variables = append(m.Variables, v)
+	}
+	for _, f := range higher.Files {
+		if i := m.fileIndex(f.Path); i >= 0 {
+			m.Files[i] = f
+			continue
+		}
+		m.Files = append(m.Files, f)
+	}
+	m.Hooks.Pre = append(m.Hooks.Pre, higher.Hooks.Pre...)
+	m.Hooks.Post = append(m.Hooks.Post, higher.Hooks.Post...)
+}
+
+func (m *Manifest) variableIndex(name string) int {
+	for i, v := range m.Variables {
+		if v.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *Manifest) fileIndex(p string) int {
+	for i, f := range m.Files {
+		if f.Path == p {
+			return i
+		}
+	}
+	return -1
+}
+
+// ResolveVariables fills the variables not provided in option.KVs,
+// by ask if it is not nil, or by their defaults otherwise.
+func (m *Manifest) ResolveVariables(option *params.Option, ask func(v *Variable) (interface{}, error)) error {
+	for _, v := range m.Variables {
+		if _, ok := option.KVs[v.Name]; ok {
+			continue
+		}
+		value := v.Default
+		if ask != nil {
+			var err error
+			if value, err = ask(v); err != nil {
+				return fmt.Errorf("read variable %s err: %w", v.Name, err)
+			}
+		}
+		if option.KVs == nil {
+			option.KVs = make(map[string]interface{})
+		}
+		option.KVs[v.Name] = value
+	}
+	return nil
+}
+
+// Enabled reports whether the template file rel, which is slash separated and relative to the layers,
+// is rendered, i.e. the conditions of all the rules matching it or its parent directories hold.
+func (m *Manifest) Enabled(rel string, fd *FD, option *params.Option) bool {
+	for _, f := range m.Files {
+		if ignored(rel, []string{f.Path}) && !f.When.holds(fd, option) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Condition) holds(fd *FD, option *params.Option) bool {
+	if len(c.Protocol) != 0 && !contains(c.Protocol, option.Protocol) {
+		return false
+	}
+	if c.Streaming != nil && *c.Streaming != hasStreaming(fd) {
+		return false
+	}
+	if c.RESTful != nil && *c.RESTful != hasRESTful(fd) {
+		return false
+	}
+	for k, want := range c.KVs {
+		got, ok := option.KVs[k]
+		if !ok || !matchValue(got, want) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchValue reports whether the value of a KV equals want, or any of its values if want is a list.
+// They are compared by their string forms, since JSON decodes numbers as float64 while YAML decodes them as int.
+func matchValue(got, want interface{}) bool {
+	if list, ok := want.([]interface{}); ok {
+		for _, w := range list {
+			if matchValue(got, w) {
+				return true
+			}
+		}
+		return false
+	}
+	return fmt.Sprint(got) == fmt.Sprint(want)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func hasStreaming(fd *FD) bool {
+	if fd == nil {
+		return false
+	}
+	for _, sd := range fd.Services {
+		for _, rpc := range sd.RPC {
+			if rpc.ClientStreaming || rpc.ServerStreaming {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasRESTful(fd *FD) bool {
+	if fd == nil {
+		return false
+	}
+	for _, sd := range fd.Services {
+		for _, rpc := range sd.RPC {
+			if len(rpc.RESTfulAPIInfo.ContentList) != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RunHooks runs the commands by the shell in dir, with the environment variables TRPC_PROTOFILE, TRPC_PROTOCOL,
+// TRPC_LANGUAGE and TRPC_KV_<NAME> of the KVs, e.g. TRPC_KV_DB of db.
+func RunHooks(commands []string, dir string, option *params.Option) error {
+	env := append(os.Environ(),
+		"TRPC_PROTOFILE="+option.Protofile,
+		"TRPC_PROTOCOL="+option.Protocol,
+		"TRPC_LANGUAGE="+option.Language,
+	)
+	for k, v := range option.KVs {
+		env = append(env, "TRPC_KV_"+envName(k)+"="+fmt.Sprint(v))
+	}
+	for _, command := range commands {
+		cmd := exec.Command("sh", "-c", command)
+		if runtime.GOOS == "windows" {
+			cmd = exec.Command("cmd", "/C", command)
+		}
+		cmd.Dir, cmd.Env = dir, env
+		log.Info("run hook: %s", command)
+		if buf, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("run hook %q err: %w, output: %s", command, err, buf)
+		} else if len(buf) != 0 {
+			log.Info("%s", strings.TrimSpace(string(buf)))
+		}
+	}
+	return nil
+}
+
+// envName converts the KV name into the environment variable name, e.g. db-host into DB_HOST.
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
@@ -0,0 +1,148 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package tpl
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-cmdline/descriptor"
+	"trpc.group/trpc-go/trpc-cmdline/params"
+)
+
+func TestManifest(t *testing.T) {
+	builtin, overlay := t.TempDir(), t.TempDir()
+	writeFiles(t, builtin, map[string]string{
+		ManifestFile: `
+variables:
+  - name: db
+    description: Database of the DAO layer
+    default: none
+  - name: docker
+    default: false
+files:
+  - path: Dockerfile.tpl
+    when:
+      kvs:
+        docker: true
+  - path: dao
+    when:
+      kvs:
+        db: [mysql, redis]
+  - path: stream.go.tpl
+    when:
+      streaming: true
+  - path: restful.go.tpl
+    when:
+      restful: true
+      protocol: trpc
+hooks:
+  pre: [echo builtin]
+`,
+		"main.go.tpl":        "package main",
+		"Dockerfile.tpl":     "FROM {{ .KVs.image }}",
+		"dao/dao.go.tpl":     "package dao // {{ .KVs.db }}",
+		"dao/mysql/x.go.tpl": "package mysql",
+		"stream.go.tpl":      "package main",
+		"restful.go.tpl":     "package main",
+	})
+	writeFiles(t, overlay, map[string]string{
+		ManifestFile: `
+variables:
+  - name: docker
+    default: true
+  - name: image
+    default: alpine
+files:
+  - path: restful.go.tpl
+    when:
+      protocol: [http]
+hooks:
+  pre: [echo overlay]
+  post: [echo done]
+`,
+	})
+	option := &params.Option{Assetdir: builtin, Overlays: []string{overlay}, Protocol: "trpc"}
+	m, err := LoadManifest(option)
+	require.Nil(t, err)
+	require.Equal(t, []string{"echo builtin", "echo overlay"}, m.Hooks.Pre)
+	require.Equal(t, []string{"echo done"}, m.Hooks.Post)
+	require.Equal(t, []string{builtin, overlay}, m.Hooks.Layers)
+
+	option.KVs = map[string]interface{}{"db": "mysql"}
+	require.Nil(t, m.ResolveVariables(option, nil))
+	require.Equal(t, map[string]interface{}{"db": "mysql", "docker": true, "image": "alpine"}, option.KVs)
+
+	fd := &FD{Services: []*descriptor.ServiceDescriptor{{RPC: []*descriptor.RPCDescriptor{{Name: "Hello"}}}}}
+	enabled := func() []string {
+		var files []string
+		for _, rel := range []string{"main.go.tpl", "Dockerfile.tpl", "dao", "dao/mysql/x.go.tpl",
+			"stream.go.tpl", "restful.go.tpl"} {
+			if m.Enabled(rel, fd, option) {
+				files = append(files, rel)
+			}
+		}
+		return files
+	}
+	require.Equal(t, []string{"main.go.tpl", "Dockerfile.tpl", "dao", "dao/mysql/x.go.tpl"}, enabled())
+
+	option.KVs["db"], option.KVs["docker"] = "none", false
+	fd.Services[0].RPC[0].ServerStreaming = true
+	option.Protocol = "http"
+	require.Equal(t, []string{"main.go.tpl", "stream.go.tpl", "restful.go.tpl"}, enabled(),
+		"the rule of the overlay overrides the one of the same path beneath")
+
+	// The disabled files and the manifest itself are not generated.
+	outdir := t.TempDir()
+	option.IDLType, option.Language = 0, "go"
+	require.Nil(t, GenerateFiles(fd, outdir, option))
+	var generated []string
+	require.Nil(t, filepath.Walk(outdir, func(p string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			rel, _ := filepath.Rel(outdir, p)
+			generated = append(generated, filepath.ToSlash(rel))
+		}
+		return err
+	}))
+	sort.Strings(generated)
+	require.Equal(t, []string{"main.go", "restful.go", "stream.go"}, generated)
+}
+
+func TestLoadManifestErr(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{ManifestFile: "files:\n  - path: \"[\"\n"})
+	_, err := LoadManifest(&params.Option{Assetdir: dir})
+	require.ErrorContains(t, err, `invalid path "["`)
+
+	writeFiles(t, dir, map[string]string{ManifestFile: "variable:\n  - name: x\n"})
+	_, err = LoadManifest(&params.Option{Assetdir: dir})
+	require.ErrorContains(t, err, "field variable not found")
+}
+
+func TestRunHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks are run by sh in the test")
+	}
+	dir := t.TempDir()
+	option := &params.Option{Protocol: "trpc", KVs: map[string]interface{}{"db-host": "localhost"}}
+	require.Nil(t, RunHooks([]string{`echo "$TRPC_PROTOCOL $TRPC_KV_DB_HOST" > hook.txt`}, dir, option))
+	b, err := os.ReadFile(filepath.Join(dir, "hook.txt"))
+	require.Nil(t, err)
+	require.Equal(t, "trpc localhost", strings.TrimSpace(string(b)))
+
+	err = RunHooks([]string{"echo oops; exit 3"}, dir, option)
+	require.ErrorContains(t, err, "oops")
+}
@@ -0,0 +1,166 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package tpl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"trpc.group/trpc-go/trpc-cmdline/params"
+)
+
+// IgnoreFile is the file inside a template layer listing the inherited template files to delete,
+// one slash separated path or glob pattern relative to the layer root per line, e.g.
+//
+//	# drop the generated test stub and the whole stub directory
+//	rpc/trpc_test.go.tpl
+//	stub
+//
+// The patterns only apply to the layers below, the files of the layer itself and the layers above are kept.
+const IgnoreFile = ".trpcignore"
+
+// Layers returns the template directories ordered from the highest priority to the lowest,
+// i.e. option.Overlays followed by option.Assetdir.
+func Layers(option *params.Option) []string {
+	layers := make([]string, 0, len(option.Overlays)+1)
+	layers = append(layers, option.Overlays...)
+	return append(layers, option.Assetdir)
+}
+
+// templateFile is a template file or directory resolved through the layers.
+type templateFile struct {
+	rel  string // Slash separated path relative to the layer root.
+	path string // Path of the file inside the layer which provides it.
+	info os.FileInfo
+}
+
+// resolveTemplates merges the layers into the template files to generate, sorted by their relative paths,
+// so that directories precede the files inside them.
+// A file of a higher layer overrides the one at the same relative path of the lower layers.
+func resolveTemplates(layers []string) ([]*templateFile, error) {
+	files := make(map[string]*templateFile)
+	for i := len(layers) - 1; i >= 0; i-- {
+		layer := filepath.Clean(layers[i])
+		patterns, err := loadIgnore(layer)
+		if err != nil {
+			return nil, err
+		}
+		for rel := range files {
+			if ignored(rel, patterns) {
+				delete(files, rel)
+			}
+		}
+		err = filepath.Walk(layer, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if p == layer {
+				return nil
+			}
+			rel := filepath.ToSlash(strings.TrimPrefix(p, layer+string(filepath.Separator)))
+			if rel == IgnoreFile || rel == ManifestFile {
+				return nil
+			}
+			files[rel] = &templateFile{rel: rel, path: p, info: info}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("walk template layer %s err: %w", layer, err)
+		}
+	}
+	resolved := make([]*templateFile, 0, len(files))
+	for _, f := range files {
+		resolved = append(resolved, f)
+	}
+	sort.Slice(resolved, func(i, j int) bool {
+		return resolved[i].rel < resolved[j].rel
+	})
+	return resolved, nil
+}
+
+// Lookup returns the path of the template file rel, which is slash separated and relative to the layer root,
+// in the highest layer providing it. os.ErrNotExist is returned if no layer provides it or it is ignored.
+func Lookup(option *params.Option, rel string) (string, error) {
+	layers := Layers(option)
+	for _, layer := range layers {
+		p := filepath.Join(layer, filepath.FromSlash(rel))
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("stat template %s err: %w", p, err)
+		}
+		patterns, err := loadIgnore(layer)
+		if err != nil {
+			return "", err
+		}
+		if ignored(rel, patterns) {
+			break
+		}
+	}
+	return "", fmt.Errorf("template %s not found in %v: %w", rel, layers, os.ErrNotExist)
+}
+
+// layerRelPath returns the path of entry relative to the layer containing it, or empty if it is a layer root.
+func layerRelPath(entry string, option *params.Option) string {
+	for _, layer := range Layers(option) {
+		prefix := filepath.Clean(layer) + string(filepath.Separator)
+		if strings.HasPrefix(entry, prefix) {
+			return strings.TrimPrefix(entry, prefix)
+		}
+	}
+	return ""
+}
+
+// loadIgnore loads the patterns in the IgnoreFile of the layer, blank lines and comments starting with # are skipped.
+func loadIgnore(layer string) ([]string, error) {
+	f, err := os.Open(filepath.Join(layer, IgnoreFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open %s err: %w", IgnoreFile, err)
+	}
+	defer f.Close()
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.Trim(line, "/")
+		if _, err := path.Match(line, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q in %s of %s err: %w", line, IgnoreFile, layer, err)
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s of %s err: %w", IgnoreFile, layer, err)
+	}
+	return patterns, nil
+}
+
+// ignored reports whether rel or any of its parent directories matches the patterns.
+func ignored(rel string, patterns []string) bool {
+	for _, pattern := range patterns {
+		for p := rel; p != "." && p != "/"; p = path.Dir(p) {
+			if ok, _ := path.Match(pattern, p); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package tpl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-cmdline/params"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		fp := filepath.Join(dir, filepath.FromSlash(name))
+		require.Nil(t, os.MkdirAll(filepath.Dir(fp), os.ModePerm))
+		require.Nil(t, os.WriteFile(fp, []byte(content), 0644))
+	}
+}
+
+func TestResolveTemplates(t *testing.T) {
+	builtin, team, local := t.TempDir(), t.TempDir(), t.TempDir()
+	writeFiles(t, builtin, map[string]string{
+		"main.go.tpl":              "builtin",
+		"go.mod.tpl":               "builtin",
+		"stub/go.mod.tpl":          "builtin",
+		"rpc/trpc.go.tpl":          "builtin",
+		"rpc/trpc_test.go.tpl":     "builtin",
+		"service/service.go.tpl":   "builtin",
+		"trpc_go.yaml.tpl":         "builtin",
+		"service/readme.md.tpl":    "builtin",
+		"service/service_test.tpl": "builtin",
+	})
+	writeFiles(t, team, map[string]string{
+		"main.go.tpl":            "team",
+		"trpc_go.yaml.tpl":       "team",
+		"service/service.go.tpl": "team",
+		IgnoreFile:               "# no stub\nstub/\nrpc/*_test.go.tpl\n",
+	})
+	writeFiles(t, local, map[string]string{
+		"main.go.tpl":          "local",
+		"stub/go.mod.tpl":      "local",
+		"service/extra.go.tpl": "local",
+		IgnoreFile:             "service/readme.md.tpl\n",
+	})
+
+	option := &params.Option{Assetdir: builtin, Overlays: []string{local, team}}
+	files, err := resolveTemplates(Layers(option))
+	require.Nil(t, err)
+	got := make(map[string]string)
+	var order []string
+	for _, f := range files {
+		order = append(order, f.rel)
+		if f.info.IsDir() {
+			continue
+		}
+		b, err := os.ReadFile(f.path)
+		require.Nil(t, err)
+		got[f.rel] = string(b)
+	}
+	require.Equal(t, map[string]string{
+		"main.go.tpl":              "local",
+		"go.mod.tpl":               "builtin",
+		"stub/go.mod.tpl":          "local", // Ignored by the team pack, added back by the local overlay.
+		"rpc/trpc.go.tpl":          "builtin",
+		"service/service.go.tpl":   "team",
+		"service/service_test.tpl": "builtin",
+		"service/extra.go.tpl":     "local",
+		"trpc_go.yaml.tpl":         "team",
+	}, got)
+	require.IsIncreasing(t, order)
+	require.Contains(t, order, "stub")
+
+	fp, err := Lookup(option, "main.go.tpl")
+	require.Nil(t, err)
+	require.Equal(t, filepath.Join(local, "main.go.tpl"), fp)
+	fp, err = Lookup(option, "rpc/trpc.go.tpl")
+	require.Nil(t, err)
+	require.Equal(t, filepath.Join(builtin, "rpc", "trpc.go.tpl"), fp)
+	_, err = Lookup(option, "rpc/trpc_test.go.tpl")
+	require.ErrorIs(t, err, os.ErrNotExist)
+	_, err = Lookup(option, "none.tpl")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	require.Equal(t, filepath.Join("rpc", "trpc.go.tpl"),
+		layerRelPath(filepath.Join(builtin, "rpc", "trpc.go.tpl"), option))
+	require.Equal(t, "main.go.tpl", layerRelPath(filepath.Join(local, "main.go.tpl"), option))
+
+	writeFiles(t, local, map[string]string{IgnoreFile: "[\n"})
+	_, err = resolveTemplates(Layers(option))
+	require.ErrorContains(t, err, "invalid pattern")
+}
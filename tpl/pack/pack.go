@@ -0,0 +1,131 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+// Package pack manages the template packs, which are named and versioned code templates
+// installed into a store beside the built-in assets, so that they survive the upgrades of trpc-cmdline.
+package pack
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"trpc.group/trpc-go/trpc-cmdline/config"
+)
+
+// MetaFile is the file name of the metadata at the root of a template pack.
+const MetaFile = "template.yaml"
+
+// assetsDir is the directory holding the template files inside an installed pack.
+const assetsDir = "assets"
+
+// Meta is the metadata of a template pack, e.g.
+//
+//	name: my-service
+//	version: v1.0.0
+//	description: tRPC-Go service with company defaults
+//	idl: protobuf
+//	language: go
+//
+// The rest files of the pack are the templates, which are laid out the same as the built-in ones,
+// e.g. ~/.trpc-cmdline-assets/protobuf/asset_go. They are layered on top of the built-in ones,
+// so only the changed files are needed, see tpl.Layers and tpl.IgnoreFile.
+type Meta struct {
+	Name        string `yaml:"name"`
+	Version     string `yaml:"version"`
+	Description string `yaml:"description,omitempty"`
+	IDL         string `yaml:"idl"`      // IDL type, defaults to protobuf.
+	Language    string `yaml:"language"` // Programming language, defaults to go.
+	// Source is where the pack is installed from, which is filled on installation.
+	Source string `yaml:"source,omitempty"`
+}
+
+// Pack is an installed template pack.
+type Pack struct {
+	Meta
+	Dir string // Installation directory.
+}
+
+// ID returns name@version of the pack.
+func (p *Pack) ID() string {
+	return p.Name + "@" + p.Version
+}
+
+// AssetDir returns the directory of the template files, which can be used as --assetdir.
+func (p *Pack) AssetDir() string {
+	return filepath.Join(p.Dir, assetsDir)
+}
+
+// LoadMeta loads the metadata file inside dir, a missing metadata file results in an empty Meta.
+func LoadMeta(dir string) (*Meta, error) {
+	m := &Meta{}
+	b, err := os.ReadFile(filepath.Join(dir, MetaFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s err: %w", MetaFile, err)
+	}
+	if err := yaml.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("yaml unmarshal %s err: %w", MetaFile, err)
+	}
+	return m, nil
+}
+
+// override overrides the metadata by the non-empty install options.
+func (m *Meta) override(opts InstallOptions) {
+	if opts.Name != "" {
+		m.Name = opts.Name
+	}
+	if opts.Version != "" {
+		m.Version = opts.Version
+	}
+	if opts.IDL != "" {
+		m.IDL = opts.IDL
+	}
+	if opts.Language != "" {
+		m.Language = opts.Language
+	}
+}
+
+// fix fills the defaults and validates the metadata.
+func (m *Meta) fix() error {
+	if m.IDL == "" {
+		m.IDL = config.IDLTypeProtobuf.String()
+	}
+	if m.Language == "" {
+		m.Language = "go"
+	}
+	if m.Name == "" {
+		return errors.New("pack name is required, specify it in " + MetaFile + " or by --name")
+	}
+	if m.Version == "" {
+		return errors.New("pack version is required, specify it in " + MetaFile + " or by --version")
+	}
+	for _, s := range []string{m.Name, m.Version} {
+		if strings.ContainsAny(s, `@/\`) || s == "." || s == ".." {
+			return fmt.Errorf("invalid pack name or version %q", s)
+		}
+	}
+	if m.IDL != config.IDLTypeProtobuf.String() && m.IDL != config.IDLTypeFlatBuffers.String() {
+		return fmt.Errorf("invalid idl %s of pack %s, supported: %s, %s",
+			m.IDL, m.Name, config.IDLTypeProtobuf, config.IDLTypeFlatBuffers)
+	}
+	return nil
+}
+
+// ParseRef splits the pack reference name[@version], version is empty if not specified.
+func ParseRef(ref string) (name, version string) {
+	name, version, _ = strings.Cut(ref, "@")
+	return name, version
+}
@@ -0,0 +1,145 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package pack
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"trpc.group/trpc-go/trpc-cmdline/bindata/compress"
+	"trpc.group/trpc-go/trpc-cmdline/util/fs"
+)
+
+// fetch extracts the pack files of src into the empty directory dst.
+// src can be a local directory, a gzipped tarball (*.tar.gz, *.tgz) or a local git repository,
+// ref is the revision to check out from the git repository, HEAD by default.
+func fetch(src, ref, dst string) error {
+	fi, err := os.Stat(src)
+	if err != nil {
+		return fmt.Errorf("stat %s err: %w", src, err)
+	}
+	switch {
+	case !fi.IsDir() && (strings.HasSuffix(src, ".tar.gz") || strings.HasSuffix(src, ".tgz")):
+		if ref != "" {
+			return errors.New("ref is only supported by git repositories")
+		}
+		return fetchTarball(src, dst)
+	case fi.IsDir() && isGitRepository(src):
+		return fetchGit(src, ref, dst)
+	case fi.IsDir():
+		if ref != "" {
+			return errors.New("ref is only supported by git repositories")
+		}
+		return fetchDir(src, dst)
+	default:
+		return fmt.Errorf("unsupported source %s, expect a directory, a *.tar.gz tarball or a git repository", src)
+	}
+}
+
+func fetchDir(src, dst string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return fmt.Errorf("read directory %s err: %w", src, err)
+	}
+	for _, e := range entries {
+		if e.Name() == ".git" {
+			continue
+		}
+		if err := fs.Copy(filepath.Join(src, e.Name()), filepath.Join(dst, e.Name())); err != nil {
+			return fmt.Errorf("copy %s err: %w", e.Name(), err)
+		}
+	}
+	return nil
+}
+
+func fetchTarball(src, dst string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("open %s err: %w", src, err)
+	}
+	defer f.Close()
+	if err := compress.UntarSafe(dst, f); err != nil {
+		return fmt.Errorf("untar %s err: %w", src, err)
+	}
+	// Tarballs usually wrap the files by a top level directory.
+	if _, err := os.Stat(filepath.Join(dst, MetaFile)); err == nil {
+		return nil
+	}
+	entries, err := os.ReadDir(dst)
+	if err != nil {
+		return fmt.Errorf("read directory %s err: %w", dst, err)
+	}
+	if len(entries) != 1 || !entries[0].IsDir() {
+		return nil
+	}
+	top := filepath.Join(dst, entries[0].Name())
+	if err := fetchDir(top, dst); err != nil {
+		return err
+	}
+	return os.RemoveAll(top)
+}
+
+func isGitRepository(dir string) bool {
+	_, err := git.PlainOpen(dir)
+	return err == nil
+}
+
+// fetchGit writes the files committed at ref, the uncommitted changes are not included.
+func fetchGit(src, ref, dst string) error {
+	repo, err := git.PlainOpen(src)
+	if err != nil {
+		return fmt.Errorf("open git repository %s err: %w", src, err)
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return fmt.Errorf("resolve revision %s err: %w", ref, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return fmt.Errorf("get commit %s err: %w", hash, err)
+	}
+	files, err := commit.Files()
+	if err != nil {
+		return fmt.Errorf("get files of commit %s err: %w", hash, err)
+	}
+	return files.ForEach(func(f *object.File) error {
+		fp := filepath.Join(dst, filepath.FromSlash(f.Name))
+		if err := os.MkdirAll(filepath.Dir(fp), os.ModePerm); err != nil {
+			return err
+		}
+		mode, err := f.Mode.ToOSFileMode()
+		if err != nil {
+			return fmt.Errorf("file mode of %s err: %w", f.Name, err)
+		}
+		r, err := f.Reader()
+		if err != nil {
+			return fmt.Errorf("read %s err: %w", f.Name, err)
+		}
+		defer r.Close()
+		w, err := os.OpenFile(fp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm())
+		if err != nil {
+			return err
+		}
+		defer w.Close()
+		_, err = io.Copy(w, r)
+		return err
+	})
+}
@@ -0,0 +1,340 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package pack
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"trpc.group/trpc-go/trpc-cmdline/util/semver"
+)
+
+// storeConfigFile is the file name of the store configuration, which records the default packs.
+const storeConfigFile = "templates.yaml"
+
+// DefaultDir returns the default store directory $HOME/.trpc-cmdline-templates,
+// which lives beside the built-in assets $HOME/.trpc-cmdline-assets,
+// so that the packs are kept when the built-in assets are reinstalled on upgrade.
+func DefaultDir() (string, error) {
+	u, err := user.Current()
+	if err != nil {
+		return "", fmt.Errorf("get current user err: %w", err)
+	}
+	return filepath.Join(u.HomeDir, ".trpc-cmdline-templates"), nil
+}
+
+// Store is the directory where the template packs are installed, which is laid out as:
+//
+//	templates.yaml              # default packs per IDL and language
+//	<name>/<version>/template.yaml
+//	<name>/<version>/assets/... # template files
+type Store struct {
+	dir string
+}
+
+// NewStore creates a store in dir.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// OpenDefault returns the store in DefaultDir.
+func OpenDefault() (*Store, error) {
+	dir, err := DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewStore(dir), nil
+}
+
+// Dir returns the directory of the store.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// InstallOptions are the options of Install, the non-empty fields override the metadata of the pack.
+type InstallOptions struct {
+	Name     string
+	Version  string
+	IDL      string
+	Language string
+	Ref      string // Revision of the git repository to install, HEAD by default.
+	Force    bool   // Overwrite the installed pack with the same name and version.
+}
+
+// Install installs the pack from src, which can be a local directory, a gzipped tarball or a local git repository.
+func (s *Store) Install(src string, opts InstallOptions) (*Pack, error) {
+	if err := os.MkdirAll(s.dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("create store directory %s err: %w", s.dir, err)
+	}
+	// Prepare the pack inside the store, so that it is moved into place by renaming.
+	tmp, err := os.MkdirTemp(s.dir, ".install-*")
+	if err != nil {
+		return nil, fmt.Errorf("create temporary directory err: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	files := filepath.Join(tmp, assetsDir)
+	if err := os.Mkdir(files, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("create directory %s err: %w", files, err)
+	}
+	if err := fetch(src, opts.Ref, files); err != nil {
+		return nil, fmt.Errorf("fetch pack from %s err: %w", src, err)
+	}
+
+	meta, err := LoadMeta(files)
+	if err != nil {
+		return nil, err
+	}
+	meta.override(opts)
+	if err := meta.fix(); err != nil {
+		return nil, err
+	}
+	if meta.Source, err = filepath.Abs(src); err != nil {
+		return nil, fmt.Errorf("get absolute path of %s err: %w", src, err)
+	}
+	if opts.Ref != "" {
+		meta.Source += "@" + opts.Ref
+	}
+	if err := os.Remove(filepath.Join(files, MetaFile)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("remove %s from templates err: %w", MetaFile, err)
+	}
+	if err := writeYAML(filepath.Join(tmp, MetaFile), meta); err != nil {
+		return nil, err
+	}
+
+	p := &Pack{Meta: *meta, Dir: s.packDir(meta.Name, meta.Version)}
+	if _, err := os.Stat(p.Dir); err == nil {
+		if !opts.Force {
+			return nil, fmt.Errorf("pack %s is already installed, use --force to overwrite it", p.ID())
+		}
+		if err := os.RemoveAll(p.Dir); err != nil {
+			return nil, fmt.Errorf("remove installed pack %s err: %w", p.ID(), err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(p.Dir), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("create directory of pack %s err: %w", p.ID(), err)
+	}
+	if err := os.Rename(tmp, p.Dir); err != nil {
+		return nil, fmt.Errorf("move pack %s into place err: %w", p.ID(), err)
+	}
+	return p, nil
+}
+
+// List returns the installed packs sorted by name and version.
+func (s *Store) List() ([]*Pack, error) {
+	names, err := subDirs(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var packs []*Pack
+	for _, name := range names {
+		versions, err := subDirs(filepath.Join(s.dir, name))
+		if err != nil {
+			return nil, err
+		}
+		for _, version := range versions {
+			dir := s.packDir(name, version)
+			if _, err := os.Stat(filepath.Join(dir, MetaFile)); err != nil {
+				continue // Not a pack.
+			}
+			meta, err := LoadMeta(dir)
+			if err != nil {
+				return nil, fmt.Errorf("load pack %s@%s err: %w", name, version, err)
+			}
+			packs = append(packs, &Pack{Meta: *meta, Dir: dir})
+		}
+	}
+	sort.SliceStable(packs, func(i, j int) bool {
+		if packs[i].Name != packs[j].Name {
+			return packs[i].Name < packs[j].Name
+		}
+		return semver.NewerThan(packs[j].Version, packs[i].Version)
+	})
+	return packs, nil
+}
+
+// Get returns the pack by name[@version], the latest version is returned if version is not specified.
+func (s *Store) Get(ref string) (*Pack, error) {
+	name, version := ParseRef(ref)
+	packs, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	var found *Pack
+	for _, p := range packs {
+		if p.Name != name || (version != "" && p.Version != version) {
+			continue
+		}
+		if found == nil || semver.NewerThan(p.Version, found.Version) {
+			found = p
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("template pack %s is not installed", ref)
+	}
+	return found, nil
+}
+
+// Remove removes the pack by name[@version], all versions are removed if version is not specified.
+// The defaults referring to the removed packs are cleared.
+func (s *Store) Remove(ref string) ([]*Pack, error) {
+	name, version := ParseRef(ref)
+	packs, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	var removed []*Pack
+	for _, p := range packs {
+		if p.Name != name || (version != "" && p.Version != version) {
+			continue
+		}
+		if err := os.RemoveAll(p.Dir); err != nil {
+			return nil, fmt.Errorf("remove pack %s err: %w", p.ID(), err)
+		}
+		removed = append(removed, p)
+	}
+	if len(removed) == 0 {
+		return nil, fmt.Errorf("template pack %s is not installed", ref)
+	}
+	// Remove the name directory if no version is left.
+	_ = os.Remove(filepath.Join(s.dir, name))
+
+	cfg, err := s.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	var changed bool
+	for idl, langs := range cfg.Defaults {
+		for lang, def := range langs {
+			if _, err := s.Get(def); err != nil {
+				delete(cfg.Defaults[idl], lang)
+				changed = true
+			}
+		}
+	}
+	if changed {
+		return removed, s.saveConfig(cfg)
+	}
+	return removed, nil
+}
+
+// Use sets the pack name[@version] as the default of its IDL and language.
+// The latest installed version is used if version is not specified.
+func (s *Store) Use(ref string) (*Pack, error) {
+	p, err := s.Get(ref)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := s.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Defaults[p.IDL] == nil {
+		cfg.Defaults[p.IDL] = make(map[string]string)
+	}
+	cfg.Defaults[p.IDL][p.Language] = ref
+	return p, s.saveConfig(cfg)
+}
+
+// Unuse clears the default pack of the IDL and language, so that the built-in templates are used.
+func (s *Store) Unuse(idl, lang string) error {
+	cfg, err := s.loadConfig()
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.Defaults[idl][lang]; !ok {
+		return nil
+	}
+	delete(cfg.Defaults[idl], lang)
+	return s.saveConfig(cfg)
+}
+
+// Default returns the default pack of the IDL and language, or nil if the built-in templates are used.
+func (s *Store) Default(idl, lang string) (*Pack, error) {
+	cfg, err := s.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	ref, ok := cfg.Defaults[idl][lang]
+	if !ok {
+		return nil, nil
+	}
+	p, err := s.Get(ref)
+	if err != nil {
+		return nil, fmt.Errorf("default template pack of %s/%s err: %w", idl, lang, err)
+	}
+	return p, nil
+}
+
+// storeConfig is the content of templates.yaml.
+type storeConfig struct {
+	Defaults map[string]map[string]string `yaml:"defaults"` // IDL -> language -> pack name[@version]
+}
+
+func (s *Store) loadConfig() (*storeConfig, error) {
+	cfg := &storeConfig{}
+	b, err := os.ReadFile(filepath.Join(s.dir, storeConfigFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("read %s err: %w", storeConfigFile, err)
+	}
+	if err := yaml.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("yaml unmarshal %s err: %w", storeConfigFile, err)
+	}
+	if cfg.Defaults == nil {
+		cfg.Defaults = make(map[string]map[string]string)
+	}
+	return cfg, nil
+}
+
+func (s *Store) saveConfig(cfg *storeConfig) error {
+	if err := os.MkdirAll(s.dir, os.ModePerm); err != nil {
+		return fmt.Errorf("create store directory %s err: %w", s.dir, err)
+	}
+	return writeYAML(filepath.Join(s.dir, storeConfigFile), cfg)
+}
+
+func (s *Store) packDir(name, version string) string {
+	return filepath.Join(s.dir, name, version)
+}
+
+func writeYAML(fp string, v interface{}) error {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("yaml marshal %s err: %w", filepath.Base(fp), err)
+	}
+	if err := os.WriteFile(fp, b, 0644); err != nil {
+		return fmt.Errorf("write %s err: %w", fp, err)
+	}
+	return nil
+}
+
+// subDirs returns the names of the sub directories, hidden ones are skipped.
+func subDirs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read directory %s err: %w", dir, err)
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
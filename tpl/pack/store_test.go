@@ -0,0 +1,197 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package pack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-cmdline/bindata/compress"
+)
+
+func writePack(t *testing.T, dir, meta string) {
+	t.Helper()
+	require.Nil(t, os.MkdirAll(filepath.Join(dir, "rpc"), os.ModePerm))
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "rpc", "trpc.go.tpl"), []byte("package {{.PackageName}}"), 0644))
+	if meta != "" {
+		require.Nil(t, os.WriteFile(filepath.Join(dir, MetaFile), []byte(meta), 0644))
+	}
+}
+
+func TestStore_InstallDir(t *testing.T) {
+	src := t.TempDir()
+	writePack(t, src, "name: svc\nversion: v1.0.0\ndescription: test pack\n")
+	s := NewStore(t.TempDir())
+
+	p, err := s.Install(src, InstallOptions{})
+	require.Nil(t, err)
+	require.Equal(t, "svc@v1.0.0", p.ID())
+	require.Equal(t, "protobuf", p.IDL)
+	require.Equal(t, "go", p.Language)
+	require.Equal(t, src, p.Source)
+	require.FileExists(t, filepath.Join(p.AssetDir(), "rpc", "trpc.go.tpl"))
+	require.NoFileExists(t, filepath.Join(p.AssetDir(), MetaFile))
+	require.FileExists(t, filepath.Join(p.Dir, MetaFile))
+
+	_, err = s.Install(src, InstallOptions{})
+	require.ErrorContains(t, err, "already installed")
+	_, err = s.Install(src, InstallOptions{Force: true})
+	require.Nil(t, err)
+
+	_, err = s.Install(src, InstallOptions{Ref: "v1"})
+	require.ErrorContains(t, err, "only supported by git")
+
+	entries, err := os.ReadDir(s.Dir())
+	require.Nil(t, err)
+	for _, e := range entries {
+		require.NotContains(t, e.Name(), ".install-", "temporary directory is left")
+	}
+}
+
+func TestStore_InstallInvalid(t *testing.T) {
+	src := t.TempDir()
+	writePack(t, src, "")
+	s := NewStore(t.TempDir())
+
+	_, err := s.Install(src, InstallOptions{})
+	require.ErrorContains(t, err, "name is required")
+	_, err = s.Install(src, InstallOptions{Name: "svc"})
+	require.ErrorContains(t, err, "version is required")
+	_, err = s.Install(src, InstallOptions{Name: "a/b", Version: "v1"})
+	require.ErrorContains(t, err, "invalid pack name")
+	_, err = s.Install(src, InstallOptions{Name: "svc", Version: "v1", IDL: "thrift"})
+	require.ErrorContains(t, err, "invalid idl")
+
+	p, err := s.Install(src, InstallOptions{Name: "svc", Version: "v1", IDL: "flatbuffers", Language: "cpp"})
+	require.Nil(t, err)
+	require.Equal(t, "flatbuffers", p.IDL)
+	require.Equal(t, "cpp", p.Language)
+}
+
+func TestStore_InstallTarball(t *testing.T) {
+	src := t.TempDir()
+	writePack(t, filepath.Join(src, "svc"), "name: svc\nversion: v1.0.0\n")
+	tarball := filepath.Join(t.TempDir(), "svc.tar.gz")
+	f, err := os.Create(tarball)
+	require.Nil(t, err)
+	require.Nil(t, compress.Tar(src, f))
+	require.Nil(t, f.Close())
+
+	p, err := NewStore(t.TempDir()).Install(tarball, InstallOptions{})
+	require.Nil(t, err)
+	require.Equal(t, "svc@v1.0.0", p.ID())
+	require.FileExists(t, filepath.Join(p.AssetDir(), "rpc", "trpc.go.tpl"))
+}
+
+func TestStore_InstallGit(t *testing.T) {
+	src := t.TempDir()
+	writePack(t, src, "name: svc\nversion: v1.0.0\n")
+	repo, err := git.PlainInit(src, false)
+	require.Nil(t, err)
+	w, err := repo.Worktree()
+	require.Nil(t, err)
+	require.Nil(t, w.AddGlob("."))
+	sig := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	hash, err := w.Commit("v1", &git.CommitOptions{Author: sig})
+	require.Nil(t, err)
+	_, err = repo.CreateTag("v1.0.0", hash, nil)
+	require.Nil(t, err)
+	// Uncommitted changes are not installed.
+	require.Nil(t, os.WriteFile(filepath.Join(src, "dirty.tpl"), nil, 0644))
+
+	s := NewStore(t.TempDir())
+	p, err := s.Install(src, InstallOptions{Ref: "v1.0.0"})
+	require.Nil(t, err)
+	require.Equal(t, src+"@v1.0.0", p.Source)
+	require.FileExists(t, filepath.Join(p.AssetDir(), "rpc", "trpc.go.tpl"))
+	require.NoFileExists(t, filepath.Join(p.AssetDir(), "dirty.tpl"))
+	require.NoDirExists(t, filepath.Join(p.AssetDir(), ".git"))
+
+	_, err = s.Install(src, InstallOptions{Ref: "no-such-ref", Force: true})
+	require.ErrorContains(t, err, "resolve revision")
+}
+
+func TestStore_ListUseRemove(t *testing.T) {
+	src := t.TempDir()
+	writePack(t, src, "")
+	s := NewStore(t.TempDir())
+	for _, o := range []InstallOptions{
+		{Name: "svc", Version: "v1.10.0"},
+		{Name: "svc", Version: "v1.2.0"},
+		{Name: "api", Version: "v0.1.0", Language: "cpp"},
+	} {
+		_, err := s.Install(src, o)
+		require.Nil(t, err)
+	}
+
+	packs, err := s.List()
+	require.Nil(t, err)
+	var ids []string
+	for _, p := range packs {
+		ids = append(ids, p.ID())
+	}
+	require.Equal(t, []string{"api@v0.1.0", "svc@v1.2.0", "svc@v1.10.0"}, ids)
+
+	p, err := s.Get("svc")
+	require.Nil(t, err)
+	require.Equal(t, "svc@v1.10.0", p.ID())
+	_, err = s.Get("svc@v2.0.0")
+	require.ErrorContains(t, err, "not installed")
+
+	def, err := s.Default("protobuf", "go")
+	require.Nil(t, err)
+	require.Nil(t, def)
+	_, err = s.Use("svc@v1.2.0")
+	require.Nil(t, err)
+	_, err = s.Use("api")
+	require.Nil(t, err)
+	def, err = s.Default("protobuf", "go")
+	require.Nil(t, err)
+	require.Equal(t, "svc@v1.2.0", def.ID())
+
+	// Removing another version keeps the default.
+	removed, err := s.Remove("svc@v1.10.0")
+	require.Nil(t, err)
+	require.Len(t, removed, 1)
+	def, err = s.Default("protobuf", "go")
+	require.Nil(t, err)
+	require.Equal(t, "svc@v1.2.0", def.ID())
+
+	// Removing the default one clears it.
+	removed, err = s.Remove("svc")
+	require.Nil(t, err)
+	require.Len(t, removed, 1)
+	require.NoDirExists(t, filepath.Join(s.Dir(), "svc"))
+	def, err = s.Default("protobuf", "go")
+	require.Nil(t, err)
+	require.Nil(t, def)
+	_, err = s.Remove("svc")
+	require.ErrorContains(t, err, "not installed")
+
+	require.Nil(t, s.Unuse("protobuf", "cpp"))
+	def, err = s.Default("protobuf", "cpp")
+	require.Nil(t, err)
+	require.Nil(t, def)
+}
+
+func TestParseRef(t *testing.T) {
+	name, version := ParseRef("svc@v1.0.0")
+	require.Equal(t, "svc", name)
+	require.Equal(t, "v1.0.0", version)
+	name, version = ParseRef("svc")
+	require.Equal(t, "svc", name)
+	require.Empty(t, version)
+}
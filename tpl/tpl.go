@@ -13,6 +13,7 @@ package tpl
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -41,7 +42,15 @@ const (
 type FD = descriptor.FileDescriptor
 
 // GenerateFiles processes the go template files and outputs them to the outputdir directory.
+// The template files are resolved through option.Overlays on top of option.Assetdir, see Layers.
 func GenerateFiles(fd *FD, outputdir string, option *params.Option) error {
+	return ForEachTemplate(fd, outputdir, option, func(_ string, err error) error { return err })
+}
+
+// ForEachTemplate processes the template files the same as GenerateFiles, and calls fn after each of them
+// with its slash separated path relative to the layers and the error of processing it,
+// so that the rest are still processed if fn ignores the error. It stops once fn returns a non-nil error.
+func ForEachTemplate(fd *FD, outputdir string, option *params.Option, fn func(rel string, err error) error) error {
 	// Preparing output directory.
 	if err := fs.PrepareOutputdir(outputdir); err != nil {
 		return fmt.Errorf("create outputdir: %v", err)
@@ -57,21 +66,29 @@ func GenerateFiles(fd *FD, outputdir string, option *params.Option) error {
 		cfg = c
 	}
 
-	// Traverse through the template files for processing.
-	f := func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if path == option.Assetdir {
-			return nil
+	// Resolve the template files through the overlays and process the ones enabled by the manifest.
+	files, err := resolveTemplates(Layers(option))
+	if err != nil {
+		return err
+	}
+	manifest, err := LoadManifest(option)
+	if err != nil {
+		return err
+	}
+	mixed := MixedOptions{
+		OutputDir: outputdir,
+		Cfg:       cfg,
+	}
+	for _, f := range files {
+		if !manifest.Enabled(f.rel, fd, option) {
+			log.Debug("skip template %s by the conditions in %s", f.rel, ManifestFile)
+			continue
 		}
-		mixed := MixedOptions{
-			OutputDir: outputdir,
-			Cfg:       cfg,
+		if err := fn(f.rel, ProcessTemplateFile(fd, f.path, f.info, option, &mixed)); err != nil {
+			return err
 		}
-		return ProcessTemplateFile(fd, path, info, option, &mixed)
 	}
-	return filepath.Walk(option.Assetdir, f)
+	return nil
 }
 
 // GenerateOptions is extension options.
@@ -81,6 +98,9 @@ type GenerateOptions struct {
 	// The index position of the service in proto, starting from 0,
 	// and -1 indicates that it does not exist.
 	methodIndex int
+	// The message or enum to be generated by the templates rendered per message or per enum.
+	message *descriptor.MessageDescriptor
+	enum    *descriptor.EnumDescriptor
 }
 
 // ServiceIndex returns the index of the service to be generated.
@@ -99,6 +119,22 @@ func (o *GenerateOptions) MethodIndex() int {
 	return MethodIndexDefault
 }
 
+// Message returns the message to be generated, which is nil unless the template is rendered per message.
+func (o *GenerateOptions) Message() *descriptor.MessageDescriptor {
+	if o != nil {
+		return o.message
+	}
+	return nil
+}
+
+// Enum returns the enum to be generated, which is nil unless the template is rendered per enum.
+func (o *GenerateOptions) Enum() *descriptor.EnumDescriptor {
+	if o != nil {
+		return o.enum
+	}
+	return nil
+}
+
 // GenerateFile generates file for fd.
 func GenerateFile(fd *FD, infile, outfile string, opt *params.Option, extOpt *GenerateOptions) error {
 	if !filepath.IsAbs(opt.Assetdir) {
@@ -117,8 +153,23 @@ func GenerateFile(fd *FD, infile, outfile string, opt *params.Option, extOpt *Ge
 	}
 	defer fout.Close()
 
+	err = executeTemplate(fout, fd, infile, opt, extOpt)
+	log.Debug("outfile:%s, genExtOption:%+v", outfile, extOpt)
+	return err
+}
+
+// Render executes the template infile for fd and writes the result into w, no file is created.
+func Render(w io.Writer, fd *FD, infile string, opt *params.Option) error {
+	return executeTemplate(w, fd, infile, opt, nil)
+}
+
+// executeTemplate executes the template infile and writes the result into w.
+func executeTemplate(w io.Writer, fd *FD, infile string, opt *params.Option, extOpt *GenerateOptions) error {
 	// template execute and populate the output file
-	var tplInstance *template.Template
+	var (
+		tplInstance *template.Template
+		err         error
+	)
 	var baseName = filepath.Base(infile)
 
 	if funcMap == nil {
@@ -132,21 +183,23 @@ func GenerateFile(fd *FD, infile, outfile string, opt *params.Option, extOpt *Ge
 
 	// Pass in descriptor information, command-line control parameter information,
 	// and other serviceIndex information required by other split files.
-	err = tplInstance.Execute(fout, struct {
+	err = tplInstance.Execute(w, struct {
 		*descriptor.FileDescriptor
 		*params.Option
 		ServiceIndex       int
 		MethodIndex        int
+		Message            *descriptor.MessageDescriptor
+		Enum               *descriptor.EnumDescriptor
 		TRPCCmdlineVersion string
 	}{
 		fd,
 		opt,
 		extOpt.ServiceIndex(),
 		extOpt.MethodIndex(),
+		extOpt.Message(),
+		extOpt.Enum(),
 		config.TRPCCliVersion,
 	})
-	log.Debug("outfile:%s, genExtOption:%+v", outfile, extOpt)
-
 	if err != nil {
 		return fmt.Errorf("template execute err: %v", err)
 	}
@@ -159,7 +212,7 @@ func ProcessTemplateFile(fd *FD, entry string, info os.FileInfo, option *params.
 	log.Debug("file entry srcPath:%s", entry)
 
 	// keep same files/folders hierarchy in the outputdir/assetdir
-	relPath := strings.TrimPrefix(entry, filepath.Clean(option.Assetdir)+string(filepath.Separator))
+	relPath := layerRelPath(entry, option)
 	if len(relPath) == 0 {
 		return nil
 	}
@@ -184,7 +237,16 @@ func ProcessTemplateFile(fd *FD, entry string, info os.FileInfo, option *params.
 			return generateClientStub(fd, entry, outdir, opts.Cfg, option)
 		}
 	}
+	// if `entry` is rendered per message or per enum
+	if kind := entityKindOf(relPath, opts.Cfg); kind != entityNone {
+		return generatePerEntity(fd, entry, outPath, kind, opts.Cfg, option)
+	}
 	// if `entry` is normal go template file
+	if option.Update && fileExists(outPath) {
+		// Files like main.go and trpc_go.yaml are likely edited by hand, keep them in update mode.
+		log.Debug("update mode, keep existing file %s", outPath)
+		return nil
+	}
 	return GenerateFile(fd, entry, outPath, option, nil)
 }
 
@@ -210,11 +272,17 @@ func isRPCClientStubFile(fp string, rpcClientStub []string) bool {
 
 // generateServerStub generates server-side code corresponding to the service in the IDL.
 func generateServerStub(fd *FD, infile, outdir string, cfg *config.Template, opt *params.Option) error {
+	var err error
 	if opt.PerMethod {
-		return generatePerMethod(fd, infile, outdir, cfg.LangFileExt, opt)
+		err = generatePerMethod(fd, infile, outdir, cfg.LangFileExt, opt)
+	} else {
+		var camelcase bool
+		err = generatePerService(fd, infile, outdir, cfg.LangFileExt, camelcase, opt)
+	}
+	if err != nil || !opt.Update {
+		return err
 	}
-	var camelcase bool
-	return generatePerService(fd, infile, outdir, cfg.LangFileExt, camelcase, opt)
+	return markRemovedRPCs(fd, outdir, false)
 }
 
 // generatePerService splits the generated code into separate files per service.
@@ -225,7 +293,8 @@ func generatePerService(fd *FD, infile, outdir, langFileExt string, camelcase bo
 			base = strcase.ToCamel(sd.Name) + "." + langFileExt
 		}
 		outfile := filepath.Join(outdir, base)
-		if err := GenerateFile(fd, infile, outfile, opt, &GenerateOptions{sIdx, -1}); err != nil {
+		extOpt := &GenerateOptions{serviceIndex: sIdx, methodIndex: -1}
+		if err := generateOrMerge(fd, infile, outfile, opt, extOpt); err != nil {
 			return err
 		}
 	}
@@ -238,7 +307,8 @@ func generatePerMethod(fd *FD, inFile, outdir, langFileExt string, option *param
 		for mIdx, method := range sd.RPC {
 			base := strcase.ToSnake(sd.Name) + "_" + strcase.ToSnake(method.Name) + "." + langFileExt
 			outfile := filepath.Join(outdir, base)
-			if err := GenerateFile(fd, inFile, outfile, option, &GenerateOptions{sIdx, mIdx}); err != nil {
+			extOpt := &GenerateOptions{serviceIndex: sIdx, methodIndex: mIdx}
+			if err := generateOrMerge(fd, inFile, outfile, option, extOpt); err != nil {
 				return err
 			}
 		}
@@ -251,12 +321,15 @@ func generateServerTestStub(fd *FD, entry, outdir, langFileExt string, option *p
 	for idx, sd := range fd.Services {
 		base := strcase.ToSnake(sd.Name) + "_test." + langFileExt
 		outfile := filepath.Join(outdir, base)
-		if err := GenerateFile(fd, entry, outfile, option, &GenerateOptions{serviceIndex: idx}); err != nil {
+		if err := generateOrMerge(fd, entry, outfile, option, &GenerateOptions{serviceIndex: idx}); err != nil {
 			return err
 		}
 		log.Debug("entry destPath: %s", outfile)
 		continue
 	}
+	if option.Update {
+		return markRemovedRPCs(fd, outdir, true)
+	}
 	return nil
 }
 
@@ -288,7 +361,7 @@ func generateClientStub(fd *FD, infile, outdir string, cfg *config.Template, opt
 			}
 		}
 		outfile := filepath.Join(outdir, base)
-		if err := GenerateFile(fd, infile, outfile, opt, &GenerateOptions{idx, -1}); err != nil {
+		if err := GenerateFile(fd, infile, outfile, opt, &GenerateOptions{serviceIndex: idx, methodIndex: -1}); err != nil {
 			return err
 		}
 	}
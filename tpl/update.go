@@ -0,0 +1,151 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package tpl
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"trpc.group/trpc-go/trpc-cmdline/params"
+	"trpc.group/trpc-go/trpc-cmdline/util/gomerge"
+	"trpc.group/trpc-go/trpc-cmdline/util/lang"
+	"trpc.group/trpc-go/trpc-cmdline/util/log"
+)
+
+// generateOrMerge generates outfile from the template infile.
+// In update mode, an existing outfile is not overwritten, instead the generated declarations are merged into it,
+// so that the hand-written method bodies are preserved.
+func generateOrMerge(fd *FD, infile, outfile string, opt *params.Option, extOpt *GenerateOptions) error {
+	if !opt.Update || !fileExists(outfile) {
+		return GenerateFile(fd, infile, outfile, opt, extOpt)
+	}
+	existing, err := os.ReadFile(outfile)
+	if err != nil {
+		return fmt.Errorf("read existing file %s err: %w", outfile, err)
+	}
+	var buf bytes.Buffer
+	if err := executeTemplate(&buf, fd, infile, opt, extOpt); err != nil {
+		return err
+	}
+	res, err := gomerge.Merge(existing, buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("merge generated code into %s err: %w", outfile, err)
+	}
+	if !res.Changed() {
+		log.Debug("update mode, %s is up to date", outfile)
+		return nil
+	}
+	if err := os.WriteFile(outfile, res.Src, 0644); err != nil {
+		return fmt.Errorf("write merged file %s err: %w", outfile, err)
+	}
+	for _, key := range res.Added {
+		log.Info("update %s: add %s", filepath.Base(outfile), key)
+	}
+	for _, key := range res.Updated {
+		log.Info("update %s: update the signature of %s", filepath.Base(outfile), key)
+	}
+	return nil
+}
+
+// markRemovedRPCs looks for the methods (or the tests if test is true) generated for RPCs
+// which are no longer defined in the IDL, and marks them with a TODO comment.
+// They are never deleted, since they may contain hand-written logic.
+func markRemovedRPCs(fd *FD, outdir string, test bool) error {
+	entries, err := os.ReadDir(outdir)
+	if err != nil {
+		return fmt.Errorf("read dir %s err: %w", outdir, err)
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") != test {
+			continue
+		}
+		if err := markRemovedRPCsInFile(fd, filepath.Join(outdir, name), test); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func markRemovedRPCsInFile(fd *FD, fp string, test bool) error {
+	src, err := os.ReadFile(fp)
+	if err != nil {
+		return fmt.Errorf("read file %s err: %w", fp, err)
+	}
+	keys, err := gomerge.FuncKeys(src)
+	if err != nil {
+		// Not a valid Go file, it is not generated by us.
+		log.Debug("skip marking removed rpc in %s: %v", fp, err)
+		return nil
+	}
+	marks := make(map[string]string)
+	for _, key := range keys {
+		if rpc, ok := removedRPC(fd, key, test); ok {
+			marks[key] = fmt.Sprintf(
+				"TODO: RPC %s has been removed from %s, delete this if it is no longer needed.",
+				rpc, filepath.Base(fd.FilePath))
+		}
+	}
+	if len(marks) == 0 {
+		return nil
+	}
+	removed := make([]string, 0, len(marks))
+	for key := range marks {
+		removed = append(removed, key)
+	}
+	sort.Strings(removed)
+	for _, key := range removed {
+		log.Info("update %s: %s belongs to a removed RPC, please check it", filepath.Base(fp), key)
+	}
+	src, newlyMarked, err := gomerge.Mark(src, marks)
+	if err != nil {
+		return fmt.Errorf("mark removed rpc in %s err: %w", fp, err)
+	}
+	if len(newlyMarked) == 0 {
+		return nil
+	}
+	return os.WriteFile(fp, src, 0644)
+}
+
+// removedRPC reports whether the declaration with key belongs to an RPC which is not defined in fd,
+// the name of the RPC is returned.
+//
+// The names follow the service_rpc.go.tpl and service_rpc_test.go.tpl templates, i.e.
+// ${service}Impl.${RPC} for the implementations, and Test_${Service}_${RPC} or Test_${service}Impl_${RPC} for tests.
+func removedRPC(fd *FD, key string, test bool) (string, bool) {
+	for _, sd := range fd.Services {
+		svc := lang.Camelcase(sd.Name)
+		rpcs := make(map[string]bool, len(sd.RPC))
+		for _, rpc := range sd.RPC {
+			rpcs[lang.Camelcase(rpc.Name)] = true
+		}
+		prefixes := []string{lang.UnTitle(svc) + "Impl."}
+		if test {
+			prefixes = []string{"Test_" + svc + "_", "Test_" + lang.UnTitle(svc) + "Impl_"}
+		}
+		for _, prefix := range prefixes {
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			rpc := strings.TrimPrefix(key, prefix)
+			return rpc, rpc != "" && !rpcs[rpc]
+		}
+	}
+	return "", false
+}
+
+func fileExists(fp string) bool {
+	_, err := os.Stat(fp)
+	return err == nil
+}
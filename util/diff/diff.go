@@ -0,0 +1,185 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+// Package diff compares directory trees and renders the changes as unified diffs.
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// Status is the change status of a file.
+type Status string
+
+// Change status of files.
+const (
+	Added    Status = "added"
+	Modified Status = "modified"
+	Deleted  Status = "deleted"
+)
+
+// File is the change of a single file.
+type File struct {
+	Path   string // Slash separated path relative to the compared directories.
+	Status Status
+	Old    []byte // Content in the old directory, nil if added.
+	New    []byte // Content in the new directory, nil if deleted.
+}
+
+// IgnoreFunc reports whether the file or directory at the slash separated relative path should be skipped.
+type IgnoreFunc func(rel string, isDir bool) bool
+
+// IgnoreGitDir skips the .git directory.
+func IgnoreGitDir(rel string, isDir bool) bool {
+	return isDir && filepath.Base(rel) == ".git"
+}
+
+// Dirs compares the regular files of oldDir and newDir, and returns the changes sorted by path.
+// A directory which does not exist is treated as empty.
+func Dirs(oldDir, newDir string, ignore IgnoreFunc) ([]*File, error) {
+	olds, err := ReadDir(oldDir, ignore)
+	if err != nil {
+		return nil, err
+	}
+	news, err := ReadDir(newDir, ignore)
+	if err != nil {
+		return nil, err
+	}
+	return Maps(olds, news), nil
+}
+
+// Maps compares two sets of files keyed by path, and returns the changes sorted by path.
+func Maps(olds, news map[string][]byte) []*File {
+	var files []*File
+	for p, n := range news {
+		o, ok := olds[p]
+		switch {
+		case !ok:
+			files = append(files, &File{Path: p, Status: Added, New: n})
+		case !bytes.Equal(o, n):
+			files = append(files, &File{Path: p, Status: Modified, Old: o, New: n})
+		}
+	}
+	for p, o := range olds {
+		if _, ok := news[p]; !ok {
+			files = append(files, &File{Path: p, Status: Deleted, Old: o})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	return files
+}
+
+// ReadDir reads all the regular files under dir, keyed by slash separated relative path.
+// An empty map is returned if dir does not exist.
+func ReadDir(dir string, ignore IgnoreFunc) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	if _, err := os.Stat(dir); err != nil {
+		if os.IsNotExist(err) {
+			return files, nil
+		}
+		return nil, err
+	}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if ignore != nil && ignore(rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[rel] = b
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read files under %s err: %w", dir, err)
+	}
+	return files, nil
+}
+
+// Unified returns the change in the format of unified diff.
+func (f *File) Unified() string {
+	from, to := "a/"+f.Path, "b/"+f.Path
+	switch f.Status {
+	case Added:
+		from = "/dev/null"
+	case Deleted:
+		to = "/dev/null"
+	}
+	if isBinary(f.Old) || isBinary(f.New) {
+		return fmt.Sprintf("--- %s\n+++ %s\nBinary files differ\n", from, to)
+	}
+	s, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(f.Old),
+		B:        splitLines(f.New),
+		FromFile: from,
+		ToFile:   to,
+		Context:  3,
+	})
+	if err != nil {
+		return fmt.Sprintf("--- %s\n+++ %s\n%v\n", from, to, err)
+	}
+	return s
+}
+
+// Summary counts the changes by status.
+func Summary(files []*File) map[Status]int {
+	m := map[Status]int{Added: 0, Modified: 0, Deleted: 0}
+	for _, f := range files {
+		m[f.Status]++
+	}
+	return m
+}
+
+func splitLines(b []byte) []string {
+	if len(b) == 0 {
+		return nil
+	}
+	lines := difflib.SplitLines(string(b))
+	// difflib.SplitLines always appends a line break to the last line,
+	// which produces an empty trailing line if the content ends with a line break.
+	if strings.HasSuffix(string(b), "\n") {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func isBinary(b []byte) bool {
+	const sniffLen = 8000
+	if len(b) > sniffLen {
+		b = b[:sniffLen]
+	}
+	return bytes.IndexByte(b, 0) != -1
+}
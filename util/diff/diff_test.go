@@ -0,0 +1,86 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		fp := filepath.Join(dir, filepath.FromSlash(name))
+		require.Nil(t, os.MkdirAll(filepath.Dir(fp), os.ModePerm))
+		require.Nil(t, os.WriteFile(fp, []byte(content), 0644))
+	}
+}
+
+func TestDirs(t *testing.T) {
+	oldDir, newDir := t.TempDir(), t.TempDir()
+	writeFiles(t, oldDir, map[string]string{
+		"same.go":        "package main\n",
+		"modified.go":    "package main\n\nfunc a() {}\n",
+		"sub/deleted.go": "package sub\n",
+		".git/HEAD":      "ref: refs/heads/master\n",
+	})
+	writeFiles(t, newDir, map[string]string{
+		"same.go":      "package main\n",
+		"modified.go":  "package main\n\nfunc b() {}\n",
+		"sub/added.go": "package sub\n",
+	})
+
+	files, err := Dirs(oldDir, newDir, IgnoreGitDir)
+	require.Nil(t, err)
+	require.Len(t, files, 3)
+	require.Equal(t, "modified.go", files[0].Path)
+	require.Equal(t, Modified, files[0].Status)
+	require.Equal(t, "sub/added.go", files[1].Path)
+	require.Equal(t, Added, files[1].Status)
+	require.Equal(t, "sub/deleted.go", files[2].Path)
+	require.Equal(t, Deleted, files[2].Status)
+	require.Equal(t, map[Status]int{Added: 1, Modified: 1, Deleted: 1}, Summary(files))
+
+	files, err = Dirs(filepath.Join(oldDir, "not_exist"), newDir, nil)
+	require.Nil(t, err)
+	require.Len(t, files, 3)
+	for _, f := range files {
+		require.Equal(t, Added, f.Status)
+	}
+}
+
+func TestFile_Unified(t *testing.T) {
+	f := &File{
+		Path:   "a.go",
+		Status: Modified,
+		Old:    []byte("package main\n\nfunc a() {}\n"),
+		New:    []byte("package main\n\nfunc b() {}\n"),
+	}
+	require.Equal(t, `--- a/a.go
++++ b/a.go
+@@ -1,3 +1,3 @@
+ package main
+ 
+-func a() {}
++func b() {}
+`, f.Unified())
+
+	f = &File{Path: "b.go", Status: Added, New: []byte("package b\n")}
+	require.Equal(t, `--- /dev/null
++++ b/b.go
+@@ -0,0 +1 @@
++package b
+`, f.Unified())
+
+	f = &File{Path: "c.bin", Status: Deleted, Old: []byte{0x00, 0x01}}
+	require.Equal(t, "--- a/c.bin\n+++ /dev/null\nBinary files differ\n", f.Unified())
+}
@@ -0,0 +1,383 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+// Package gomerge merges newly generated Go source into existing, possibly hand-written, Go source.
+//
+// Only the declarations that need to change are touched, the rest of the existing source
+// (method bodies, comments, formatting) is kept byte for byte.
+package gomerge
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// Result is the result of Merge.
+type Result struct {
+	Src     []byte   // Merged source.
+	Added   []string // Keys of the declarations added.
+	Updated []string // Keys of the methods whose signatures are updated.
+	Imports []string // Paths of the imports added.
+}
+
+// Changed reports whether the existing source is changed.
+func (r *Result) Changed() bool {
+	return len(r.Added) != 0 || len(r.Updated) != 0 || len(r.Imports) != 0
+}
+
+// Key returns the key of a declaration, which is "Recv.Name" for methods and "Name" for others.
+// The pointer of the receiver type is ignored.
+func Key(recv, name string) string {
+	if recv == "" {
+		return name
+	}
+	return recv + "." + name
+}
+
+// FuncKeys returns the keys of all the function and method declarations in src.
+func FuncKeys(src []byte) ([]string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("parse go source err: %w", err)
+	}
+	var keys []string
+	for _, d := range f.Decls {
+		if fd, ok := d.(*ast.FuncDecl); ok {
+			keys = append(keys, funcKey(fd))
+		}
+	}
+	return keys, nil
+}
+
+// Merge merges generated into existing:
+//   - functions, methods and types only declared in generated are appended,
+//   - imports only declared in generated are added,
+//   - methods declared in both but with different parameter or result types get the signature of generated,
+//     and the references to the replaced types inside the method body are updated accordingly.
+//
+// Declarations only declared in existing are kept as they are.
+func Merge(existing, generated []byte) (*Result, error) {
+	generated, err := format.Source(generated)
+	if err != nil {
+		return nil, fmt.Errorf("format generated go source err: %w", err)
+	}
+	m := &merger{fset: token.NewFileSet(), oldSrc: existing, genSrc: generated}
+	if m.old, err = parser.ParseFile(m.fset, "existing.go", existing, parser.ParseComments); err != nil {
+		return nil, fmt.Errorf("parse existing go source err: %w", err)
+	}
+	if m.gen, err = parser.ParseFile(m.fset, "generated.go", generated, parser.ParseComments); err != nil {
+		return nil, fmt.Errorf("parse generated go source err: %w", err)
+	}
+	if err := m.merge(); err != nil {
+		return nil, err
+	}
+	src := m.apply()
+	if _, err := parser.ParseFile(token.NewFileSet(), "", src, parser.ParseComments); err != nil {
+		return nil, fmt.Errorf("parse merged go source err: %w", err)
+	}
+	m.res.Src = src
+	return &m.res, nil
+}
+
+// Mark inserts a line comment before each of the declarations whose key is in marks,
+// unless the doc comment of the declaration already contains the comment.
+// The keys of the newly marked declarations are returned.
+func Mark(src []byte, marks map[string]string) ([]byte, []string, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse go source err: %w", err)
+	}
+	var (
+		edits  []edit
+		marked []string
+	)
+	for _, d := range f.Decls {
+		fd, ok := d.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		key := funcKey(fd)
+		msg, ok := marks[key]
+		if !ok || (fd.Doc != nil && strings.Contains(fd.Doc.Text(), msg)) {
+			continue
+		}
+		off := fset.Position(fd.Pos()).Offset
+		edits = append(edits, edit{start: off, end: off, text: "// " + msg + "\n"})
+		marked = append(marked, key)
+	}
+	return applyEdits(src, edits), marked, nil
+}
+
+type edit struct {
+	start, end int
+	text       string
+}
+
+type merger struct {
+	fset           *token.FileSet
+	old, gen       *ast.File
+	oldSrc, genSrc []byte
+	edits          []edit
+	appends        []string
+	res            Result
+}
+
+func (m *merger) merge() error {
+	oldFuncs := make(map[string]*ast.FuncDecl)
+	oldTypes := make(map[string]bool)
+	for _, d := range m.old.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			oldFuncs[funcKey(d)] = d
+		case *ast.GenDecl:
+			if d.Tok == token.TYPE {
+				for _, s := range d.Specs {
+					oldTypes[s.(*ast.TypeSpec).Name.Name] = true
+				}
+			}
+		}
+	}
+	for _, d := range m.gen.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			key := funcKey(d)
+			old, ok := oldFuncs[key]
+			if !ok {
+				m.appendDecl(d, d.Doc, key)
+				continue
+			}
+			if d.Recv != nil {
+				m.updateSignature(old, d, key)
+			}
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			if !d.Lparen.IsValid() {
+				if name := d.Specs[0].(*ast.TypeSpec).Name.Name; !oldTypes[name] {
+					m.appendDecl(d, d.Doc, name)
+				}
+				continue
+			}
+			// The types of a group are appended one by one, as some of them may be declared in existing.
+			for _, s := range d.Specs {
+				if ts := s.(*ast.TypeSpec); !oldTypes[ts.Name.Name] {
+					m.appendTypeSpec(ts)
+				}
+			}
+		}
+	}
+	m.mergeImports()
+	return nil
+}
+
+func (m *merger) appendDecl(d ast.Decl, doc *ast.CommentGroup, key string) {
+	start := d.Pos()
+	if doc != nil {
+		start = doc.Pos()
+	}
+	m.appends = append(m.appends, m.genText(start, d.End()))
+	m.res.Added = append(m.res.Added, key)
+}
+
+// appendTypeSpec appends the type spec of a grouped declaration as a declaration of its own.
+func (m *merger) appendTypeSpec(s *ast.TypeSpec) {
+	end := s.End()
+	if s.Comment != nil {
+		end = s.Comment.End()
+	}
+	text := "type " + m.genText(s.Pos(), end)
+	if s.Doc != nil {
+		text = m.genText(s.Doc.Pos(), s.Doc.End()) + "\n" + text
+	}
+	// The specs inside the parentheses are indented by one more level.
+	m.appends = append(m.appends, strings.ReplaceAll(text, "\n\t", "\n"))
+	m.res.Added = append(m.res.Added, s.Name.Name)
+}
+
+// updateSignature replaces the signature of old with the one of gen if their parameter or result types differ.
+func (m *merger) updateSignature(old, gen *ast.FuncDecl, key string) {
+	oldTypes, genTypes := fieldTypes(m.fset, old.Type), fieldTypes(m.fset, gen.Type)
+	if equalStrings(oldTypes, genTypes) {
+		return
+	}
+	m.edits = append(m.edits, edit{
+		start: m.fset.Position(old.Type.Params.Pos()).Offset,
+		end:   m.fset.Position(old.Type.End()).Offset,
+		text:  m.genText(gen.Type.Params.Pos(), gen.Type.End()),
+	})
+	m.res.Updated = append(m.res.Updated, key)
+	if old.Body == nil || len(oldTypes) != len(genTypes) {
+		return
+	}
+	renames := make(map[string]string)
+	for i := range oldTypes {
+		o, g := strings.TrimLeft(oldTypes[i], "*"), strings.TrimLeft(genTypes[i], "*")
+		if o != g {
+			renames[o] = g
+		}
+	}
+	m.renameTypes(old.Body, renames)
+}
+
+// renameTypes replaces the references to the types in renames inside node.
+func (m *merger) renameTypes(node ast.Node, renames map[string]string) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr, *ast.Ident:
+			if to, ok := renames[exprString(m.fset, n.(ast.Expr))]; ok {
+				m.edits = append(m.edits, edit{
+					start: m.fset.Position(n.Pos()).Offset,
+					end:   m.fset.Position(n.End()).Offset,
+					text:  to,
+				})
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func (m *merger) mergeImports() {
+	oldPaths := make(map[string]bool)
+	for _, s := range m.old.Imports {
+		oldPaths[s.Path.Value] = true
+	}
+	var specs []string
+	for _, s := range m.gen.Imports {
+		if oldPaths[s.Path.Value] {
+			continue
+		}
+		oldPaths[s.Path.Value] = true
+		specs = append(specs, m.genText(s.Pos(), s.End()))
+		m.res.Imports = append(m.res.Imports, strings.Trim(s.Path.Value, "\"`"))
+	}
+	if len(specs) == 0 {
+		return
+	}
+	var last *ast.GenDecl
+	for _, d := range m.old.Decls {
+		if gd, ok := d.(*ast.GenDecl); ok && gd.Tok == token.IMPORT {
+			last = gd
+		}
+	}
+	switch {
+	case last == nil:
+		off := m.fset.Position(m.old.Name.End()).Offset
+		m.edits = append(m.edits, edit{start: off, end: off,
+			text: "\n\nimport (\n\t" + strings.Join(specs, "\n\t") + "\n)"})
+	case last.Lparen.IsValid():
+		off := m.fset.Position(last.Rparen).Offset
+		m.edits = append(m.edits, edit{start: off, end: off, text: "\t" + strings.Join(specs, "\n\t") + "\n"})
+	default:
+		off := m.fset.Position(last.End()).Offset
+		m.edits = append(m.edits, edit{start: off, end: off,
+			text: "\n\nimport (\n\t" + strings.Join(specs, "\n\t") + "\n)"})
+	}
+}
+
+func (m *merger) genText(start, end token.Pos) string {
+	return string(m.genSrc[m.fset.Position(start).Offset:m.fset.Position(end).Offset])
+}
+
+func (m *merger) apply() []byte {
+	src := applyEdits(m.oldSrc, m.edits)
+	if len(m.appends) == 0 {
+		return src
+	}
+	buf := bytes.NewBuffer(bytes.TrimRight(src, "\n"))
+	buf.WriteString("\n")
+	for _, text := range m.appends {
+		buf.WriteString("\n" + text + "\n")
+	}
+	return buf.Bytes()
+}
+
+// applyEdits applies the non-overlapping edits to src.
+func applyEdits(src []byte, edits []edit) []byte {
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].start > edits[j].start
+	})
+	out := append([]byte{}, src...)
+	for _, e := range edits {
+		out = append(out[:e.start], append([]byte(e.text), out[e.end:]...)...)
+	}
+	return out
+}
+
+func funcKey(fd *ast.FuncDecl) string {
+	if fd.Recv == nil || len(fd.Recv.List) == 0 {
+		return fd.Name.Name
+	}
+	return Key(recvTypeName(fd.Recv.List[0].Type), fd.Name.Name)
+}
+
+func recvTypeName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return recvTypeName(e.X)
+	case *ast.ParenExpr:
+		return recvTypeName(e.X)
+	case *ast.IndexExpr:
+		return recvTypeName(e.X)
+	case *ast.Ident:
+		return e.Name
+	default:
+		return ""
+	}
+}
+
+// fieldTypes returns the types of the parameters followed by the types of the results, one per name.
+func fieldTypes(fset *token.FileSet, ft *ast.FuncType) []string {
+	var types []string
+	for _, fl := range []*ast.FieldList{ft.Params, ft.Results} {
+		if fl == nil {
+			continue
+		}
+		for _, f := range fl.List {
+			t := exprString(fset, f.Type)
+			n := len(f.Names)
+			if n == 0 {
+				n = 1
+			}
+			for i := 0; i < n; i++ {
+				types = append(types, t)
+			}
+		}
+	}
+	return types
+}
+
+func exprString(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, expr); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,252 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package gomerge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const existing = `package main
+
+import (
+	"context"
+
+	pb "github.com/x/helloworld"
+)
+
+type greeterImpl struct {
+	pb.UnimplementedGreeter
+	db   *DB // hand-written field
+}
+
+// SayHello says hello.
+func (s *greeterImpl) SayHello(
+	ctx context.Context,
+	req *pb.HelloRequest,
+) (*pb.HelloReply, error) {
+	// hand-written logic
+	rsp := &pb.HelloReply{Msg: "hello " + req.Name}
+	return rsp, nil
+}
+
+func (s *greeterImpl) SayBye(ctx context.Context, req *pb.ByeRequest) (*pb.ByeReply, error) {
+	return &pb.ByeReply{}, nil
+}
+
+func helper() {}
+`
+
+const generated = `package main
+
+import (
+	"context"
+	"io"
+
+	pb "github.com/x/helloworld"
+)
+
+type greeterImpl struct {
+	pb.UnimplementedGreeter
+}
+
+// SayHello says hello.
+func (s *greeterImpl) SayHello(
+	ctx context.Context,
+	req *pb.HelloRequestV2,
+) (*pb.HelloReply, error) {
+	rsp := &pb.HelloReply{}
+	return rsp, nil
+}
+
+// SayHi says hi.
+func (s *greeterImpl) SayHi(stream pb.Greeter_SayHiServer) error {
+	_, err := stream.Recv()
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+`
+
+const merged = `package main
+
+import (
+	"context"
+
+	pb "github.com/x/helloworld"
+	"io"
+)
+
+type greeterImpl struct {
+	pb.UnimplementedGreeter
+	db   *DB // hand-written field
+}
+
+// SayHello says hello.
+func (s *greeterImpl) SayHello(
+	ctx context.Context,
+	req *pb.HelloRequestV2,
+) (*pb.HelloReply, error) {
+	// hand-written logic
+	rsp := &pb.HelloReply{Msg: "hello " + req.Name}
+	return rsp, nil
+}
+
+func (s *greeterImpl) SayBye(ctx context.Context, req *pb.ByeRequest) (*pb.ByeReply, error) {
+	return &pb.ByeReply{}, nil
+}
+
+func helper() {}
+
+// SayHi says hi.
+func (s *greeterImpl) SayHi(stream pb.Greeter_SayHiServer) error {
+	_, err := stream.Recv()
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+`
+
+func TestMerge(t *testing.T) {
+	res, err := Merge([]byte(existing), []byte(generated))
+	require.Nil(t, err)
+	require.True(t, res.Changed())
+	require.Equal(t, []string{"greeterImpl.SayHi"}, res.Added)
+	require.Equal(t, []string{"greeterImpl.SayHello"}, res.Updated)
+	require.Equal(t, []string{"io"}, res.Imports)
+	require.Equal(t, merged, string(res.Src))
+
+	// Merging again changes nothing.
+	res, err = Merge(res.Src, []byte(generated))
+	require.Nil(t, err)
+	require.False(t, res.Changed())
+	require.Equal(t, merged, string(res.Src))
+}
+
+func TestMerge_RenameTypesInBody(t *testing.T) {
+	res, err := Merge([]byte(`package main
+
+import pb "github.com/x/helloworld"
+
+func (s *greeterImpl) SayHello(ctx context.Context, req *pb.HelloRequest) (*pb.HelloReply, error) {
+	var r pb.HelloRequest = *req
+	_ = r
+	return &pb.HelloReply{Msg: "hi"}, nil
+}
+`), []byte(`package main
+
+import (
+	"context"
+
+	pb "github.com/x/helloworld"
+)
+
+func (s *greeterImpl) SayHello(ctx context.Context, req *pb.Req) (*pb.Rsp, error) {
+	return &pb.Rsp{}, nil
+}
+`))
+	require.Nil(t, err)
+	require.Equal(t, `package main
+
+import pb "github.com/x/helloworld"
+
+import (
+	"context"
+)
+
+func (s *greeterImpl) SayHello(ctx context.Context, req *pb.Req) (*pb.Rsp, error) {
+	var r pb.Req = *req
+	_ = r
+	return &pb.Rsp{Msg: "hi"}, nil
+}
+`, string(res.Src))
+}
+
+func TestMerge_GroupedTypes(t *testing.T) {
+	res, err := Merge([]byte(`package main
+
+type greeterImpl struct {
+	db string // kept
+}
+`), []byte(`package main
+
+type (
+	greeterImpl struct{}
+	// helloImpl implements hello.
+	helloImpl struct {
+		name string
+	}
+	kind int // kind of hello
+)
+`))
+	require.Nil(t, err)
+	require.Equal(t, []string{"helloImpl", "kind"}, res.Added)
+	require.Equal(t, `package main
+
+type greeterImpl struct {
+	db string // kept
+}
+
+// helloImpl implements hello.
+type helloImpl struct {
+	name string
+}
+
+type kind int // kind of hello
+`, string(res.Src))
+
+	// Merging again changes nothing.
+	res, err = Merge(res.Src, []byte("package main\n\ntype (\n\thelloImpl struct{}\n\tkind int\n)\n"))
+	require.Nil(t, err)
+	require.False(t, res.Changed())
+}
+
+func TestMerge_InvalidSource(t *testing.T) {
+	_, err := Merge([]byte("package main\nfunc {"), []byte("package main\n"))
+	require.NotNil(t, err)
+	_, err = Merge([]byte("package main\n"), []byte("package main\nfunc {"))
+	require.NotNil(t, err)
+}
+
+func TestMark(t *testing.T) {
+	src := []byte(`package main
+
+// SayBye says bye.
+func (s *greeterImpl) SayBye() {}
+
+func (s *greeterImpl) SayHello() {}
+`)
+	marks := map[string]string{
+		"greeterImpl.SayBye": "TODO: RPC SayBye has been removed.",
+	}
+	out, marked, err := Mark(src, marks)
+	require.Nil(t, err)
+	require.Equal(t, []string{"greeterImpl.SayBye"}, marked)
+	require.Equal(t, `package main
+
+// SayBye says bye.
+// TODO: RPC SayBye has been removed.
+func (s *greeterImpl) SayBye() {}
+
+func (s *greeterImpl) SayHello() {}
+`, string(out))
+
+	again, marked, err := Mark(out, marks)
+	require.Nil(t, err)
+	require.Empty(t, marked)
+	require.Equal(t, string(out), string(again))
+
+	keys, err := FuncKeys(out)
+	require.Nil(t, err)
+	require.Equal(t, []string{"greeterImpl.SayBye", "greeterImpl.SayHello"}, keys)
+}
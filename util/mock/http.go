@@ -0,0 +1,310 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package mock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"google.golang.org/protobuf/types/descriptorpb"
+	trpcpb "trpc.group/trpc/trpc-protocol/pb/go/trpc"
+
+	"trpc.group/trpc-go/trpc-cmdline/descriptor"
+	"trpc.group/trpc-go/trpc-cmdline/util/protocol"
+)
+
+// route is a RESTful route of a method, whose path template is like /v1/{name=messages/*}/items/{id}:verb.
+type route struct {
+	m        *protocol.Method
+	api      *descriptor.RESTfulAPIContent
+	segments []string   // Literal segments, or * matching a segment, or ** matching the rest.
+	vars     []variable // Variables bound to the segments.
+	verb     string     // Verb following the last segment, including the leading colon.
+}
+
+// variable binds the segments [start, end) of a path to a field.
+type variable struct {
+	field      string
+	start, end int
+}
+
+// HTTPHandler returns the handler serving the RESTful routes of the methods, which are defined by the
+// trpc.api.http options. The request fields are bound from the path, the body and the query parameters,
+// and the response is encoded into JSON. Non-zero ret codes are responded with status 500 and a body
+// like {"code":10001,"message":"..."}.
+func (s *Server) HTTPHandler() (http.Handler, error) {
+	var routes []*route
+	for _, m := range s.methods {
+		for _, api := range m.RPC.RESTfulAPIInfo.ContentList {
+			r, err := newRoute(m, api)
+			if err != nil {
+				return nil, fmt.Errorf("route %s %s of %s: %w", api.Method, api.PathTmpl, m.Func, err)
+			}
+			routes = append(routes, r)
+		}
+	}
+	if len(routes) == 0 {
+		return nil, errors.New("no RESTful routes defined by the trpc.api.http options")
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		s.serveHTTP(routes, w, req)
+	}), nil
+}
+
+func (s *Server) serveHTTP(routes []*route, w http.ResponseWriter, req *http.Request) {
+	start := time.Now()
+	from := req.RemoteAddr
+	for _, r := range routes {
+		vars, ok := r.match(req.Method, req.URL.EscapedPath())
+		if !ok {
+			continue
+		}
+		in, err := r.decode(req, vars)
+		if err != nil {
+			rsp := &Response{Ret: int32(trpcpb.TrpcRetCode_TRPC_SERVER_DECODE_ERR), ErrorMsg: err.Error()}
+			s.logRequest("http", from, r.m, nil, rsp, start)
+			writeHTTPError(w, http.StatusBadRequest, rsp)
+			return
+		}
+		out, err := s.handle(r.m, in)
+		if err != nil {
+			out = &Response{Ret: int32(trpcpb.TrpcRetCode_TRPC_SERVER_ENCODE_ERR), ErrorMsg: err.Error()}
+		}
+		s.logRequest("http", from, r.m, in, out, start)
+		if out.Ret != 0 {
+			writeHTTPError(w, http.StatusInternalServerError, out)
+			return
+		}
+		b, err := r.encode(out.Body)
+		if err != nil {
+			writeHTTPError(w, http.StatusInternalServerError,
+				&Response{Ret: int32(trpcpb.TrpcRetCode_TRPC_SERVER_ENCODE_ERR), ErrorMsg: err.Error()})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(b)
+		return
+	}
+	s.logf("http", "%s %s %s route not found", from, req.Method, req.URL.Path)
+	http.NotFound(w, req)
+}
+
+func writeHTTPError(w http.ResponseWriter, status int, rsp *Response) {
+	b, _ := json.Marshal(map[string]interface{}{"code": rsp.Ret, "message": rsp.ErrorMsg})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(b)
+}
+
+func newRoute(m *protocol.Method, api *descriptor.RESTfulAPIContent) (*route, error) {
+	r := &route{m: m, api: api}
+	tmpl := api.PathTmpl
+	if !strings.HasPrefix(tmpl, "/") {
+		return nil, errors.New("path template should start with /")
+	}
+	if i := strings.LastIndex(tmpl, ":"); i > strings.LastIndex(tmpl, "/") && i > strings.LastIndex(tmpl, "}") {
+		tmpl, r.verb = tmpl[:i], tmpl[i:]
+	}
+	for tmpl = tmpl[1:]; tmpl != ""; {
+		if !strings.HasPrefix(tmpl, "{") {
+			var seg string
+			seg, tmpl, _ = strings.Cut(tmpl, "/")
+			r.segments = append(r.segments, seg)
+			continue
+		}
+		end := strings.Index(tmpl, "}")
+		if end < 0 {
+			return nil, errors.New("unclosed variable")
+		}
+		field, pattern, ok := strings.Cut(tmpl[1:end], "=")
+		if !ok {
+			pattern = "*"
+		}
+		if err := checkFieldPath(m.Input, field); err != nil {
+			return nil, err
+		}
+		v := variable{field: field, start: len(r.segments)}
+		r.segments = append(r.segments, strings.Split(pattern, "/")...)
+		v.end = len(r.segments)
+		r.vars = append(r.vars, v)
+		tmpl = strings.TrimPrefix(tmpl[end+1:], "/")
+	}
+	for i, seg := range r.segments {
+		if seg == "**" && i != len(r.segments)-1 {
+			return nil, errors.New("** should be the last segment")
+		}
+	}
+	return r, nil
+}
+
+// match matches the method and path of a request, and returns the values of the variables.
+func (r *route) match(method, path string) (map[string]string, bool) {
+	if method != r.api.Method || !strings.HasSuffix(path, r.verb) {
+		return nil, false
+	}
+	path = strings.TrimSuffix(path, r.verb)
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, seg := range r.segments {
+		switch {
+		case seg == "**":
+			parts = append(parts[:i], strings.Join(parts[i:], "/"))
+		case i >= len(parts):
+			return nil, false
+		case seg != "*" && seg != parts[i]:
+			return nil, false
+		}
+	}
+	if len(parts) != len(r.segments) {
+		return nil, false
+	}
+	vars := make(map[string]string, len(r.vars))
+	for _, v := range r.vars {
+		value, err := url.PathUnescape(strings.Join(parts[v.start:v.end], "/"))
+		if err != nil {
+			return nil, false
+		}
+		vars[v.field] = value
+	}
+	return vars, true
+}
+
+// decode decodes the request message from the body, the path variables and the query parameters.
+func (r *route) decode(req *http.Request, vars map[string]string) (*dynamic.Message, error) {
+	in, err := r.m.NewInput(nil)
+	if err != nil {
+		return nil, err
+	}
+	if r.api.RequestBody != "" {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("read body err: %w", err)
+		}
+		if r.api.RequestBody != "*" && len(body) != 0 {
+			body = []byte(fmt.Sprintf(`{%q:%s}`, r.api.RequestBody, body))
+		}
+		if len(body) != 0 {
+			if in, err = r.m.NewInput(body); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for field, value := range vars {
+		if err := setField(in, field, value); err != nil {
+			return nil, err
+		}
+	}
+	if r.api.RequestBody == "*" {
+		return in, nil
+	}
+	for field, values := range req.URL.Query() {
+		if checkFieldPath(r.m.Input, field) != nil {
+			continue // Unknown query parameters are ignored.
+		}
+		for _, value := range values {
+			if err := setField(in, field, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return in, nil
+}
+
+// encode encodes the response message, or its field specified by the response_body option, into JSON.
+func (r *route) encode(out *dynamic.Message) ([]byte, error) {
+	b, err := protocol.MarshalJSON(out)
+	if err != nil || r.api.ResponseBody == "" {
+		return b, err
+	}
+	obj := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return nil, err
+	}
+	return obj[r.api.ResponseBody], nil
+}
+
+// setField sets the field of the path joined by dots to the value parsed from s,
+// which is appended if the field is repeated.
+func setField(msg *dynamic.Message, path, s string) error {
+	name, rest, nested := strings.Cut(path, ".")
+	fd := msg.GetMessageDescriptor().FindFieldByName(name)
+	if fd == nil {
+		return fmt.Errorf("field %s not found", name)
+	}
+	if nested {
+		if fd.GetMessageType() == nil || fd.IsRepeated() {
+			return fmt.Errorf("field %s is not a singular message", name)
+		}
+		sub, ok := msg.GetField(fd).(*dynamic.Message)
+		if !ok || sub == nil {
+			sub = dynamic.NewMessage(fd.GetMessageType())
+		}
+		if err := setField(sub, rest, s); err != nil {
+			return err
+		}
+		return msg.TrySetField(fd, sub)
+	}
+	v, err := parseValue(fd, s)
+	if err != nil {
+		return fmt.Errorf("invalid value %q of field %s: %w", s, path, err)
+	}
+	if fd.IsRepeated() {
+		return msg.TryAddRepeatedField(fd, v)
+	}
+	return msg.TrySetField(fd, v)
+}
+
+func parseValue(fd *desc.FieldDescriptor, s string) (interface{}, error) {
+	switch fd.GetType() {
+	case descriptorpb.FieldDescriptorProto_TYPE_STRING:
+		return s, nil
+	case descriptorpb.FieldDescriptorProto_TYPE_BYTES:
+		return []byte(s), nil
+	case descriptorpb.FieldDescriptorProto_TYPE_BOOL:
+		return strconv.ParseBool(s)
+	case descriptorpb.FieldDescriptorProto_TYPE_INT32,
+		descriptorpb.FieldDescriptorProto_TYPE_SINT32,
+		descriptorpb.FieldDescriptorProto_TYPE_SFIXED32:
+		v, err := strconv.ParseInt(s, 10, 32)
+		return int32(v), err
+	case descriptorpb.FieldDescriptorProto_TYPE_INT64,
+		descriptorpb.FieldDescriptorProto_TYPE_SINT64,
+		descriptorpb.FieldDescriptorProto_TYPE_SFIXED64:
+		return strconv.ParseInt(s, 10, 64)
+	case descriptorpb.FieldDescriptorProto_TYPE_UINT32,
+		descriptorpb.FieldDescriptorProto_TYPE_FIXED32:
+		v, err := strconv.ParseUint(s, 10, 32)
+		return uint32(v), err
+	case descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+		descriptorpb.FieldDescriptorProto_TYPE_FIXED64:
+		return strconv.ParseUint(s, 10, 64)
+	case descriptorpb.FieldDescriptorProto_TYPE_FLOAT:
+		v, err := strconv.ParseFloat(s, 32)
+		return float32(v), err
+	case descriptorpb.FieldDescriptorProto_TYPE_DOUBLE:
+		return strconv.ParseFloat(s, 64)
+	case descriptorpb.FieldDescriptorProto_TYPE_ENUM:
+		if v := fd.GetEnumType().FindValueByName(s); v != nil {
+			return v.GetNumber(), nil
+		}
+		v, err := strconv.ParseInt(s, 10, 32)
+		return int32(v), err
+	default:
+		return nil, fmt.Errorf("unsupported type %s", fd.GetType())
+	}
+}
@@ -0,0 +1,96 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+// Package mock implements the mock server of the services in proto files, whose responses are
+// decided by rules or generated as samples.
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/jhump/protoreflect/dynamic"
+
+	"trpc.group/trpc-go/trpc-cmdline/util/protocol"
+	"trpc.group/trpc-go/trpc-cmdline/util/sample"
+)
+
+// Server is the mock server, which serves the tRPC protocol by ServeTRPC,
+// and the HTTP protocol of the RESTful routes by HTTPHandler.
+type Server struct {
+	methods []*protocol.Method
+	funcs   map[string]*protocol.Method // Methods by their names sent in requests.
+	rules   []*Rule
+
+	mu  sync.Mutex // Guards the writes of logs.
+	log io.Writer
+}
+
+// Response is the response of a request.
+type Response struct {
+	Ret      int32
+	ErrorMsg string
+	Body     *dynamic.Message // Nil if no response body, such as the errors without a rule response.
+}
+
+// NewServer creates a mock server of the methods, which responds by rules and logs every request into log.
+// The rules should be loaded by LoadRules with the same methods.
+func NewServer(methods []*protocol.Method, rules []*Rule, log io.Writer) *Server {
+	funcs := make(map[string]*protocol.Method, len(methods))
+	for _, m := range methods {
+		funcs[m.Func] = m
+	}
+	return &Server{methods: methods, funcs: funcs, rules: rules, log: log}
+}
+
+// handle responds to the request of the method by the first matched rule, or a sample response.
+func (s *Server) handle(m *protocol.Method, req *dynamic.Message) (*Response, error) {
+	b, err := protocol.MarshalJSON(req)
+	if err != nil {
+		return nil, fmt.Errorf("encode request into json err: %w", err)
+	}
+	obj := make(map[string]interface{})
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return nil, fmt.Errorf("decode request json err: %w", err)
+	}
+	for _, r := range s.rules {
+		if !r.matches(m, obj) {
+			continue
+		}
+		time.Sleep(r.Delay)
+		rsp := &Response{Ret: r.Ret, ErrorMsg: r.ErrorMsg, Body: r.response}
+		if rsp.Body == nil && rsp.Ret == 0 {
+			rsp.Body = sample.New(sample.DefaultSeed).Message(m.Output)
+		}
+		return rsp, nil
+	}
+	return &Response{Body: sample.New(sample.DefaultSeed).Message(m.Output)}, nil
+}
+
+// logf logs a line of the requests served by the transport, i.e. trpc or http.
+func (s *Server) logf(transport, format string, args ...interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintf(s.log, "%s [%s] %s\n",
+		time.Now().Format("2006-01-02 15:04:05.000"), transport, fmt.Sprintf(format, args...))
+}
+
+// logRequest logs the request of the method and its response.
+func (s *Server) logRequest(transport, from string, m *protocol.Method, req *dynamic.Message, rsp *Response,
+	start time.Time) {
+	var reqJSON []byte
+	if req != nil {
+		reqJSON, _ = req.MarshalJSON()
+	}
+	s.logf(transport, "%s %s ret: %d, error_msg: %q, cost: %s, request: %s",
+		from, m.Func, rsp.Ret, rsp.ErrorMsg, time.Since(start).Round(time.Microsecond), reqJSON)
+}
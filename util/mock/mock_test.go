@@ -0,0 +1,202 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package mock
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	trpcpb "trpc.group/trpc/trpc-protocol/pb/go/trpc"
+
+	"trpc.group/trpc-go/trpc-cmdline/parser"
+	"trpc.group/trpc-go/trpc-cmdline/util/protocol"
+)
+
+const testRules = `
+rules:
+  - method: UserService/GetUser
+    match:
+      name: error
+    ret: 10001
+    error_msg: mocked error
+  - method: /trpc.test.mock.UserService/GetUser
+    match:
+      id: 3
+      page.num: 2
+    response:
+      user:
+        name: page2
+        status: STATUS_OK
+    delay: 10ms
+`
+
+// syncBuffer is a buffer safe for concurrent use, which collects the logs.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func testMethods(t *testing.T) []*protocol.Method {
+	fd, err := parser.ParseProtoFile("mock.proto", []string{"testcase", "../../install/submodules/trpc-protocol"},
+		parser.WithAliasOn(true), parser.WithRPCOnly(true))
+	require.Nil(t, err)
+	methods, err := protocol.Methods(fd)
+	require.Nil(t, err)
+	return methods
+}
+
+func writeRules(t *testing.T, rules string) string {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	require.Nil(t, os.WriteFile(path, []byte(rules), 0644))
+	return path
+}
+
+func testServer(t *testing.T) (*Server, *syncBuffer) {
+	methods := testMethods(t)
+	rules, err := LoadRules(writeRules(t, testRules), methods)
+	require.Nil(t, err)
+	require.Len(t, rules, 2)
+	log := &syncBuffer{}
+	return NewServer(methods, rules, log), log
+}
+
+func TestLoadRules(t *testing.T) {
+	methods := testMethods(t)
+	for rules, want := range map[string]string{
+		"rules:\n  - method: UserService/Nope\n":                               "method UserService/Nope not found",
+		"rules:\n  - method: UserService/GetUser\n    match: {page.nope: 1}\n": "field page.nope not found",
+		"rules:\n  - method: UserService/GetUser\n    match: {name.x: 1}\n":    "field name is not a message",
+		"rules:\n  - method: UserService/GetUser\n    response: {nope: 1}\n":   "decode trpc.test.mock.GetUserRsp from json",
+		"rules:\n  - method: UserService/GetUser\n    nope: 1\n":               "field nope not found",
+	} {
+		_, err := LoadRules(writeRules(t, rules), methods)
+		require.ErrorContains(t, err, want, rules)
+	}
+}
+
+func TestServeTRPC(t *testing.T) {
+	s, log := testServer(t)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer ln.Close()
+	go s.ServeTRPC(ln)
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	require.Nil(t, err)
+	defer conn.Close()
+
+	m, err := protocol.LookupMethod(s.methods, "UserService/GetUser")
+	require.Nil(t, err)
+	call := func(fn string, contentType trpcpb.TrpcContentEncodeType, req string) (*trpcpb.ResponseProtocol, string) {
+		in, err := m.NewInput([]byte(req))
+		require.Nil(t, err)
+		body, err := in.Marshal()
+		require.Nil(t, err)
+		if contentType == trpcpb.TrpcContentEncodeType_TRPC_JSON_ENCODE {
+			body = []byte(req)
+		}
+		head := &trpcpb.RequestProtocol{RequestId: 1, Func: []byte(fn), ContentType: uint32(contentType)}
+		require.Nil(t, protocol.WriteRequest(conn, head, body))
+		rsp, rspBody, err := protocol.ReadResponse(conn)
+		require.Nil(t, err)
+		require.Equal(t, uint32(1), rsp.GetRequestId())
+		if rsp.GetRet() != 0 || contentType == trpcpb.TrpcContentEncodeType_TRPC_JSON_ENCODE {
+			return rsp, string(rspBody)
+		}
+		out, err := m.NewOutput(nil)
+		require.Nil(t, err)
+		require.Nil(t, out.Unmarshal(rspBody))
+		b, err := out.MarshalJSON()
+		require.Nil(t, err)
+		return rsp, string(b)
+	}
+
+	rsp, body := call(m.Func, trpcpb.TrpcContentEncodeType_TRPC_PROTO_ENCODE, `{"id":"3","page":{"num":2}}`)
+	require.Zero(t, rsp.GetRet())
+	require.JSONEq(t, `{"user":{"name":"page2","status":"STATUS_OK"}}`, body)
+
+	rsp, _ = call(m.Func, trpcpb.TrpcContentEncodeType_TRPC_PROTO_ENCODE, `{"name":"error"}`)
+	require.Equal(t, int32(10001), rsp.GetRet())
+	require.Equal(t, "mocked error", string(rsp.GetErrorMsg()))
+
+	rsp, body = call(m.Func, trpcpb.TrpcContentEncodeType_TRPC_JSON_ENCODE, `{"id":"3","page":{"num":1}}`)
+	require.Zero(t, rsp.GetRet())
+	require.Contains(t, body, `"user":{`)
+
+	rsp, _ = call("/trpc.test.mock.UserService/Nope", trpcpb.TrpcContentEncodeType_TRPC_PROTO_ENCODE, "")
+	require.Equal(t, int32(trpcpb.TrpcRetCode_TRPC_SERVER_NOFUNC_ERR), rsp.GetRet())
+
+	logs := strings.Split(strings.TrimSpace(log.String()), "\n")
+	require.Len(t, logs, 4)
+	require.Contains(t, logs[0], "[trpc]")
+	require.Contains(t, logs[0], `/trpc.test.mock.UserService/GetUser ret: 0`)
+	require.Contains(t, logs[0], `request: {"id":"3","page":{"num":2}}`)
+	require.Contains(t, logs[1], `ret: 10001, error_msg: "mocked error"`)
+	require.Contains(t, logs[3], "/trpc.test.mock.UserService/Nope ret: 12, func not found")
+}
+
+func TestHTTPHandler(t *testing.T) {
+	s, log := testServer(t)
+	handler, err := s.HTTPHandler()
+	require.Nil(t, err)
+	hs := httptest.NewServer(handler)
+	defer hs.Close()
+
+	do := func(method, path, body string) (int, string) {
+		req, err := http.NewRequest(method, hs.URL+path, strings.NewReader(body))
+		require.Nil(t, err)
+		rsp, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		defer rsp.Body.Close()
+		b, err := io.ReadAll(rsp.Body)
+		require.Nil(t, err)
+		return rsp.StatusCode, string(b)
+	}
+
+	status, body := do(http.MethodGet, "/v1/users/bob?id=3&page.num=2&tags=a&tags=b&unknown=1", "")
+	require.Equal(t, http.StatusOK, status)
+	require.Contains(t, body, `"name":"page2"`)
+	require.Contains(t, log.String(), `request: {"name":"bob","id":"3","page":{"num":2},"tags":["a","b"]}`)
+
+	status, body = do(http.MethodGet, "/v1/users/error", "")
+	require.Equal(t, http.StatusInternalServerError, status)
+	require.JSONEq(t, `{"code":10001,"message":"mocked error"}`, body)
+
+	status, body = do(http.MethodPost, "/v1/groups/g1/users:search", `{"num":2,"size":10}`)
+	require.Equal(t, http.StatusOK, status)
+	require.Contains(t, body, `"user":{`)
+	require.Contains(t, log.String(), `request: {"name":"groups/g1/users","page":{"num":2,"size":10}}`)
+
+	status, _ = do(http.MethodGet, "/v1/users/bob?id=x", "")
+	require.Equal(t, http.StatusBadRequest, status)
+	status, _ = do(http.MethodPost, "/v1/groups/g1/users", "")
+	require.Equal(t, http.StatusNotFound, status)
+	require.Contains(t, log.String(), "POST /v1/groups/g1/users route not found")
+}
@@ -0,0 +1,167 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"gopkg.in/yaml.v2"
+
+	"trpc.group/trpc-go/trpc-cmdline/util/protocol"
+)
+
+// Rule decides the response of the requests of a method, for example:
+//
+//	rules:
+//	  - method: helloworld.Greeter/SayHello
+//	    match:
+//	      msg: error
+//	    ret: 10001
+//	    error_msg: mocked error
+//	  - method: Greeter/SayHello
+//	    response:
+//	      msg: hello
+//	    delay: 100ms
+//
+// The rules are matched in order, and the first matched one is applied.
+type Rule struct {
+	// Method is the name of the method, which is specified the same as 'trpc call'.
+	Method string `yaml:"method"`
+	// Match are the fields of the request to match, the keys are the field names in the proto file,
+	// which are joined by dots for nested fields, and the values are compared by their string forms.
+	// Empty Match matches all the requests.
+	Match map[string]interface{} `yaml:"match,omitempty"`
+	// Response is the response in JSON form, a sample response is generated if it is empty and Ret is 0.
+	Response interface{} `yaml:"response,omitempty"`
+	// Ret is the ret code of the response.
+	Ret int32 `yaml:"ret,omitempty"`
+	// ErrorMsg is the error message of the response.
+	ErrorMsg string `yaml:"error_msg,omitempty"`
+	// Delay delays the response.
+	Delay time.Duration `yaml:"delay,omitempty"`
+
+	method   *protocol.Method
+	response *dynamic.Message
+}
+
+// LoadRules loads the rules from the yaml (or json) file, whose methods are looked up in methods.
+func LoadRules(path string, methods []*protocol.Method) ([]*Rule, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read rules file err: %w", err)
+	}
+	var file struct {
+		Rules []*Rule `yaml:"rules"`
+	}
+	if err := yaml.UnmarshalStrict(b, &file); err != nil {
+		return nil, fmt.Errorf("unmarshal rules file %s err: %w", path, err)
+	}
+	for i, r := range file.Rules {
+		if err := r.init(methods); err != nil {
+			return nil, fmt.Errorf("rule %d of %s: %w", i+1, path, err)
+		}
+	}
+	return file.Rules, nil
+}
+
+func (r *Rule) init(methods []*protocol.Method) error {
+	m, err := protocol.LookupMethod(methods, r.Method)
+	if err != nil {
+		return err
+	}
+	r.method = m
+	for path := range r.Match {
+		if err := checkFieldPath(m.Input, path); err != nil {
+			return fmt.Errorf("invalid match of %s: %w", m.Input.GetFullyQualifiedName(), err)
+		}
+	}
+	if r.Response == nil {
+		return nil
+	}
+	b, err := json.Marshal(jsonValue(r.Response))
+	if err != nil {
+		return fmt.Errorf("encode response into json err: %w", err)
+	}
+	if r.response, err = m.NewOutput(b); err != nil {
+		return err
+	}
+	return nil
+}
+
+// matches reports whether the request in the form of decoded JSON matches the rule.
+func (r *Rule) matches(m *protocol.Method, req map[string]interface{}) bool {
+	if r.method.Func != m.Func {
+		return false
+	}
+	for path, want := range r.Match {
+		got, ok := lookupJSON(req, path)
+		if !ok || fmt.Sprint(got) != fmt.Sprint(jsonValue(want)) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkFieldPath checks that the fields of the path joined by dots exist in md.
+func checkFieldPath(md *desc.MessageDescriptor, path string) error {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		if md == nil {
+			return fmt.Errorf("field %s is not a message", strings.Join(names[:i], "."))
+		}
+		fd := md.FindFieldByName(name)
+		if fd == nil {
+			return fmt.Errorf("field %s not found", strings.Join(names[:i+1], "."))
+		}
+		md = fd.GetMessageType()
+	}
+	return nil
+}
+
+// lookupJSON looks up the value of the path joined by dots in the decoded JSON object.
+func lookupJSON(obj map[string]interface{}, path string) (interface{}, bool) {
+	var v interface{} = obj
+	for _, name := range strings.Split(path, ".") {
+		o, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if v, ok = o[name]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// jsonValue converts the value decoded from yaml, whose maps are keyed by interface{}, to the one of JSON.
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		obj := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			obj[fmt.Sprint(k)] = jsonValue(val)
+		}
+		return obj
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+		for i, val := range v {
+			arr[i] = jsonValue(val)
+		}
+		return arr
+	default:
+		return v
+	}
+}
@@ -0,0 +1,121 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package mock
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/jhump/protoreflect/dynamic"
+	trpcpb "trpc.group/trpc/trpc-protocol/pb/go/trpc"
+
+	"trpc.group/trpc-go/trpc-cmdline/util/protocol"
+)
+
+// ServeTRPC serves the unary requests of the tRPC protocol accepted from ln, until ln is closed.
+// The bodies are encoded by protobuf or JSON, according to the content type of the requests.
+func (s *Server) ServeTRPC(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("accept connection err: %w", err)
+		}
+		go s.serveConn(conn)
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	from := conn.RemoteAddr().String()
+	for {
+		req, body, err := protocol.ReadRequest(conn)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				s.logf("trpc", "%s read request err: %v, only unary requests are supported", from, err)
+			}
+			return
+		}
+		rsp := &trpcpb.ResponseProtocol{
+			Version:     req.GetVersion(),
+			CallType:    req.GetCallType(),
+			RequestId:   req.GetRequestId(),
+			ContentType: req.GetContentType(),
+		}
+		rspBody, err := s.serveTRPC(from, req, body, rsp)
+		if err != nil {
+			rsp.Ret = int32(trpcpb.TrpcRetCode_TRPC_SERVER_ENCODE_ERR)
+			rsp.ErrorMsg = []byte(err.Error())
+		}
+		if req.GetCallType() == uint32(trpcpb.TrpcCallType_TRPC_ONEWAY_CALL) {
+			continue
+		}
+		if err := protocol.WriteResponse(conn, rsp, rspBody); err != nil {
+			s.logf("trpc", "%s write response err: %v", from, err)
+			return
+		}
+	}
+}
+
+// serveTRPC fills in the ret codes of rsp, and returns the encoded response body.
+func (s *Server) serveTRPC(from string, req *trpcpb.RequestProtocol, body []byte,
+	rsp *trpcpb.ResponseProtocol) ([]byte, error) {
+	start := time.Now()
+	m, ok := s.funcs[string(req.GetFunc())]
+	if !ok {
+		rsp.Ret = int32(trpcpb.TrpcRetCode_TRPC_SERVER_NOFUNC_ERR)
+		rsp.ErrorMsg = []byte(fmt.Sprintf("mock server: func %s not found", req.GetFunc()))
+		s.logf("trpc", "%s %s ret: %d, func not found", from, req.GetFunc(), rsp.Ret)
+		return nil, nil
+	}
+	in, err := decodeTRPC(m, req.GetContentType(), body)
+	if err != nil {
+		rsp.Ret = int32(trpcpb.TrpcRetCode_TRPC_SERVER_DECODE_ERR)
+		rsp.ErrorMsg = []byte(err.Error())
+		s.logRequest("trpc", from, m, nil, &Response{Ret: rsp.Ret, ErrorMsg: err.Error()}, start)
+		return nil, nil
+	}
+	out, err := s.handle(m, in)
+	if err != nil {
+		return nil, err
+	}
+	s.logRequest("trpc", from, m, in, out, start)
+	rsp.Ret, rsp.ErrorMsg = out.Ret, []byte(out.ErrorMsg)
+	if out.Body == nil {
+		return nil, nil
+	}
+	if req.GetContentType() == uint32(trpcpb.TrpcContentEncodeType_TRPC_JSON_ENCODE) {
+		return protocol.MarshalJSON(out.Body)
+	}
+	return out.Body.Marshal()
+}
+
+func decodeTRPC(m *protocol.Method, contentType uint32, body []byte) (*dynamic.Message, error) {
+	switch trpcpb.TrpcContentEncodeType(contentType) {
+	case trpcpb.TrpcContentEncodeType_TRPC_PROTO_ENCODE:
+		in, err := m.NewInput(nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := in.Unmarshal(body); err != nil {
+			return nil, fmt.Errorf("unmarshal request into %s err: %w", m.Input.GetFullyQualifiedName(), err)
+		}
+		return in, nil
+	case trpcpb.TrpcContentEncodeType_TRPC_JSON_ENCODE:
+		return m.NewInput(body)
+	default:
+		return nil, fmt.Errorf("mock server: unsupported content type %d", contentType)
+	}
+}
@@ -0,0 +1,197 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package pb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"google.golang.org/protobuf/cmd/protoc-gen-go/internal_gengo"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"trpc.group/trpc-go/trpc-cmdline/util/log"
+)
+
+// Compilers of the protobuf files.
+const (
+	// CompilerProtoc runs the installed protoc, which invokes the installed protoc-gen-* plugins.
+	CompilerProtoc = "protoc"
+	// CompilerBuiltin compiles the protobuf files in-process, no protoc is needed.
+	// protoc-gen-go is linked in, and the other plugins, such as protoc-gen-validate, are invoked directly.
+	CompilerBuiltin = "builtin"
+)
+
+// ValidCompiler checks if compiler is a known protobuf compiler.
+func ValidCompiler(compiler string) bool {
+	return compiler == CompilerProtoc || compiler == CompilerBuiltin
+}
+
+// compile does the same as protoc with the given args, but the protobuf files are parsed in-process,
+// and the CodeGeneratorRequest is passed to the plugin without protoc.
+func compile(args *protocArgs, protofile string, options options) error {
+	name, parameter, outputdir, err := parseProtocOut(args.argsGoOut)
+	if err != nil {
+		return err
+	}
+	files, err := loadFileDescriptorProtos(args, protofile, options)
+	if err != nil {
+		return err
+	}
+	req := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{protofile},
+		Parameter:      proto.String(parameter),
+		ProtoFile:      files,
+	}
+	log.Debug("compile %s in-process by protoc-gen-%s, parameter: %s, output: %s",
+		protofile, name, parameter, outputdir)
+
+	var resp *pluginpb.CodeGeneratorResponse
+	if name == "go" {
+		resp, err = runGoPlugin(req)
+	} else {
+		resp, err = runPlugin(name, req)
+	}
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("protoc-gen-%s: %s", name, resp.GetError())
+	}
+	return writeResponse(resp, outputdir)
+}
+
+// parseProtocOut splits the protoc out argument, such as `--go_out=paths=source_relative:out`,
+// into the plugin name, the parameter and the output directory.
+func parseProtocOut(arg string) (name, parameter, outputdir string, err error) {
+	name, out, ok := strings.Cut(strings.TrimPrefix(arg, "--"), "_out=")
+	if !ok {
+		return "", "", "", fmt.Errorf("invalid protoc out argument %s", arg)
+	}
+	parameter, outputdir, ok = strings.Cut(out, ":")
+	if !ok {
+		// No parameter.
+		return name, "", out, nil
+	}
+	return name, parameter, outputdir, nil
+}
+
+// loadFileDescriptorProtos returns protofile and all its dependencies in topological order.
+func loadFileDescriptorProtos(
+	args *protocArgs,
+	protofile string,
+	options options,
+) ([]*descriptorpb.FileDescriptorProto, error) {
+	if options.descriptorSetIn != "" {
+		b, err := os.ReadFile(options.descriptorSetIn)
+		if err != nil {
+			return nil, fmt.Errorf("read descriptor_set_in %s err: %w", options.descriptorSetIn, err)
+		}
+		set := &descriptorpb.FileDescriptorSet{}
+		if err := proto.Unmarshal(b, set); err != nil {
+			return nil, fmt.Errorf("proto unmarshal descriptor_set_in %s err: %w", options.descriptorSetIn, err)
+		}
+		return set.File, nil
+	}
+	importPaths := make([]string, 0, len(args.argsProtoPath))
+	for _, arg := range args.argsProtoPath {
+		importPaths = append(importPaths, strings.TrimPrefix(arg, "--proto_path="))
+	}
+	parser := protoparse.Parser{
+		ImportPaths:           importPaths,
+		IncludeSourceCodeInfo: true,
+	}
+	fds, err := parser.ParseFiles(protofile)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s from %v err: %w", protofile, importPaths, err)
+	}
+	var files []*descriptorpb.FileDescriptorProto
+	seen := make(map[string]bool)
+	var add func(fd *desc.FileDescriptor)
+	add = func(fd *desc.FileDescriptor) {
+		if seen[fd.GetName()] {
+			return
+		}
+		seen[fd.GetName()] = true
+		for _, dep := range fd.GetDependencies() {
+			add(dep)
+		}
+		files = append(files, fd.AsFileDescriptorProto())
+	}
+	add(fds[0])
+	return files, nil
+}
+
+// runGoPlugin runs the linked-in protoc-gen-go.
+func runGoPlugin(req *pluginpb.CodeGeneratorRequest) (*pluginpb.CodeGeneratorResponse, error) {
+	gen, err := protogen.Options{}.New(req)
+	if err != nil {
+		return nil, fmt.Errorf("protoc-gen-go: %w", err)
+	}
+	for _, f := range gen.Files {
+		if f.Generate {
+			internal_gengo.GenerateFile(gen, f)
+		}
+	}
+	gen.SupportedFeatures = internal_gengo.SupportedFeatures
+	return gen.Response(), nil
+}
+
+// runPlugin runs the installed protoc-gen-$name, which reads the request from stdin
+// and writes the response into stdout.
+func runPlugin(name string, req *pluginpb.CodeGeneratorRequest) (*pluginpb.CodeGeneratorResponse, error) {
+	exe := "protoc-gen-" + name
+	if _, err := exec.LookPath(exe); err != nil {
+		return nil, fmt.Errorf("%s not found, install it first: %w", exe, err)
+	}
+	in, err := proto.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("proto marshal request err: %w", err)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(exe)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("run %s err: %w, stderr: %s", exe, err, stderr.String())
+	}
+	resp := &pluginpb.CodeGeneratorResponse{}
+	if err := proto.Unmarshal(stdout.Bytes(), resp); err != nil {
+		return nil, fmt.Errorf("proto unmarshal response of %s err: %w", exe, err)
+	}
+	return resp, nil
+}
+
+// writeResponse writes the generated files into outputdir.
+func writeResponse(resp *pluginpb.CodeGeneratorResponse, outputdir string) error {
+	for _, f := range resp.File {
+		if f.GetInsertionPoint() != "" {
+			return errors.New("insertion points are not supported by the builtin compiler")
+		}
+		fp := filepath.Join(outputdir, filepath.FromSlash(f.GetName()))
+		if err := os.MkdirAll(filepath.Dir(fp), os.ModePerm); err != nil {
+			return fmt.Errorf("create directory for %s err: %w", fp, err)
+		}
+		if err := os.WriteFile(fp, []byte(f.GetContent()), 0644); err != nil {
+			return fmt.Errorf("write %s err: %w", fp, err)
+		}
+	}
+	return nil
+}
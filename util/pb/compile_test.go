@@ -0,0 +1,78 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package pb
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtoc_Builtin(t *testing.T) {
+	t.Run("helloworld", func(t *testing.T) {
+		outputdir := t.TempDir()
+		require.Nil(t, Protoc([]string{wd}, "helloworld.proto", "go", outputdir,
+			WithPb2ImportPath(map[string]string{"helloworld.proto": "trpc.group/examples/helloworld"}),
+			WithCompiler(CompilerBuiltin)))
+		b, err := os.ReadFile(filepath.Join(outputdir, "helloworld.pb.go"))
+		require.Nil(t, err)
+		require.Contains(t, string(b), "package helloworld")
+		require.Contains(t, string(b), "type HelloReq struct")
+	})
+	t.Run("virtual path, imports and proto3 optional", func(t *testing.T) {
+		dir := t.TempDir()
+		require.Nil(t, os.MkdirAll(filepath.Join(dir, "foo"), os.ModePerm))
+		require.Nil(t, os.WriteFile(filepath.Join(dir, "foo", "foo.proto"), []byte(`syntax = "proto3";
+package foo;
+option go_package = "trpc.group/examples/foo";
+import "google/protobuf/timestamp.proto";
+message Foo {
+  optional string name = 1;
+  google.protobuf.Timestamp at = 2;
+}
+`), 0644))
+		outputdir := filepath.Join(dir, "out", "foo")
+		require.Nil(t, Protoc([]string{dir, filepath.Join(dir, "foo")}, "foo/foo.proto", "go", outputdir, WithCompiler(CompilerBuiltin)))
+		b, err := os.ReadFile(filepath.Join(outputdir, "foo.pb.go"))
+		require.Nil(t, err)
+		require.Contains(t, string(b), "Name *string")
+		require.Contains(t, string(b), "timestamppb")
+	})
+	t.Run("plugin not found", func(t *testing.T) {
+		err := Protoc([]string{wd}, "helloworld.proto", "go", t.TempDir(),
+			WithPkg2ImportPath(map[string]string{"validate": "validate"}),
+			WithValidateEnabled(true),
+			WithCompiler(CompilerBuiltin))
+		if _, lookErr := exec.LookPath("protoc-gen-validate"); lookErr == nil {
+			t.Skip("protoc-gen-validate is installed")
+		}
+		require.ErrorContains(t, err, "protoc-gen-validate not found")
+	})
+}
+
+func Test_parseProtocOut(t *testing.T) {
+	name, parameter, out, err := parseProtocOut("--go_out=paths=source_relative,Ma.proto=x/a:/tmp/out")
+	require.Nil(t, err)
+	require.Equal(t, "go", name)
+	require.Equal(t, "paths=source_relative,Ma.proto=x/a", parameter)
+	require.Equal(t, "/tmp/out", out)
+
+	name, parameter, out, err = parseProtocOut("--cpp_out=/tmp/out")
+	require.Nil(t, err)
+	require.Equal(t, "cpp", name)
+	require.Empty(t, parameter)
+	require.Equal(t, "/tmp/out", out)
+
+	_, _, _, err = parseProtocOut("--go")
+	require.NotNil(t, err)
+}
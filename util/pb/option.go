@@ -15,6 +15,7 @@ type options struct {
 	pb2ImportPath     map[string]string
 	pkg2ImportPath    map[string]string
 	descriptorSetIn   string
+	compiler          string
 }
 
 // Option is used to store the content of the relevant options.
@@ -58,3 +59,10 @@ func WithDescriptorSetIn(descriptorSetIn string) Option {
 		o.descriptorSetIn = descriptorSetIn
 	}
 }
+
+// WithCompiler specifies the protobuf compiler, CompilerProtoc or CompilerBuiltin, defaults to CompilerProtoc.
+func WithCompiler(compiler string) Option {
+	return func(o *options) {
+		o.compiler = compiler
+	}
+}
@@ -92,6 +92,11 @@ func IsInternalProto(fname string) bool {
 // In the worst case, if none of these suit your needs, you can always generate into a temporary directory and copy the
 // file into the desired location. Neither the paths nor module flags have any effect on the contents of the generated
 // files.
+//
+// ------------------------------------------------------------------------------------------------------------------
+//
+// If WithCompiler(CompilerBuiltin) is given, protoc is not needed: the pb files are parsed in-process
+// and the plugins are invoked with the same arguments as the ones passed to protoc.
 func Protoc(protodirs []string, protofile, lang, outputdir string, opts ...Option) error {
 	options := options{
 		pb2ImportPath:  make(map[string]string),
@@ -111,6 +116,10 @@ func Protoc(protodirs []string, protofile, lang, outputdir string, opts ...Optio
 		defer movePbGoFile(protocArgs.argsGoOut, importPath, protocArgs.baseDir, protofile)
 	}
 
+	if options.compiler == CompilerBuiltin {
+		return compile(protocArgs, protofile, options)
+	}
+
 	var args []string
 	args = append(args, protocArgs.argsProtoPath...)
 	args = append(args, protocArgs.argsGoOut)
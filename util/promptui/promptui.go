@@ -35,10 +35,15 @@ func WithMinLength(minlen int) promptui.ValidateFunc {
 
 // Read reads from standard input, specifies the label, the validateFunc, and returns the string.
 func Read(label string, validateFunc promptui.ValidateFunc) (string, error) {
+	return ReadWithDefault(label, "", validateFunc)
+}
+
+// ReadWithDefault is the same as Read, except that the input is prefilled with def.
+func ReadWithDefault(label, def string, validateFunc promptui.ValidateFunc) (string, error) {
 
 	prompt := promptui.Prompt{
 		Label:       label,
-		Default:     "",
+		Default:     def,
 		AllowEdit:   true,
 		Validate:    validateFunc,
 		Mask:        0,
@@ -55,6 +60,47 @@ func Read(label string, validateFunc promptui.ValidateFunc) (string, error) {
 	return result, nil
 }
 
+// Select lets the user choose one of the items, the cursor starts at def, and returns the chosen item.
+func Select(label string, items []string, def string) (string, error) {
+	var pos int
+	for i, item := range items {
+		if item == def {
+			pos = i
+		}
+	}
+	prompt := promptui.Select{
+		Label:     label,
+		Items:     items,
+		CursorPos: pos,
+	}
+	_, result, err := prompt.Run()
+	if err != nil {
+		fmt.Printf("Prompt failed %v\n", err)
+		return "", err
+	}
+	return result, nil
+}
+
+// Confirm asks a yes or no question, def is the answer when the user just presses enter.
+func Confirm(label string, def bool) (bool, error) {
+	prompt := promptui.Prompt{
+		Label:     label,
+		IsConfirm: true,
+	}
+	if def {
+		prompt.Default = "y"
+	}
+	_, err := prompt.Run()
+	if errors.Is(err, promptui.ErrAbort) {
+		return false, nil
+	}
+	if err != nil {
+		fmt.Printf("Prompt failed %v\n", err)
+		return false, err
+	}
+	return true, nil
+}
+
 // ConfirmQuit reads user input to determine whether to quit.
 func ConfirmQuit() bool {
 	c, err := Read("press q to quit", func(s string) error {
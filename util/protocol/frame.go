@@ -0,0 +1,158 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+// Package protocol implements the tRPC protocol, which is used by the commands
+// talking to tRPC services without generated code.
+package protocol
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"google.golang.org/protobuf/proto"
+	trpcpb "trpc.group/trpc/trpc-protocol/pb/go/trpc"
+)
+
+// HeadSize is the size of the fixed frame head.
+const HeadSize = 16
+
+// MaxFrameSize is the max size of a frame, larger frames are rejected.
+const MaxFrameSize = 10 * 1024 * 1024
+
+// FrameHead is the fixed head of a frame, which is laid out as:
+//
+//	magic(2) | data frame type(1) | stream frame type(1) | total size(4) |
+//	header size(2) | stream id(4) | protocol version(1) | reserved(1)
+type FrameHead struct {
+	FrameType       trpcpb.TrpcDataFrameType   // Unary or stream frame.
+	StreamFrameType trpcpb.TrpcStreamFrameType // Init, data, feedback or close frame of a stream.
+	StreamID        uint32
+}
+
+// Frame is a frame of the tRPC protocol, whose Header is the encoded RequestProtocol or ResponseProtocol
+// for unary frames, or the stream meta for stream frames.
+type Frame struct {
+	FrameHead
+	Header []byte
+	Body   []byte
+}
+
+// ReadFrame reads a frame from r.
+func ReadFrame(r io.Reader) (*Frame, error) {
+	head := make([]byte, HeadSize)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return nil, err
+	}
+	if magic := binary.BigEndian.Uint16(head); magic != uint16(trpcpb.TrpcMagic_TRPC_MAGIC_VALUE) {
+		return nil, fmt.Errorf("invalid magic %#x of frame", magic)
+	}
+	total := binary.BigEndian.Uint32(head[4:])
+	size := int(binary.BigEndian.Uint16(head[8:]))
+	if total < HeadSize || total > MaxFrameSize || HeadSize+size > int(total) {
+		return nil, fmt.Errorf("invalid frame size, total size %d, header size %d", total, size)
+	}
+	buf := make([]byte, total-HeadSize)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, fmt.Errorf("read frame err: %w", err)
+	}
+	return &Frame{
+		FrameHead: FrameHead{
+			FrameType:       trpcpb.TrpcDataFrameType(head[2]),
+			StreamFrameType: trpcpb.TrpcStreamFrameType(head[3]),
+			StreamID:        binary.BigEndian.Uint32(head[10:]),
+		},
+		Header: buf[:size],
+		Body:   buf[size:],
+	}, nil
+}
+
+// WriteFrame writes the frame into w.
+func WriteFrame(w io.Writer, f *Frame) error {
+	if len(f.Header) > math.MaxUint16 {
+		return fmt.Errorf("frame header size %d exceeds %d", len(f.Header), math.MaxUint16)
+	}
+	total := HeadSize + len(f.Header) + len(f.Body)
+	if total > MaxFrameSize {
+		return fmt.Errorf("frame size %d exceeds %d", total, MaxFrameSize)
+	}
+	buf := make([]byte, HeadSize, total)
+	binary.BigEndian.PutUint16(buf, uint16(trpcpb.TrpcMagic_TRPC_MAGIC_VALUE))
+	buf[2] = byte(f.FrameType)
+	buf[3] = byte(f.StreamFrameType)
+	binary.BigEndian.PutUint32(buf[4:], uint32(total))
+	binary.BigEndian.PutUint16(buf[8:], uint16(len(f.Header)))
+	binary.BigEndian.PutUint32(buf[10:], f.StreamID)
+	buf = append(append(buf, f.Header...), f.Body...)
+	_, err := w.Write(buf)
+	return err
+}
+
+// WriteRequest writes a unary request frame, whose header is req and body is body.
+func WriteRequest(w io.Writer, req *trpcpb.RequestProtocol, body []byte) error {
+	return writeUnary(w, req, body)
+}
+
+// ReadRequest reads a unary request frame, the attachment is dropped.
+func ReadRequest(r io.Reader) (*trpcpb.RequestProtocol, []byte, error) {
+	req := &trpcpb.RequestProtocol{}
+	body, err := readUnary(r, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err = dropAttachment(body, req.GetAttachmentSize())
+	return req, body, err
+}
+
+// WriteResponse writes a unary response frame, whose header is rsp and body is body.
+func WriteResponse(w io.Writer, rsp *trpcpb.ResponseProtocol, body []byte) error {
+	return writeUnary(w, rsp, body)
+}
+
+// ReadResponse reads a unary response frame, the attachment is dropped.
+func ReadResponse(r io.Reader) (*trpcpb.ResponseProtocol, []byte, error) {
+	rsp := &trpcpb.ResponseProtocol{}
+	body, err := readUnary(r, rsp)
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err = dropAttachment(body, rsp.GetAttachmentSize())
+	return rsp, body, err
+}
+
+func writeUnary(w io.Writer, header proto.Message, body []byte) error {
+	b, err := proto.Marshal(header)
+	if err != nil {
+		return fmt.Errorf("marshal frame header err: %w", err)
+	}
+	return WriteFrame(w, &Frame{Header: b, Body: body})
+}
+
+func readUnary(r io.Reader, header proto.Message) ([]byte, error) {
+	f, err := ReadFrame(r)
+	if err != nil {
+		return nil, err
+	}
+	if f.FrameType != trpcpb.TrpcDataFrameType_TRPC_UNARY_FRAME {
+		return nil, fmt.Errorf("unexpected frame type %s, expect a unary frame", f.FrameType)
+	}
+	if err := proto.Unmarshal(f.Header, header); err != nil {
+		return nil, fmt.Errorf("unmarshal frame header err: %w", err)
+	}
+	return f.Body, nil
+}
+
+func dropAttachment(body []byte, size uint32) ([]byte, error) {
+	if int(size) > len(body) {
+		return nil, errors.New("attachment size exceeds the frame")
+	}
+	return body[:len(body)-int(size)], nil
+}
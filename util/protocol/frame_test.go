@@ -0,0 +1,75 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package protocol
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	trpcpb "trpc.group/trpc/trpc-protocol/pb/go/trpc"
+)
+
+func TestFrame(t *testing.T) {
+	buf := &bytes.Buffer{}
+	f := &Frame{
+		FrameHead: FrameHead{
+			FrameType:       trpcpb.TrpcDataFrameType_TRPC_STREAM_FRAME,
+			StreamFrameType: trpcpb.TrpcStreamFrameType_TRPC_STREAM_FRAME_DATA,
+			StreamID:        100,
+		},
+		Header: []byte("header"),
+		Body:   []byte("body"),
+	}
+	require.Nil(t, WriteFrame(buf, f))
+	require.Equal(t, []byte{0x09, 0x30, 1, 2, 0, 0, 0, 26, 0, 6, 0, 0, 0, 100, 0, 0}, buf.Bytes()[:HeadSize])
+	got, err := ReadFrame(buf)
+	require.Nil(t, err)
+	require.Equal(t, f, got)
+
+	_, err = ReadFrame(bytes.NewReader(make([]byte, HeadSize)))
+	require.ErrorContains(t, err, "invalid magic")
+}
+
+func TestRequestResponse(t *testing.T) {
+	buf := &bytes.Buffer{}
+	req := &trpcpb.RequestProtocol{Func: []byte("/a.B/C"), AttachmentSize: 3}
+	require.Nil(t, WriteRequest(buf, req, []byte("bodyatt")))
+	gotReq, body, err := ReadRequest(buf)
+	require.Nil(t, err)
+	require.Equal(t, "/a.B/C", string(gotReq.GetFunc()))
+	require.Equal(t, "body", string(body))
+
+	rsp := &trpcpb.ResponseProtocol{Ret: 12, ErrorMsg: []byte("no func")}
+	require.Nil(t, WriteResponse(buf, rsp, nil))
+	gotRsp, body, err := ReadResponse(buf)
+	require.Nil(t, err)
+	require.Equal(t, int32(12), gotRsp.GetRet())
+	require.Empty(t, body)
+
+	require.Nil(t, WriteFrame(buf, &Frame{FrameHead: FrameHead{FrameType: trpcpb.TrpcDataFrameType_TRPC_STREAM_FRAME}}))
+	_, _, err = ReadResponse(buf)
+	require.ErrorContains(t, err, "expect a unary frame")
+}
+
+func TestParseTarget(t *testing.T) {
+	for target, want := range map[string]string{
+		"ip://127.0.0.1:8000":  "127.0.0.1:8000",
+		"tcp://127.0.0.1:8000": "127.0.0.1:8000",
+		"127.0.0.1:8000":       "127.0.0.1:8000",
+	} {
+		network, address, err := ParseTarget(target)
+		require.Nil(t, err)
+		require.Equal(t, "tcp", network)
+		require.Equal(t, want, address)
+	}
+	_, _, err := ParseTarget("polaris://trpc.app.server.Greeter")
+	require.ErrorContains(t, err, "unsupported target")
+}
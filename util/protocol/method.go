@@ -0,0 +1,165 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+
+	"trpc.group/trpc-go/trpc-cmdline/descriptor"
+)
+
+// Method is an RPC of a parsed proto file, whose messages are built dynamically instead of by generated code.
+type Method struct {
+	RPC     *descriptor.RPCDescriptor
+	Service string // Fully qualified service name, e.g. trpc.app.server.Greeter, which is the default callee.
+	Func    string // RPC name sent in the request, e.g. /trpc.app.server.Greeter/SayHello.
+	Input   *desc.MessageDescriptor
+	Output  *desc.MessageDescriptor
+}
+
+// Methods returns the RPCs of all services in fd, the aliases of an RPC are returned as separate methods.
+func Methods(fd *descriptor.FileDescriptor) ([]*Method, error) {
+	pfd, ok := fd.FD.(*descriptor.ProtoFileDescriptor)
+	if !ok {
+		return nil, errors.New("only protobuf is supported")
+	}
+	var methods []*Method
+	for _, sd := range fd.Services {
+		service := sd.Name
+		if pkg := pfd.FD.GetPackage(); pkg != "" {
+			service = pkg + "." + sd.Name
+		}
+		psd := pfd.FD.FindService(service)
+		if psd == nil {
+			return nil, fmt.Errorf("service %s not found", service)
+		}
+		for _, rpc := range sd.RPC {
+			pmd := psd.FindMethodByName(rpc.Name)
+			if pmd == nil {
+				return nil, fmt.Errorf("method %s of service %s not found", rpc.Name, service)
+			}
+			// MethodRPCx holds the other names of the RPC, i.e. the original one and the aliases.
+			for _, rpcx := range append([]*descriptor.RPCDescriptor{rpc}, sd.MethodRPCx[rpc.Name]...) {
+				methods = append(methods, &Method{
+					RPC:     rpcx,
+					Service: service,
+					Func:    rpcx.FullyQualifiedCmd,
+					Input:   pmd.GetInputType(),
+					Output:  pmd.GetOutputType(),
+				})
+			}
+		}
+	}
+	return methods, nil
+}
+
+// FindMethod finds the RPC in fd by its name sent in requests, e.g. /trpc.app.server.Greeter/SayHello,
+// the leading slash can be omitted, and Greeter/SayHello is accepted if there is no ambiguity.
+func FindMethod(fd *descriptor.FileDescriptor, name string) (*Method, error) {
+	methods, err := Methods(fd)
+	if err != nil {
+		return nil, err
+	}
+	return LookupMethod(methods, name)
+}
+
+// LookupMethod looks up the RPC in methods by its name, which is specified the same as FindMethod.
+func LookupMethod(methods []*Method, name string) (*Method, error) {
+	name = strings.TrimPrefix(name, "/")
+	var found []*Method
+	for _, m := range methods {
+		if strings.TrimPrefix(m.Func, "/") == name {
+			return m, nil
+		}
+		short := m.Service[strings.LastIndex(m.Service, ".")+1:] + "/" + m.RPC.Name
+		if short == name {
+			found = append(found, m)
+		}
+	}
+	switch len(found) {
+	case 0:
+		var names []string
+		for _, m := range methods {
+			names = append(names, m.Func)
+		}
+		return nil, fmt.Errorf("method %s not found, available methods: %s", name, strings.Join(names, ", "))
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf("method %s is ambiguous, specify it by the fully qualified name", name)
+	}
+}
+
+// NewInput returns a new request message of the method, which is decoded from the JSON data if not empty.
+func (m *Method) NewInput(data []byte) (*dynamic.Message, error) {
+	msg := dynamic.NewMessage(m.Input)
+	if len(data) == 0 {
+		return msg, nil
+	}
+	if err := msg.UnmarshalJSONPB(&jsonpb.Unmarshaler{}, data); err != nil {
+		return nil, fmt.Errorf("decode %s from json err: %w", m.Input.GetFullyQualifiedName(), err)
+	}
+	return msg, nil
+}
+
+// NewOutput returns a new response message of the method, which is decoded from the JSON data if not empty.
+func (m *Method) NewOutput(data []byte) (*dynamic.Message, error) {
+	msg := dynamic.NewMessage(m.Output)
+	if len(data) == 0 {
+		return msg, nil
+	}
+	if err := msg.UnmarshalJSONPB(&jsonpb.Unmarshaler{}, data); err != nil {
+		return nil, fmt.Errorf("decode %s from json err: %w", m.Output.GetFullyQualifiedName(), err)
+	}
+	return msg, nil
+}
+
+// MarshalJSON encodes the message into JSON, the fields keep their names in the proto file
+// and the ones with default values are emitted.
+func MarshalJSON(msg *dynamic.Message) ([]byte, error) {
+	return msg.MarshalJSONPB(&jsonpb.Marshaler{OrigName: true, EmitDefaults: true})
+}
+
+// ParseTransInfo parses the metadata in the form of key=value into the trans_info of frames.
+func ParseTransInfo(kvs []string) (map[string][]byte, error) {
+	if len(kvs) == 0 {
+		return nil, nil
+	}
+	transInfo := make(map[string][]byte, len(kvs))
+	for _, kv := range kvs {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid meta %q, expect key=value", kv)
+		}
+		transInfo[k] = []byte(v)
+	}
+	return transInfo, nil
+}
+
+// ParseTarget parses the target address, such as ip://127.0.0.1:8000 or 127.0.0.1:8000,
+// into the network and address to dial.
+func ParseTarget(target string) (network, address string, err error) {
+	scheme, addr, ok := strings.Cut(target, "://")
+	if !ok {
+		return "tcp", target, nil
+	}
+	switch scheme {
+	case "ip", "tcp":
+		return "tcp", addr, nil
+	default:
+		return "", "", fmt.Errorf("unsupported target %s, expect ip://host:port or host:port", target)
+	}
+}
@@ -0,0 +1,233 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	trpcpb "trpc.group/trpc/trpc-protocol/pb/go/trpc"
+)
+
+// DefaultWindowSize is the default initial window size of the stream flow control, the same as tRPC-Go.
+const DefaultWindowSize = 65535
+
+// StreamError is the error closing a stream with non-zero ret codes, which is sent by the peer.
+type StreamError struct {
+	Ret     int32
+	FuncRet int32
+	Msg     string
+}
+
+// Error implements the error interface.
+func (e *StreamError) Error() string {
+	return fmt.Sprintf("stream closed with ret: %d, func_ret: %d, msg: %s", e.Ret, e.FuncRet, e.Msg)
+}
+
+// ClientStream is the client side of a stream of the tRPC stream frame protocol:
+//
+//	client                       server
+//	INIT(request meta)    ->
+//	                      <-     INIT(response meta)
+//	DATA...               <->    DATA...
+//	FEEDBACK...           <->    FEEDBACK...
+//	CLOSE                 ->
+//	                      <-     CLOSE(ret)
+//
+// The sender of DATA frames consumes its window by the size of the bodies, and blocks when the window is used up,
+// until the receiver sends a FEEDBACK frame after consuming a quarter of the window.
+type ClientStream struct {
+	rw io.ReadWriter
+	id uint32
+
+	wmu sync.Mutex // Guards the writes of frames.
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	window int64 // Window to send DATA frames.
+	err    error // Error breaking the stream, set by the receiving loop.
+
+	frames     chan *Frame // DATA and CLOSE frames received.
+	recvWindow uint32      // Window advertised to the server.
+	consumed   uint32      // Size of the DATA frames received since the last FEEDBACK frame.
+	closed     bool        // Whether the CLOSE frame is received.
+}
+
+// NewClientStream initializes stream id on rw by meta, whose window size is advertised to the server.
+// The server response of the INIT frame is returned as well.
+func NewClientStream(
+	rw io.ReadWriter,
+	id uint32,
+	meta *trpcpb.TrpcStreamInitRequestMeta,
+	window uint32,
+) (*ClientStream, *trpcpb.TrpcStreamInitResponseMeta, error) {
+	if window == 0 {
+		window = DefaultWindowSize
+	}
+	s := &ClientStream{rw: rw, id: id, frames: make(chan *Frame, 64), recvWindow: window}
+	s.cond = sync.NewCond(&s.mu)
+	init := &trpcpb.TrpcStreamInitMeta{
+		RequestMeta:    meta,
+		InitWindowSize: window,
+		ContentType:    uint32(trpcpb.TrpcContentEncodeType_TRPC_PROTO_ENCODE),
+	}
+	if err := s.write(trpcpb.TrpcStreamFrameType_TRPC_STREAM_FRAME_INIT, init, nil); err != nil {
+		return nil, nil, fmt.Errorf("send init frame err: %w", err)
+	}
+	f, err := ReadFrame(rw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("receive init frame err: %w", err)
+	}
+	if f.FrameType != trpcpb.TrpcDataFrameType_TRPC_STREAM_FRAME ||
+		f.StreamFrameType != trpcpb.TrpcStreamFrameType_TRPC_STREAM_FRAME_INIT {
+		return nil, nil, fmt.Errorf("unexpected %s frame, expect the init frame", f.StreamFrameType)
+	}
+	rsp := &trpcpb.TrpcStreamInitMeta{}
+	if err := proto.Unmarshal(f.Header, rsp); err != nil {
+		return nil, nil, fmt.Errorf("unmarshal init frame err: %w", err)
+	}
+	if ret := rsp.GetResponseMeta().GetRet(); ret != 0 {
+		return nil, rsp.GetResponseMeta(), &StreamError{Ret: ret, Msg: string(rsp.GetResponseMeta().GetErrorMsg())}
+	}
+	s.window = int64(rsp.GetInitWindowSize())
+	if s.window == 0 {
+		s.window = DefaultWindowSize
+	}
+	go s.loop()
+	return s, rsp.GetResponseMeta(), nil
+}
+
+// Send sends body as a DATA frame, which blocks until the window is available.
+func (s *ClientStream) Send(body []byte) error {
+	s.mu.Lock()
+	for s.window <= 0 && s.err == nil {
+		s.cond.Wait()
+	}
+	if err := s.err; err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	s.window -= int64(len(body))
+	s.mu.Unlock()
+	return s.write(trpcpb.TrpcStreamFrameType_TRPC_STREAM_FRAME_DATA, nil, body)
+}
+
+// CloseSend tells the server that no more DATA frames will be sent.
+func (s *ClientStream) CloseSend() error {
+	meta := &trpcpb.TrpcStreamCloseMeta{CloseType: int32(trpcpb.TrpcStreamCloseType_TRPC_STREAM_CLOSE)}
+	return s.write(trpcpb.TrpcStreamFrameType_TRPC_STREAM_FRAME_CLOSE, meta, nil)
+}
+
+// Recv receives the body of the next DATA frame.
+// io.EOF is returned when the server closes the stream normally, and *StreamError with non-zero ret codes.
+func (s *ClientStream) Recv() ([]byte, error) {
+	if s.closed {
+		return nil, io.EOF
+	}
+	f, ok := <-s.frames
+	if !ok {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return nil, s.err
+	}
+	if f.StreamFrameType == trpcpb.TrpcStreamFrameType_TRPC_STREAM_FRAME_CLOSE {
+		s.closed = true
+		meta := &trpcpb.TrpcStreamCloseMeta{}
+		if err := proto.Unmarshal(f.Header, meta); err != nil {
+			return nil, fmt.Errorf("unmarshal close frame err: %w", err)
+		}
+		if meta.GetRet() != 0 || meta.GetFuncRet() != 0 {
+			return nil, &StreamError{Ret: meta.GetRet(), FuncRet: meta.GetFuncRet(), Msg: string(meta.GetMsg())}
+		}
+		return nil, io.EOF
+	}
+	s.consumed += uint32(len(f.Body))
+	if s.consumed >= s.recvWindow/4 {
+		// The feedback is best effort, the server may have closed the stream already,
+		// and a broken connection is reported by the receiving loop anyway.
+		feedback := &trpcpb.TrpcStreamFeedBackMeta{WindowSizeIncrement: s.consumed}
+		_ = s.write(trpcpb.TrpcStreamFrameType_TRPC_STREAM_FRAME_FEEDBACK, feedback, nil)
+		s.consumed = 0
+	}
+	return f.Body, nil
+}
+
+// loop receives the frames, the FEEDBACK frames enlarge the window and the others are passed to Recv.
+func (s *ClientStream) loop() {
+	defer close(s.frames)
+	for {
+		f, err := ReadFrame(s.rw)
+		if err == nil && (f.FrameType != trpcpb.TrpcDataFrameType_TRPC_STREAM_FRAME || f.StreamID != s.id) {
+			err = fmt.Errorf("unexpected frame of type %s and stream id %d", f.FrameType, f.StreamID)
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			s.fail(fmt.Errorf("receive frame err: %w", err))
+			return
+		}
+		switch f.StreamFrameType {
+		case trpcpb.TrpcStreamFrameType_TRPC_STREAM_FRAME_FEEDBACK:
+			meta := &trpcpb.TrpcStreamFeedBackMeta{}
+			if err := proto.Unmarshal(f.Header, meta); err != nil {
+				s.fail(fmt.Errorf("unmarshal feedback frame err: %w", err))
+				return
+			}
+			s.mu.Lock()
+			s.window += int64(meta.GetWindowSizeIncrement())
+			s.cond.Broadcast()
+			s.mu.Unlock()
+		case trpcpb.TrpcStreamFrameType_TRPC_STREAM_FRAME_DATA:
+			s.frames <- f
+		case trpcpb.TrpcStreamFrameType_TRPC_STREAM_FRAME_CLOSE:
+			s.frames <- f
+			s.fail(errors.New("stream is closed by the server"))
+			return
+		default:
+			s.fail(fmt.Errorf("unexpected %s frame", f.StreamFrameType))
+			return
+		}
+	}
+}
+
+func (s *ClientStream) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+	s.cond.Broadcast()
+}
+
+func (s *ClientStream) write(typ trpcpb.TrpcStreamFrameType, meta proto.Message, body []byte) error {
+	var header []byte
+	if meta != nil {
+		b, err := proto.Marshal(meta)
+		if err != nil {
+			return fmt.Errorf("marshal %s meta err: %w", typ, err)
+		}
+		header = b
+	}
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	return WriteFrame(s.rw, &Frame{
+		FrameHead: FrameHead{
+			FrameType:       trpcpb.TrpcDataFrameType_TRPC_STREAM_FRAME,
+			StreamFrameType: typ,
+			StreamID:        s.id,
+		},
+		Header: header,
+		Body:   body,
+	})
+}
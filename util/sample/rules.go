@@ -0,0 +1,155 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package sample
+
+import (
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"google.golang.org/protobuf/proto"
+)
+
+// rulesNumber is the field number of the rules extension of FieldOptions in validate.proto.
+const rulesNumber = 1071
+
+// rulesPackages are the packages of validate.proto, which share the same FieldRules.
+var rulesPackages = map[string]bool{
+	"validate":         true,
+	"trpc.validate":    true,
+	"trpc.v2.validate": true,
+}
+
+// rules are the validation rules of a field, i.e. a FieldRules message or the nested rules of it.
+// The zero value means no rules.
+type rules struct {
+	msg *dynamic.Message
+}
+
+// fieldRules returns the rules of the field defined by the (validate.rules) option.
+func (g *Generator) fieldRules(fd *desc.FieldDescriptor) rules {
+	opts := fd.GetFieldOptions()
+	if opts == nil {
+		return rules{}
+	}
+	ext := g.rulesExtension(fd.GetFile())
+	if ext == nil {
+		return rules{}
+	}
+	b, err := proto.Marshal(opts)
+	if err != nil {
+		return rules{}
+	}
+	er := &dynamic.ExtensionRegistry{}
+	if err := er.AddExtension(ext); err != nil {
+		return rules{}
+	}
+	msg := dynamic.NewMessageWithExtensionRegistry(ext.GetOwner(), er)
+	if err := msg.Unmarshal(b); err != nil || !msg.HasField(ext) {
+		return rules{}
+	}
+	r, _ := msg.GetField(ext).(*dynamic.Message)
+	return rules{msg: r}
+}
+
+// rulesExtension returns the rules extension imported by the file, or nil if validate.proto is not imported.
+func (g *Generator) rulesExtension(fd *desc.FileDescriptor) *desc.FieldDescriptor {
+	if ext, ok := g.extensions[fd]; ok {
+		return ext
+	}
+	var ext *desc.FieldDescriptor
+	if rulesPackages[fd.GetPackage()] {
+		for _, e := range fd.GetExtensions() {
+			if e.GetNumber() == rulesNumber && e.GetOwner().GetFullyQualifiedName() == "google.protobuf.FieldOptions" {
+				ext = e
+			}
+		}
+	}
+	for _, dep := range fd.GetDependencies() {
+		if ext != nil {
+			break
+		}
+		ext = g.rulesExtension(dep)
+	}
+	g.extensions[fd] = ext
+	return ext
+}
+
+// has reports whether the rule is set.
+func (r rules) has(name string) bool {
+	return r.msg != nil && r.msg.GetMessageDescriptor().FindFieldByName(name) != nil && r.msg.HasFieldName(name)
+}
+
+// sub returns the nested rules, such as the string rules of FieldRules.
+func (r rules) sub(name string) rules {
+	if !r.has(name) {
+		return rules{}
+	}
+	msg, _ := r.msg.GetFieldByName(name).(*dynamic.Message)
+	return rules{msg: msg}
+}
+
+// flag returns the bool rule.
+func (r rules) flag(name string) bool {
+	if !r.has(name) {
+		return false
+	}
+	b, _ := r.msg.GetFieldByName(name).(bool)
+	return b
+}
+
+// str returns the string rule.
+func (r rules) str(name string) (string, bool) {
+	if !r.has(name) {
+		return "", false
+	}
+	switch v := r.msg.GetFieldByName(name).(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	default:
+		return "", false
+	}
+}
+
+// num returns the numeric rule.
+func (r rules) num(name string) (float64, bool) {
+	if !r.has(name) {
+		return 0, false
+	}
+	return toFloat(r.msg.GetFieldByName(name))
+}
+
+// list returns the repeated rule, such as in and not_in.
+func (r rules) list(name string) []interface{} {
+	if !r.has(name) {
+		return nil
+	}
+	l, _ := r.msg.GetFieldByName(name).([]interface{})
+	return l
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,138 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+// Package sample generates sample messages of protobuf, whose fields are filled with realistic values
+// guessed by their names and types, within the constraints of the validate.proto options.
+package sample
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+
+	"trpc.group/trpc-go/trpc-cmdline/descriptor"
+)
+
+const (
+	// DefaultSeed is the default seed of the generator, the same seed generates the same samples.
+	DefaultSeed = 1
+	// maxDepth is the max depth of the nested messages filled, which stops at recursive messages.
+	maxDepth = 3
+)
+
+// Generator generates sample messages, which is not safe for concurrent use.
+type Generator struct {
+	rand       *rand.Rand
+	extensions map[*desc.FileDescriptor]*desc.FieldDescriptor // Validation rules extensions of files.
+}
+
+// New creates a generator with the seed.
+func New(seed int64) *Generator {
+	return &Generator{
+		rand:       rand.New(rand.NewSource(seed)),
+		extensions: make(map[*desc.FileDescriptor]*desc.FieldDescriptor),
+	}
+}
+
+// Message generates a sample of the message. Fields of nested, repeated and map types are filled
+// with one or two elements, a random choice of each oneof is filled, and the well-known types are
+// filled with values of their JSON forms.
+func (g *Generator) Message(md *desc.MessageDescriptor) *dynamic.Message {
+	return g.message(md, 0)
+}
+
+// JSON generates a sample of the message in JSON, whose fields keep their names in the proto file.
+// The JSON is indented by indent if it is not empty.
+func (g *Generator) JSON(md *desc.MessageDescriptor, indent string) ([]byte, error) {
+	b, err := g.Message(md).MarshalJSONPB(&jsonpb.Marshaler{OrigName: true})
+	if err != nil {
+		return nil, fmt.Errorf("encode sample of %s into json err: %w", md.GetFullyQualifiedName(), err)
+	}
+	if indent == "" {
+		return b, nil
+	}
+	// The indent of jsonpb is broken for the well-known types, so indent it afterwards.
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, b, "", indent); err != nil {
+		return nil, fmt.Errorf("indent json err: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// FindMessage finds the message in the file or its dependencies by the fully qualified name,
+// or the name relative to its package if there is no ambiguity.
+func FindMessage(fd *desc.FileDescriptor, name string) (*desc.MessageDescriptor, error) {
+	name = strings.TrimPrefix(name, ".")
+	var found []*desc.MessageDescriptor
+	for _, md := range allMessages(fd, make(map[string]bool)) {
+		if md.GetFullyQualifiedName() == name {
+			return md, nil
+		}
+		if strings.TrimPrefix(md.GetFullyQualifiedName(), md.GetFile().GetPackage()+".") == name {
+			found = append(found, md)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("message %s not found", name)
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf("message %s is ambiguous, specify it by the fully qualified name", name)
+	}
+}
+
+// allMessages returns the messages in the file and its dependencies, including the nested ones.
+func allMessages(fd *desc.FileDescriptor, visited map[string]bool) []*desc.MessageDescriptor {
+	if visited[fd.GetName()] {
+		return nil
+	}
+	visited[fd.GetName()] = true
+	var mds []*desc.MessageDescriptor
+	var walk func([]*desc.MessageDescriptor)
+	walk = func(nested []*desc.MessageDescriptor) {
+		for _, md := range nested {
+			if md.IsMapEntry() {
+				continue
+			}
+			mds = append(mds, md)
+			walk(md.GetNestedMessageTypes())
+		}
+	}
+	walk(fd.GetMessageTypes())
+	for _, dep := range fd.GetDependencies() {
+		mds = append(mds, allMessages(dep, visited)...)
+	}
+	return mds
+}
+
+// TemplateFunc is the template function generating a sample of the message type in fd in compact JSON,
+// the type is the one of descriptor.RPCDescriptor, such as helloworld.HelloRequest.
+// Empty string is returned if fd is not parsed from protobuf.
+func TemplateFunc(typ string, fd *descriptor.FileDescriptor) (string, error) {
+	pfd, ok := fd.FD.(*descriptor.ProtoFileDescriptor)
+	if !ok {
+		return "", nil
+	}
+	md, err := FindMessage(pfd.FD, typ[strings.LastIndex(typ, "/")+1:])
+	if err != nil {
+		return "", err
+	}
+	b, err := New(DefaultSeed).JSON(md, "")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,128 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package sample
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-cmdline/descriptor"
+)
+
+func testFile(t *testing.T, name string) *desc.FileDescriptor {
+	p := protoparse.Parser{ImportPaths: []string{"testcase", "../../install", "../../install/submodules/trpc-protocol"}}
+	fds, err := p.ParseFiles(name)
+	require.Nil(t, err)
+	return fds[0]
+}
+
+// sampleJSON generates the sample of the message, and decodes it for checking.
+func sampleJSON(t *testing.T, fd *desc.FileDescriptor, name string, seed int64) (string, map[string]interface{}) {
+	md, err := FindMessage(fd, name)
+	require.Nil(t, err)
+	b, err := New(seed).JSON(md, "")
+	require.Nil(t, err)
+	obj := make(map[string]interface{})
+	require.Nil(t, json.Unmarshal(b, &obj))
+	return string(b), obj
+}
+
+func TestGenerator_Message(t *testing.T) {
+	fd := testFile(t, "sample.proto")
+	s, obj := sampleJSON(t, fd, "User", DefaultSeed)
+	again, _ := sampleJSON(t, fd, "trpc.test.sample.User", DefaultSeed)
+	require.Equal(t, s, again, "the same seed generates the same sample")
+	other, _ := sampleJSON(t, fd, "User", DefaultSeed+1)
+	require.NotEqual(t, s, other)
+
+	require.Regexp(t, `^[0-9]+$`, obj["id"])
+	require.Contains(t, names, obj["name"])
+	require.Regexp(t, `^[a-z]+@example\.com$`, obj["email"])
+	require.Contains(t, []string{"STATUS_OK", "STATUS_BANNED"}, obj["status"])
+	require.NotEmpty(t, obj["tags"])
+	require.Len(t, obj["scores"], 1)
+	require.True(t, (obj["phone"] == nil) != (obj["website"] == nil), "only one choice of the oneof is filled")
+	require.Regexp(t, `^2023-`, obj["created_at"])
+	require.IsType(t, "", obj["nick"])
+	require.IsType(t, map[string]interface{}{}, obj["extra"])
+	require.NotContains(t, obj, "detail")
+	require.Equal(t, "Shenzhen", obj["address"].(map[string]interface{})["city"])
+	// The recursive messages stop at the max depth.
+	require.Equal(t, maxDepth-1, strings.Count(s, `"friend"`))
+}
+
+func TestGenerator_Rules(t *testing.T) {
+	fd, envoy := testFile(t, "sample.proto"), testFile(t, "envoy.proto")
+	for seed := int64(0); seed < 20; seed++ {
+		_, obj := sampleJSON(t, fd, "Validated", seed)
+		require.Regexp(t, `^C-.{6}$`, obj["code"])
+		require.Greater(t, obj["level"], 100.0)
+		require.LessOrEqual(t, obj["level"], 105.0)
+		require.GreaterOrEqual(t, obj["ratio"], 0.5)
+		require.Less(t, obj["ratio"], 1.0)
+		require.Equal(t, "STATUS_BANNED", obj["status"])
+		require.Len(t, obj["ids"], 3)
+		for _, id := range obj["ids"].([]interface{}) {
+			require.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-a[0-9a-f]{3}-[0-9a-f]{12}$`), id)
+		}
+		require.Contains(t, []string{"a", "b"}, obj["kind"])
+		require.Equal(t, 7.0, obj["count"])
+		// The ranges wider than int64 are sampled without overflow.
+		big, err := strconv.ParseInt(obj["big"].(string), 10, 64)
+		require.Nil(t, err)
+		require.Greater(t, big, int64(0))
+		_, err = strconv.ParseUint(obj["huge"].(string), 10, 64)
+		require.Nil(t, err)
+
+		// The rules of validate/validate.proto are the same.
+		_, obj = sampleJSON(t, envoy, "EnvoyValidated", seed)
+		require.Regexp(t, `@example\.com$`, obj["addr"])
+		require.Equal(t, "1000", obj["num"])
+		require.Len(t, obj["data"], 8) // 4 bytes in base64.
+		labels := obj["labels"].(map[string]interface{})
+		require.Len(t, labels, 2)
+		for k := range labels {
+			require.LessOrEqual(t, len(k), 3)
+		}
+	}
+}
+
+func TestFindMessage(t *testing.T) {
+	fd := testFile(t, "sample.proto")
+	md, err := FindMessage(fd, "User.Address")
+	require.Nil(t, err)
+	require.Equal(t, "trpc.test.sample.User.Address", md.GetFullyQualifiedName())
+	md, err = FindMessage(fd, "google.protobuf.Timestamp")
+	require.Nil(t, err)
+	require.Equal(t, "google.protobuf.Timestamp", md.GetFullyQualifiedName())
+	_, err = FindMessage(fd, "Nope")
+	require.ErrorContains(t, err, "message Nope not found")
+}
+
+func TestTemplateFunc(t *testing.T) {
+	s, err := TemplateFunc("trpc.test.sample.Validated", &descriptor.FileDescriptor{
+		FD: &descriptor.ProtoFileDescriptor{FD: testFile(t, "sample.proto")},
+	})
+	require.Nil(t, err)
+	require.True(t, json.Valid([]byte(s)))
+	require.NotContains(t, s, "\n")
+	require.Contains(t, s, `"status":"STATUS_BANNED"`)
+
+	s, err = TemplateFunc("trpc.test.sample.Validated", &descriptor.FileDescriptor{FD: &descriptor.FbsFileDescriptor{}})
+	require.Nil(t, err)
+	require.Empty(t, s)
+}
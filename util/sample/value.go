@@ -0,0 +1,439 @@
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+
+package sample
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+var (
+	// baseTime is the base of the sample times, which keeps the samples deterministic.
+	baseTime = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	names = []string{"Alice", "Bob", "Carol", "Dave", "Eve", "Frank", "Grace", "Heidi"}
+	words = []string{"apple", "banana", "cherry", "delta", "echo", "falcon", "galaxy", "harbor", "island", "jungle"}
+)
+
+func (g *Generator) message(md *desc.MessageDescriptor, depth int) *dynamic.Message {
+	msg := dynamic.NewMessage(md)
+	if g.wellKnown(msg, "", rules{}) {
+		return msg
+	}
+	chosen := make(map[*desc.OneOfDescriptor]*desc.FieldDescriptor)
+	for _, oneof := range md.GetOneOfs() {
+		choices := oneof.GetChoices()
+		chosen[oneof] = choices[g.rand.Intn(len(choices))]
+	}
+	for _, fd := range md.GetFields() {
+		if oneof := fd.GetOneOf(); oneof != nil && chosen[oneof] != fd {
+			continue
+		}
+		r := g.fieldRules(fd)
+		if md := fd.GetMessageType(); md != nil && !fd.IsMap() && !isWellKnown(md) &&
+			depth+1 >= maxDepth && !r.sub("message").flag("required") {
+			continue
+		}
+		g.setField(msg, fd, r, depth)
+	}
+	return msg
+}
+
+func (g *Generator) setField(msg *dynamic.Message, fd *desc.FieldDescriptor, r rules, depth int) {
+	name := fd.GetName()
+	switch {
+	case fd.IsMap():
+		mr := r.sub("map")
+		n := g.count(mr, "min_pairs", "max_pairs", 1)
+		keyFd, valueFd := fd.GetMapKeyType(), fd.GetMapValueType()
+		m := make(map[interface{}]interface{}, n)
+		// The keys may duplicate, so try more times.
+		for i := 0; len(m) < n && i < n*10; i++ {
+			key := g.value(keyFd, name, mr.sub("keys"), depth)
+			m[key] = g.value(valueFd, name, mr.sub("values"), depth)
+		}
+		if md := valueFd.GetMessageType(); len(m) != 0 && (md == nil || isWellKnown(md) || depth+1 < maxDepth) {
+			_ = msg.TrySetField(fd, m)
+		}
+	case fd.IsRepeated():
+		rr := r.sub("repeated")
+		n := g.count(rr, "min_items", "max_items", 1+g.rand.Intn(2))
+		for i := 0; i < n; i++ {
+			if v := g.value(fd, name, rr.sub("items"), depth); v != nil {
+				_ = msg.TryAddRepeatedField(fd, v)
+			}
+		}
+	default:
+		if v := g.value(fd, name, r, depth); v != nil {
+			_ = msg.TrySetField(fd, v)
+		}
+	}
+}
+
+// count returns the number of elements within the min and max rules, or n if there are no rules.
+func (g *Generator) count(r rules, minName, maxName string, n int) int {
+	min, hasMin := r.num(minName)
+	max, hasMax := r.num(maxName)
+	switch {
+	case hasMin && int(min) > n:
+		return int(min)
+	case hasMax && int(max) < n:
+		return int(max)
+	default:
+		return n
+	}
+}
+
+// value returns a single value of the field, regardless of whether it is repeated, which is guessed by name.
+func (g *Generator) value(fd *desc.FieldDescriptor, name string, r rules, depth int) interface{} {
+	switch fd.GetType() {
+	case descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, descriptorpb.FieldDescriptorProto_TYPE_GROUP:
+		msg := dynamic.NewMessage(fd.GetMessageType())
+		if g.wellKnown(msg, name, r) {
+			return msg
+		}
+		if fd.GetMessageType().GetFullyQualifiedName() == "google.protobuf.Any" {
+			return nil // Any can not be encoded into JSON without a resolvable type.
+		}
+		return g.message(fd.GetMessageType(), depth+1)
+	case descriptorpb.FieldDescriptorProto_TYPE_ENUM:
+		return g.enum(fd.GetEnumType(), r.sub("enum"))
+	case descriptorpb.FieldDescriptorProto_TYPE_STRING:
+		return g.string(name, r.sub("string"))
+	case descriptorpb.FieldDescriptorProto_TYPE_BYTES:
+		return []byte(g.string(name, r.sub("bytes")))
+	case descriptorpb.FieldDescriptorProto_TYPE_BOOL:
+		if r.sub("bool").has("const") {
+			return r.sub("bool").flag("const")
+		}
+		return g.rand.Intn(2) == 1
+	default:
+		kind := strings.ToLower(strings.TrimPrefix(fd.GetType().String(), "TYPE_"))
+		return g.number(fd.GetType(), name, r.sub(kind))
+	}
+}
+
+func isWellKnown(md *desc.MessageDescriptor) bool {
+	return md.GetFile().GetPackage() == "google.protobuf"
+}
+
+// wellKnown fills the message if it is of a well-known type, and reports whether it is.
+func (g *Generator) wellKnown(msg *dynamic.Message, name string, r rules) bool {
+	md := msg.GetMessageDescriptor()
+	switch md.GetFullyQualifiedName() {
+	case "google.protobuf.Timestamp":
+		msg.SetFieldByName("seconds", baseTime.Unix()+g.rand.Int63n(365*24*3600))
+	case "google.protobuf.Duration":
+		msg.SetFieldByName("seconds", 1+g.rand.Int63n(3600))
+	case "google.protobuf.Empty":
+	case "google.protobuf.FieldMask":
+		msg.SetFieldByName("paths", []interface{}{"name"})
+	case "google.protobuf.Value":
+		msg.SetFieldByName("string_value", g.word())
+	case "google.protobuf.ListValue":
+		value := dynamic.NewMessage(md.FindFieldByName("values").GetMessageType())
+		value.SetFieldByName("string_value", g.word())
+		msg.SetFieldByName("values", []interface{}{value})
+	case "google.protobuf.Struct":
+		fd := md.FindFieldByName("fields")
+		value := dynamic.NewMessage(fd.GetMapValueType().GetMessageType())
+		value.SetFieldByName("string_value", g.word())
+		msg.SetField(fd, map[interface{}]interface{}{"key": value})
+	case "google.protobuf.DoubleValue", "google.protobuf.FloatValue",
+		"google.protobuf.Int64Value", "google.protobuf.UInt64Value",
+		"google.protobuf.Int32Value", "google.protobuf.UInt32Value",
+		"google.protobuf.BoolValue", "google.protobuf.StringValue", "google.protobuf.BytesValue":
+		// The rules of the wrappers apply to the wrapped values.
+		fd := md.FindFieldByName("value")
+		if v := g.value(fd, name, r, 0); v != nil {
+			msg.SetField(fd, v)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func (g *Generator) enum(ed *desc.EnumDescriptor, r rules) interface{} {
+	if v, ok := r.num("const"); ok {
+		return int32(v)
+	}
+	if in := r.list("in"); len(in) != 0 {
+		return in[g.rand.Intn(len(in))]
+	}
+	notIn := make(map[int32]bool)
+	for _, v := range r.list("not_in") {
+		notIn[v.(int32)] = true
+	}
+	var candidates []int32
+	for _, v := range ed.GetValues() {
+		// The zero value usually means unspecified, which is not a realistic sample.
+		if v.GetNumber() != 0 && !notIn[v.GetNumber()] {
+			candidates = append(candidates, v.GetNumber())
+		}
+	}
+	if len(candidates) == 0 {
+		return ed.GetValues()[0].GetNumber()
+	}
+	return candidates[g.rand.Intn(len(candidates))]
+}
+
+// number returns a number of the type, within the range of the rules, such as gt and lte.
+func (g *Generator) number(typ descriptorpb.FieldDescriptorProto_Type, name string, r rules) interface{} {
+	isFloat := typ == descriptorpb.FieldDescriptorProto_TYPE_FLOAT || typ == descriptorpb.FieldDescriptorProto_TYPE_DOUBLE
+	if v, ok := r.num("const"); ok {
+		return convertNumber(typ, v)
+	}
+	if in := r.list("in"); len(in) != 0 {
+		return in[g.rand.Intn(len(in))]
+	}
+	lo, hi := numberRange(name, isFloat)
+	step := 1.0
+	if isFloat {
+		step = 0.01
+	}
+	hasLo, hasHi := false, false
+	if v, ok := r.num("gte"); ok {
+		lo, hasLo = v, true
+	} else if v, ok := r.num("gt"); ok {
+		lo, hasLo = v+step, true
+	}
+	if v, ok := r.num("lte"); ok {
+		hi, hasHi = v, true
+	} else if v, ok := r.num("lt"); ok {
+		hi, hasHi = v-step, true
+	}
+	switch {
+	case hasLo && !hasHi && hi < lo:
+		hi = lo + 100
+	case hasHi && !hasLo && lo > hi:
+		lo = hi - 100
+	case hi < lo:
+		hi = lo // The rules exclude a range, so take the edge.
+	}
+	notIn := make(map[float64]bool)
+	for _, v := range r.list("not_in") {
+		f, _ := toFloat(v)
+		notIn[f] = true
+	}
+	var v float64
+	for i := 0; i < 10; i++ {
+		if isFloat {
+			v = math.Round((lo+g.rand.Float64()*(hi-lo))*100) / 100
+		} else {
+			// Ranges wider than int64, such as lte of the max uint64, are clamped, or Int63n panics.
+			n := int64(math.MaxInt64 - 1)
+			if span := hi - lo; span < float64(n) {
+				n = int64(span)
+			}
+			v = lo + float64(g.rand.Int63n(n+1))
+		}
+		if !notIn[v] {
+			break
+		}
+	}
+	return convertNumber(typ, v)
+}
+
+// numberRange guesses the range of the number by its name.
+func numberRange(name string, isFloat bool) (float64, float64) {
+	name = strings.ToLower(name)
+	switch {
+	case name == "id" || strings.HasSuffix(name, "_id"):
+		return 100000, 999999
+	case name == "age":
+		return 18, 60
+	case name == "page" || name == "page_num" || name == "page_no":
+		return 1, 1
+	case strings.HasSuffix(name, "size") || strings.HasSuffix(name, "limit"):
+		return 10, 20
+	case name == "port":
+		return 8000, 9000
+	case name == "year":
+		return 2000, 2030
+	case strings.Contains(name, "time") || strings.HasSuffix(name, "_at") || name == "ts":
+		start := float64(baseTime.Unix())
+		return start, start + 365*24*3600
+	case isFloat && (strings.Contains(name, "price") || strings.Contains(name, "amount")):
+		return 1, 1000
+	default:
+		return 1, 100
+	}
+}
+
+func convertNumber(typ descriptorpb.FieldDescriptorProto_Type, v float64) interface{} {
+	switch typ {
+	case descriptorpb.FieldDescriptorProto_TYPE_INT32,
+		descriptorpb.FieldDescriptorProto_TYPE_SINT32,
+		descriptorpb.FieldDescriptorProto_TYPE_SFIXED32:
+		return int32(v)
+	case descriptorpb.FieldDescriptorProto_TYPE_INT64,
+		descriptorpb.FieldDescriptorProto_TYPE_SINT64,
+		descriptorpb.FieldDescriptorProto_TYPE_SFIXED64:
+		// float64(math.MaxInt64) rounds up to 2^63, which overflows int64.
+		if v >= math.MaxInt64 {
+			return int64(math.MaxInt64)
+		}
+		return int64(v)
+	case descriptorpb.FieldDescriptorProto_TYPE_UINT32,
+		descriptorpb.FieldDescriptorProto_TYPE_FIXED32:
+		return uint32(math.Max(v, 0))
+	case descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+		descriptorpb.FieldDescriptorProto_TYPE_FIXED64:
+		if v >= math.MaxUint64 {
+			return uint64(math.MaxUint64)
+		}
+		return uint64(math.Max(v, 0))
+	case descriptorpb.FieldDescriptorProto_TYPE_FLOAT:
+		return float32(v)
+	default:
+		return v
+	}
+}
+
+// string returns a string guessed by name, which satisfies the string or bytes rules.
+// The pattern rule is not supported.
+func (g *Generator) string(name string, r rules) string {
+	if v, ok := r.str("const"); ok {
+		return v
+	}
+	if in := r.list("in"); len(in) != 0 {
+		switch v := in[g.rand.Intn(len(in))].(type) {
+		case []byte:
+			return string(v)
+		default:
+			return fmt.Sprint(v)
+		}
+	}
+	switch {
+	case r.flag("email"):
+		return g.email()
+	case r.flag("hostname"):
+		return "api.example.com"
+	case r.flag("ip"), r.flag("ipv4"), r.flag("address"):
+		return g.ip()
+	case r.flag("ipv6"):
+		return fmt.Sprintf("2001:db8::%x", g.rand.Intn(0xffff))
+	case r.flag("uri"), r.flag("uri_ref"):
+		return "https://example.com/" + g.word()
+	case r.flag("uuid"):
+		return g.uuid()
+	}
+	prefix, _ := r.str("prefix")
+	suffix, _ := r.str("suffix")
+	contains, _ := r.str("contains")
+	if strings.Contains(prefix+suffix, contains) {
+		contains = ""
+	}
+	body := g.stringByName(name)
+	fixed := utf8.RuneCountInString(prefix + contains + suffix)
+	min, max := -1.0, -1.0
+	if v, ok := r.num("len"); ok {
+		min, max = v, v
+	}
+	if v, ok := r.num("min_len"); ok {
+		min = v
+	}
+	if v, ok := r.num("max_len"); ok {
+		max = v
+	}
+	if v, ok := r.num("min_bytes"); ok && v > min {
+		min = v
+	}
+	if v, ok := r.num("max_bytes"); ok && (max < 0 || v < max) {
+		max = v
+	}
+	runes := []rune(body)
+	if max >= 0 && len(runes)+fixed > int(max) {
+		runes = runes[:int(math.Max(max-float64(fixed), 0))]
+	}
+	for min >= 0 && len(runes)+fixed < int(min) {
+		runes = append(runes, rune('a'+g.rand.Intn(26)))
+	}
+	return prefix + string(runes) + contains + suffix
+}
+
+// stringByName guesses a realistic string by the name of the field.
+func (g *Generator) stringByName(name string) string {
+	name = strings.ToLower(name)
+	has := func(subs ...string) bool {
+		for _, sub := range subs {
+			if strings.Contains(name, sub) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case has("email", "mail"):
+		return g.email()
+	case has("phone", "mobile"):
+		return fmt.Sprintf("138%08d", g.rand.Intn(100000000))
+	case has("url", "uri", "link", "website"):
+		return "https://example.com/" + g.word()
+	case name == "ip" || strings.HasSuffix(name, "_ip") || has("ip_addr", "ipv4"):
+		return g.ip()
+	case has("uuid", "guid"):
+		return g.uuid()
+	case has("host", "domain"):
+		return "api.example.com"
+	case name == "id" || strings.HasSuffix(name, "_id") || strings.HasSuffix(name, "ids"):
+		return fmt.Sprint(100000 + g.rand.Intn(900000))
+	case has("time", "date") || strings.HasSuffix(name, "_at"):
+		return baseTime.Add(time.Duration(g.rand.Int63n(365*24)) * time.Hour).Format(time.RFC3339)
+	case has("name", "user", "author", "owner", "nick"):
+		return names[g.rand.Intn(len(names))]
+	case has("token", "secret", "key", "hash", "sign"):
+		return fmt.Sprintf("%016x", g.rand.Uint64())
+	case has("path", "file"):
+		return "/data/" + g.word() + ".txt"
+	case has("lang", "locale"):
+		return "en"
+	case has("country"):
+		return "US"
+	case has("city"):
+		return "Shenzhen"
+	case has("color", "colour"):
+		return "red"
+	case has("desc", "content", "text", "msg", "message", "comment", "remark", "title", "summary"):
+		n := 3 + g.rand.Intn(3)
+		parts := make([]string, n)
+		for i := range parts {
+			parts[i] = g.word()
+		}
+		return strings.Join(parts, " ")
+	default:
+		return g.word()
+	}
+}
+
+func (g *Generator) word() string {
+	return words[g.rand.Intn(len(words))]
+}
+
+func (g *Generator) email() string {
+	return strings.ToLower(names[g.rand.Intn(len(names))]) + "@example.com"
+}
+
+func (g *Generator) ip() string {
+	return fmt.Sprintf("192.168.%d.%d", g.rand.Intn(256), 1+g.rand.Intn(254))
+}
+
+func (g *Generator) uuid() string {
+	hi, lo := g.rand.Uint64(), g.rand.Uint64()
+	return fmt.Sprintf("%08x-%04x-4%03x-a%03x-%012x", hi>>32, hi>>16&0xffff, hi&0xfff, lo>>48&0xfff, lo&0xffffffffffff)
+}